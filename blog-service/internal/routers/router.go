@@ -6,6 +6,7 @@ import (
 	"blog-service/internal/routers/api"
 	v1 "blog-service/internal/routers/api/v1"
 	"blog-service/pkg/limiter"
+	"cache"
 	"net/http"
 	"time"
 
@@ -42,6 +43,8 @@ func NewRouter() *gin.Engine {
 
 	// 接口文档
 	r.GET("/swagger/*any", ginSwagger.WrapHandler(swaggerFiles.Handler))
+	// 缓存指标，Prometheus 文本格式，包括所有注册到 cache.DefaultRegistry 的缓存
+	r.GET("/metrics", gin.WrapH(cache.DefaultRegistry))
 
 	article := v1.NewArticle()
 	tag := v1.NewTag()
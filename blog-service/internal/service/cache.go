@@ -0,0 +1,58 @@
+package service
+
+import (
+	"blog-service/global"
+	"blog-service/internal/dao"
+	"blog-service/internal/model"
+	"cache"
+	"cache/lru"
+	"context"
+	"fmt"
+	"time"
+)
+
+// tagCache 缓存 GetArticle 中按 ID 查询的标签，key 为 "id:state"，修改或删除标签时失效；
+// 指标注册到 cache.DefaultRegistry，通过 /metrics 导出
+var (
+	tagMetrics = cache.NewMetrics("tags", "lru")
+	tagCache   = cache.NewTourCache(
+		cache.LoaderFunc(loadTag),
+		lru.New(1<<20, nil, cache.WithMetrics(tagMetrics)),
+		cache.WithDefaultTTL(time.Minute),
+		cache.WithMetrics(tagMetrics),
+	)
+)
+
+func init() {
+	cache.DefaultRegistry.Register(tagMetrics)
+}
+
+func tagCacheKey(id uint32, state uint8) string {
+	return fmt.Sprintf("%d:%d", id, state)
+}
+
+func loadTag(ctx context.Context, key string) (interface{}, error) {
+	var (
+		id    uint32
+		state uint8
+	)
+	if _, err := fmt.Sscanf(key, "%d:%d", &id, &state); err != nil {
+		return nil, err
+	}
+	return dao.New(global.DBEngine).GetTag(id, state)
+}
+
+// getTag 通过 tagCache 查询标签
+func (svc *Service) getTag(id uint32, state uint8) (model.Tag, error) {
+	val, err := tagCache.GetContext(svc.ctx, tagCacheKey(id, state))
+	if err != nil {
+		return model.Tag{}, err
+	}
+	return val.(model.Tag), nil
+}
+
+// invalidateTag 删除标签所有状态的缓存
+func invalidateTag(id uint32) {
+	tagCache.Del(tagCacheKey(id, model.STATE_OPEN))
+	tagCache.Del(tagCacheKey(id, model.STATE_CLOSE))
+}
@@ -49,9 +49,11 @@ func (svc *Service) CreateTag(param *CreateTagRequest) error {
 }
 
 func (svc *Service) UpdateTag(param *UpdateTagRequest) error {
+	defer invalidateTag(param.ID)
 	return svc.dao.UpdateTag(param.ID, param.Name, param.State, param.ModifiedBy)
 }
 
 func (svc *Service) DeleteTag(param *DeleteTagRequest) error {
+	defer invalidateTag(param.ID)
 	return svc.dao.DeleteTag(param.ID)
 }
@@ -0,0 +1,322 @@
+package arc
+
+import (
+	"cache"
+	"container/list"
+	"io"
+	"time"
+	"unsafe"
+)
+
+// arc 是一个 ARC（Adaptive Replacement Cache）cache。它不是并发安全的。
+//
+// 缓存的数据分为两部分：t1 存放只访问过一次的 entry，t2 存放至少访问过两次的 entry；
+// b1、b2 分别记录最近从 t1、t2 中淘汰的 key（只记录 key 和大小，不保存值）。
+// 命中 b1 说明 t1 太小，命中 b2 说明 t2 太小，据此动态调整 t1 的目标大小 p，
+// 因此既能抵抗一次性的扫描，又能适应访问模式的变化。容量按字节计算。
+type arc struct {
+	// 缓存最大的容量，单位字节
+	maxBytes int
+	// 当一个 entry 从缓存中移除时调用该回调函数，默认为 nil
+	onEvicted cache.OnEvicted
+
+	opts cache.Options
+
+	// t1 的目标大小，单位字节
+	p int
+
+	t1, t2, b1, b2 *segment
+	cache          map[string]*list.Element
+}
+
+type entry struct {
+	key   string
+	value interface{}
+	// 过期时间，零值表示永不过期
+	expireAt time.Time
+	// entry 占用的字节数，包括 key 和 entryOverhead，淘汰到 b1、b2 之后仍然保留，用于调整 p
+	size int
+	seg  *segment
+}
+
+// entryOverhead 每个 entry 除了 key 和值以外的开销：entry 结构体和链表节点
+const entryOverhead = int(unsafe.Sizeof(entry{})) + cache.ListElementSize
+
+// entrySize 计算一个 entry 占用的字节数，包括 key、值以及 entryOverhead
+func entrySize(key string, value interface{}) int {
+	return cache.CalcLen(value) + cache.KeySize(key) + entryOverhead
+}
+
+// segment 是一个带字节数统计的 LRU 链表，Front 为最旧的 entry
+type segment struct {
+	ll    *list.List
+	bytes int
+	// key 和 entryOverhead 占用的字节数，b1、b2 不保存值，实际只占用这部分内存
+	keyBytes int
+}
+
+func newSegment() *segment {
+	return &segment{ll: list.New()}
+}
+
+func (s *segment) pushBack(et *entry) *list.Element {
+	et.seg = s
+	s.bytes += et.size
+	s.keyBytes += cache.KeySize(et.key) + entryOverhead
+	return s.ll.PushBack(et)
+}
+
+func (s *segment) remove(e *list.Element) *entry {
+	et := s.ll.Remove(e).(*entry)
+	s.bytes -= et.size
+	s.keyBytes -= cache.KeySize(et.key) + entryOverhead
+	return et
+}
+
+// New 创建一个新的 Cache，如果 maxBytes 是 0，表示没有容量限制
+func New(maxBytes int, onEvicted cache.OnEvicted, opts ...cache.Option) cache.Cache {
+	o := cache.NewOptions(opts...)
+	return &arc{
+		maxBytes:  maxBytes,
+		onEvicted: cache.ObserveEvicted(o.Metrics, onEvicted),
+		opts:      o,
+		t1:        newSegment(),
+		t2:        newSegment(),
+		b1:        newSegment(),
+		b2:        newSegment(),
+		cache:     make(map[string]*list.Element),
+	}
+}
+
+// Set 往 Cache 增加一个元素，过期时间为默认的 TTL
+func (a *arc) Set(key string, value interface{}) {
+	a.SetWithTTL(key, value, a.opts.DefaultTTL)
+}
+
+// SetWithTTL 同 Set，ttl 小于等于 0 表示永不过期。
+// 已存在的 entry 视为又被访问了一次，移入 t2；命中 b1、b2 时调整 p 并放入 t2；否则放入 t1
+func (a *arc) SetWithTTL(key string, value interface{}, ttl time.Duration) {
+	et := &entry{key: key, value: value, expireAt: a.opts.ExpireAt(ttl), size: entrySize(key, value)}
+
+	e, ok := a.cache[key]
+	if !ok {
+		a.cache[key] = a.t1.pushBack(et)
+		a.replace(false)
+		a.trimGhosts()
+		return
+	}
+
+	old := e.Value.(*entry)
+	seg := old.seg
+	seg.remove(e)
+	switch seg {
+	case a.b1:
+		a.p += delta(et.size, a.b2.bytes, a.b1.bytes)
+		if a.p > a.maxBytes {
+			a.p = a.maxBytes
+		}
+	case a.b2:
+		a.p -= delta(et.size, a.b1.bytes, a.b2.bytes)
+		if a.p < 0 {
+			a.p = 0
+		}
+	}
+	a.cache[key] = a.t2.pushBack(et)
+	a.replace(seg == a.b2)
+	a.trimGhosts()
+}
+
+// delta 计算 p 的调整量：另一个 ghost 链表越大，调整得越多
+func delta(size, other, self int) int {
+	if self == 0 || other <= self {
+		return size
+	}
+	return size * other / self
+}
+
+// Get 从 cache 中获取 key 对应的值，nil 表示 key 不存在或已过期，命中的 entry 移入 t2
+func (a *arc) Get(key string) interface{} {
+	e, ok := a.cache[key]
+	if !ok {
+		return nil
+	}
+
+	et := e.Value.(*entry)
+	if et.seg == a.b1 || et.seg == a.b2 {
+		return nil
+	}
+	if a.opts.Expired(et.expireAt) {
+		a.removeElement(e, cache.EvictExpired)
+		return nil
+	}
+
+	et.seg.remove(e)
+	a.cache[key] = a.t2.pushBack(et)
+	return et.value
+}
+
+// Contains 判断 key 是否存在且未过期，不会移入 t2；b1、b2 中的 key 视为不存在
+func (a *arc) Contains(key string) bool {
+	e, ok := a.cache[key]
+	if !ok {
+		return false
+	}
+	et := e.Value.(*entry)
+	return et.seg != a.b1 && et.seg != a.b2 && !a.opts.Expired(et.expireAt)
+}
+
+// Del 从 cache 中删除 key 对应的元素
+func (a *arc) Del(key string) {
+	e, ok := a.cache[key]
+	if !ok {
+		return
+	}
+
+	et := e.Value.(*entry)
+	if et.seg == a.b1 || et.seg == a.b2 {
+		et.seg.remove(e)
+		delete(a.cache, key)
+		return
+	}
+	a.removeElement(e, cache.EvictDeleted)
+}
+
+// DelOldest 按照 ARC 的规则淘汰一条记录
+func (a *arc) DelOldest() {
+	a.evict(false)
+	a.trimGhosts()
+}
+
+// DeleteExpired 删除所有已过期的记录，返回删除的个数
+func (a *arc) DeleteExpired() int {
+	n := 0
+	for _, seg := range []*segment{a.t1, a.t2} {
+		for e := seg.ll.Front(); e != nil; {
+			next := e.Next()
+			if a.opts.Expired(e.Value.(*entry).expireAt) {
+				a.removeElement(e, cache.EvictExpired)
+				n++
+			}
+			e = next
+		}
+	}
+
+	return n
+}
+
+// Len 返回当前 cache 中的记录数，不包括 b1、b2 中的 key
+func (a *arc) Len() int {
+	return a.t1.ll.Len() + a.t2.ll.Len()
+}
+
+// UsedBytes 实现 cache.MemoryUsage，包括 b1、b2 中的 key 和 entryOverhead
+func (a *arc) UsedBytes() int {
+	return a.t1.bytes + a.t2.bytes + a.b1.keyBytes + a.b2.keyBytes
+}
+
+// Snapshot 依次保存 t1、t2 中所有未过期的记录，Weight 为 1 表示在 t1 中，为 2 表示在 t2 中；
+// b1、b2 和 p 不会保存，恢复后重新适应访问模式
+func (a *arc) Snapshot(w io.Writer) error {
+	entries := make([]cache.SnapshotEntry, 0, a.Len())
+	for weight, seg := range []*segment{a.t1, a.t2} {
+		for e := seg.ll.Front(); e != nil; e = e.Next() {
+			et := e.Value.(*entry)
+			if !a.opts.Expired(et.expireAt) {
+				entries = append(entries, cache.SnapshotEntry{Key: et.key, Value: et.value, ExpireAt: et.expireAt, Weight: weight + 1})
+			}
+		}
+	}
+	return cache.WriteSnapshot(w, a.opts.Codec, entries)
+}
+
+// Restore 按快照中的顺序将记录放回 t1、t2，容量不足时按 ARC 的规则淘汰
+func (a *arc) Restore(r io.Reader) error {
+	entries, err := cache.ReadSnapshot(r, a.opts.Codec)
+	if err != nil {
+		return err
+	}
+	for _, se := range entries {
+		key, value, err := cache.Unpack[string, interface{}](se)
+		if err != nil {
+			return err
+		}
+		if a.opts.Expired(se.ExpireAt) {
+			continue
+		}
+		if e, ok := a.cache[key]; ok {
+			e.Value.(*entry).seg.remove(e)
+		}
+		seg := a.t1
+		if se.Weight > 1 {
+			seg = a.t2
+		}
+		a.cache[key] = seg.pushBack(&entry{key: key, value: value, expireAt: se.ExpireAt, size: entrySize(key, value)})
+		a.replace(false)
+		a.trimGhosts()
+	}
+	return nil
+}
+
+// replace 在超出容量时淘汰 entry：t1 超过目标大小 p 时淘汰 t1 中最旧的，否则淘汰 t2 中最旧的
+func (a *arc) replace(hitB2 bool) {
+	for a.maxBytes > 0 && a.t1.bytes+a.t2.bytes > a.maxBytes {
+		a.evict(hitB2)
+	}
+}
+
+func (a *arc) evict(hitB2 bool) {
+	if a.t1.ll.Len() > 0 && (a.t1.bytes > a.p || (hitB2 && a.t1.bytes == a.p) || a.t2.ll.Len() == 0) {
+		a.toGhost(a.t1.ll.Front(), a.b1)
+	} else if a.t2.ll.Len() > 0 {
+		a.toGhost(a.t2.ll.Front(), a.b2)
+	}
+}
+
+// toGhost 将 entry 淘汰到 ghost 链表，只保留 key 和大小
+func (a *arc) toGhost(e *list.Element, ghost *segment) {
+	et := e.Value.(*entry).seg.remove(e)
+	value := et.value
+	et.value = nil
+	a.cache[et.key] = ghost.pushBack(et)
+
+	if a.onEvicted != nil {
+		a.onEvicted(et.key, value, cache.EvictCapacity)
+	}
+}
+
+// trimGhosts 限制 ghost 链表的大小：t1 + b1 不超过容量，所有链表加起来不超过容量的两倍。
+// 没有容量限制时（只有 DelOldest 会淘汰），以当前的记录数作为容量，按条数限制
+func (a *arc) trimGhosts() {
+	if a.maxBytes <= 0 {
+		n := a.Len()
+		for a.b1.ll.Len() > 0 && a.t1.ll.Len()+a.b1.ll.Len() > n {
+			a.dropGhost(a.b1)
+		}
+		for a.b2.ll.Len() > 0 && n+a.b1.ll.Len()+a.b2.ll.Len() > 2*n {
+			a.dropGhost(a.b2)
+		}
+		return
+	}
+
+	for a.b1.ll.Len() > 0 && a.t1.bytes+a.b1.bytes > a.maxBytes {
+		a.dropGhost(a.b1)
+	}
+	for a.b2.ll.Len() > 0 && a.t1.bytes+a.t2.bytes+a.b1.bytes+a.b2.bytes > 2*a.maxBytes {
+		a.dropGhost(a.b2)
+	}
+}
+
+func (a *arc) dropGhost(ghost *segment) {
+	et := ghost.remove(ghost.ll.Front())
+	delete(a.cache, et.key)
+}
+
+func (a *arc) removeElement(e *list.Element, reason cache.EvictReason) {
+	et := e.Value.(*entry)
+	et.seg.remove(e)
+	delete(a.cache, et.key)
+
+	if a.onEvicted != nil {
+		a.onEvicted(et.key, et.value, reason)
+	}
+}
@@ -0,0 +1,128 @@
+package arc
+
+import (
+	"cache"
+	"testing"
+
+	"github.com/matryer/is"
+)
+
+// entryBytes 值为 int32、key 长度为 2 的 entry 占用的字节数
+var entryBytes = entrySize("k1", int32(0))
+
+func TestSet(t *testing.T) {
+	is := is.New(t)
+
+	cache := New(2*entryBytes, nil)
+	cache.DelOldest()
+	cache.Set("k1", int32(1))
+	cache.Set("k2", int32(2))
+
+	v := cache.Get("k1")
+	is.Equal(v, int32(1))
+
+	cache.Set("k3", int32(3))
+
+	// k1 被访问过两次，在 t2 中；k2 只访问过一次，先被淘汰
+	is.Equal(cache.Get("k2"), nil)
+	is.Equal(cache.Get("k1"), int32(1))
+
+	is.Equal(2, cache.Len())
+	cache.Del("k1")
+	is.Equal(1, cache.Len())
+}
+
+func TestOnEvicted(t *testing.T) {
+	is := is.New(t)
+
+	keys := make([]string, 0, 8)
+	onEvicted := func(key string, value interface{}, reason cache.EvictReason) {
+		keys = append(keys, key)
+	}
+	cache := New(2*entryBytes, onEvicted)
+
+	cache.Set("k1", int32(1))
+	cache.Set("k2", int32(2))
+	cache.Get("k1")
+	cache.Set("k3", int32(3))
+	cache.Get("k1")
+	cache.Set("k4", int32(4))
+
+	expected := []string{"k2", "k3"}
+
+	is.Equal(expected, keys)
+	is.Equal(2, cache.Len())
+}
+
+func TestGhostHit(t *testing.T) {
+	is := is.New(t)
+
+	c := New(4*entryBytes, nil).(*arc)
+	c.Set("k1", int32(1))
+	c.Set("k2", int32(2))
+	c.Get("k1")
+	c.Get("k2")
+	for _, key := range []string{"k3", "k4", "k5"} {
+		c.Set(key, int32(1))
+	}
+	// k3 被淘汰到 b1，不再计入 Len，但仍然记录着
+	is.Equal(c.Len(), 4)
+	is.Equal(c.Get("k3"), nil)
+	is.Equal(c.b1.ll.Len(), 1)
+	// b1 只占用 key 和 entryOverhead 的内存
+	is.Equal(c.UsedBytes(), 4*entryBytes+cache.KeySize("k3")+entryOverhead)
+
+	// 命中 b1 说明 t1 太小，增大 p，并直接放入 t2
+	c.Set("k3", int32(3))
+	is.Equal(c.p, entryBytes)
+	is.Equal(c.t2.ll.Len(), 3)
+	is.Equal(c.Get("k3"), int32(3))
+	is.Equal(c.Len(), 4)
+}
+
+func TestGhostLimit(t *testing.T) {
+	is := is.New(t)
+
+	// 没有容量限制时，ghost 链表按记录数限制，不会无限增长
+	c := New(0, nil).(*arc)
+	for i := 0; i < 100; i++ {
+		key := string(rune('a'+i%26)) + string(rune('a'+i/26))
+		c.Set(key, int32(1))
+		if i%2 == 0 {
+			c.Get(key)
+			c.Set(key, int32(2))
+		}
+	}
+	for i := 0; i < 90; i++ {
+		c.DelOldest()
+	}
+	is.Equal(c.Len(), 10)
+	is.True(c.b1.ll.Len()+c.b2.ll.Len() <= c.Len())
+	is.Equal(len(c.cache), c.Len()+c.b1.ll.Len()+c.b2.ll.Len())
+
+	for c.Len() > 0 {
+		c.DelOldest()
+	}
+	is.Equal(len(c.cache), 0)
+	is.Equal(c.UsedBytes(), 0)
+}
+
+func TestScanResistance(t *testing.T) {
+	is := is.New(t)
+
+	c := New(10*entryBytes, nil)
+	hot := []string{"h1", "h2", "h3", "h4", "h5"}
+	for i := 0; i < 2; i++ {
+		for _, key := range hot {
+			c.Set(key, int32(1))
+		}
+	}
+
+	// 一次性的扫描不会冲掉访问过两次的热点数据
+	for i := 0; i < 100; i++ {
+		c.Set(string(rune('a'+i%26))+string(rune('a'+i/26)), int32(1))
+	}
+	for _, key := range hot {
+		is.Equal(c.Get(key), int32(1))
+	}
+}
@@ -0,0 +1,127 @@
+// Package bench 对比 fast 包的两种存储方式：
+// NewFastCache 在 list.Element 中保存 interface{}，NewArenaCache 将 entry 序列化到环形字节缓冲区。
+//
+//	go test -bench . -benchmem ./bigcache-bench
+package bench
+
+import (
+	"cache/fast"
+	"runtime"
+	"strconv"
+	"testing"
+)
+
+const (
+	shardsNum = 256
+	// entry 的个数和大小，两种方式都不会触发淘汰
+	entries   = 1 << 20
+	valueSize = 64
+)
+
+// byteCache 统一两种存储方式的接口
+type byteCache struct {
+	set func(key string, value []byte)
+	get func(key string) []byte
+}
+
+func newFast() byteCache {
+	c := fast.NewFastCache(entries/shardsNum*2, 0, shardsNum, nil)
+	return byteCache{
+		set: func(key string, value []byte) { c.Set(key, value) },
+		get: func(key string) []byte {
+			if v := c.Get(key); v != nil {
+				return v.([]byte)
+			}
+			return nil
+		},
+	}
+}
+
+func newArena() byteCache {
+	c := fast.NewArenaCache(entries*(valueSize+64)*2, shardsNum, nil)
+	return byteCache{
+		set: func(key string, value []byte) { c.Set(key, value) },
+		get: c.Get,
+	}
+}
+
+var modes = []struct {
+	name string
+	new  func() byteCache
+}{
+	{"fast", newFast},
+	{"arena", newArena},
+}
+
+func key(i int) string {
+	return "key-" + strconv.Itoa(i)
+}
+
+func fill(c byteCache, n int) {
+	value := make([]byte, valueSize)
+	for i := 0; i < n; i++ {
+		c.set(key(i), value)
+	}
+}
+
+func BenchmarkSet(b *testing.B) {
+	for _, mode := range modes {
+		b.Run(mode.name, func(b *testing.B) {
+			c := mode.new()
+			value := make([]byte, valueSize)
+			b.ReportAllocs()
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				c.set(key(i%entries), value)
+			}
+		})
+	}
+}
+
+func BenchmarkGet(b *testing.B) {
+	for _, mode := range modes {
+		b.Run(mode.name, func(b *testing.B) {
+			c := mode.new()
+			fill(c, entries)
+			b.ReportAllocs()
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				c.get(key(i % entries))
+			}
+		})
+	}
+}
+
+func BenchmarkGetParallel(b *testing.B) {
+	for _, mode := range modes {
+		b.Run(mode.name, func(b *testing.B) {
+			c := mode.new()
+			fill(c, entries)
+			b.ReportAllocs()
+			b.ResetTimer()
+			b.RunParallel(func(pb *testing.PB) {
+				i := 0
+				for pb.Next() {
+					c.get(key(i % entries))
+					i++
+				}
+			})
+		})
+	}
+}
+
+// BenchmarkGC 缓存中有大量 entry 时一次完整 GC 的耗时，arena 的索引和缓冲区中都没有指针，GC 不需要扫描
+func BenchmarkGC(b *testing.B) {
+	for _, mode := range modes {
+		b.Run(mode.name, func(b *testing.B) {
+			c := mode.new()
+			fill(c, entries)
+			runtime.GC()
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				runtime.GC()
+			}
+			runtime.KeepAlive(c)
+		})
+	}
+}
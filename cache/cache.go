@@ -1,67 +1,199 @@
 package cache
 
 import (
+	"errors"
+	"io"
 	"sync"
+	"sync/atomic"
+	"time"
 )
 
 // Cache 缓存接口
 type Cache interface {
 	Set(key string, value interface{})
+	// SetWithTTL 写入 entry 并指定过期时间，ttl 小于等于 0 表示永不过期
+	SetWithTTL(key string, value interface{}, ttl time.Duration)
+	// Get 返回 key 对应的值，不存在或已过期时返回 nil
 	Get(key string) interface{}
+	// Contains 判断 key 是否存在且未过期，不会调整淘汰顺序、访问频率，也不会删除过期的 entry
+	Contains(key string) bool
 	Del(key string)
 	DelOldest()
+	// DeleteExpired 删除所有已过期的 entry，返回删除的个数
+	DeleteExpired() int
 	Len() int
 }
 
+// ErrSnapshotUnsupported 底层的 Cache 没有实现 Snapshotter
+var ErrSnapshotUnsupported = errors.New("cache: snapshot is not supported")
+
 // DefaultMaxBytes 默认允许占用的最大内存
 const DefaultMaxBytes = 1 << 29
 
-// safeCache 并发安全缓存
-type safeCache struct {
-	m     sync.RWMutex
-	cache Cache
+// DefaultMaxNotFound 默认最多缓存的不存在的 key 的个数
+const DefaultMaxNotFound = 10000
+
+// safeCache 并发安全缓存。lru、lfu 等在 Get 时也会调整淘汰顺序，所有操作都需要加互斥锁；
+// 需要读操作不互相阻塞时使用 fast.NewFastCache，它按分片加锁，并且读操作只加读锁
+type safeCache[K comparable, V any] struct {
+	m     sync.Mutex
+	cache TypedCache[K, V]
 
-	nhit, nget int
+	// 加载结果为 ErrNotFound 的 key 及其过期时间，不占用 cache 的容量；
+	// 最多 maxNotFound 个，过期的在访问或后台清理时删除
+	notFound    map[K]time.Time
+	maxNotFound int
+	now         func() time.Time
+	metrics     *Metrics
+
+	// 命中和访问次数，stat 不加锁读取，使用原子操作
+	nhit, nget int64
 }
 
 type Stat struct {
 	NHit, NGet int
 }
 
-func newSafeCache(cache Cache) *safeCache {
-	return &safeCache{
-		cache: cache,
+func newSafeCache[K comparable, V any](cache TypedCache[K, V], o Options) *safeCache[K, V] {
+	return &safeCache[K, V]{
+		cache:       cache,
+		notFound:    make(map[K]time.Time),
+		maxNotFound: o.MaxNotFound,
+		now:         o.Now,
+		metrics:     o.Metrics,
 	}
 }
 
-func (sc *safeCache) set(key string, value interface{}) {
+func (sc *safeCache[K, V]) set(key K, value V) {
 	sc.m.Lock()
 	defer sc.m.Unlock()
+	delete(sc.notFound, key)
 	sc.cache.Set(key, value)
+	sc.metrics.set()
 }
 
-func (sc *safeCache) get(key string) interface{} {
-	sc.m.RLock()
-	defer sc.m.RUnlock()
-	sc.nget++
-	if sc.cache == nil {
-		return nil
+func (sc *safeCache[K, V]) setWithTTL(key K, value V, ttl time.Duration) {
+	sc.m.Lock()
+	defer sc.m.Unlock()
+	delete(sc.notFound, key)
+	sc.cache.SetWithTTL(key, value, ttl)
+	sc.metrics.set()
+}
+
+// setNotFound 记录 key 不存在，ttl 之后过期；已达到 maxNotFound 时随机移除一个
+func (sc *safeCache[K, V]) setNotFound(key K, ttl time.Duration) {
+	sc.m.Lock()
+	defer sc.m.Unlock()
+	if _, ok := sc.notFound[key]; !ok && len(sc.notFound) >= sc.maxNotFound {
+		// map 的遍历顺序是随机的
+		for k := range sc.notFound {
+			delete(sc.notFound, k)
+			break
+		}
 	}
+	sc.notFound[key] = sc.now().Add(ttl)
+}
 
-	v := sc.cache.Get(key)
-	if v != nil {
+func (sc *safeCache[K, V]) deleteExpired() {
+	sc.m.Lock()
+	defer sc.m.Unlock()
+	sc.cache.DeleteExpired()
+	now := sc.now()
+	for key, expireAt := range sc.notFound {
+		if !now.Before(expireAt) {
+			delete(sc.notFound, key)
+		}
+	}
+}
+
+// get 返回 key 对应的值，ok 为 false 表示未命中；命中了不存在的 key 时 err 为 ErrNotFound
+func (sc *safeCache[K, V]) get(key K) (value V, ok bool, err error) {
+	atomic.AddInt64(&sc.nget, 1)
+	value, ok, err = sc.peek(key)
+	if ok {
 		// log.Println("[TourCache] hit")
-		sc.nhit++
+		atomic.AddInt64(&sc.nhit, 1)
+		sc.metrics.hit()
+	} else {
+		sc.metrics.miss()
+	}
+
+	return value, ok, err
+}
+
+// peek 同 get，但不计入命中率统计
+func (sc *safeCache[K, V]) peek(key K) (value V, ok bool, err error) {
+	sc.m.Lock()
+	defer sc.m.Unlock()
+	if sc.cache == nil {
+		return value, false, nil
 	}
+	return sc.lookup(key)
+}
 
-	return v
+func (sc *safeCache[K, V]) lookup(key K) (value V, ok bool, err error) {
+	if value, ok = sc.cache.Get(key); ok {
+		return value, true, nil
+	}
+	if expireAt, ok := sc.notFound[key]; ok {
+		if sc.now().Before(expireAt) {
+			return value, true, ErrNotFound
+		}
+		delete(sc.notFound, key)
+	}
+	return value, false, nil
+}
+
+// del 删除 key，返回删除前 key 是否存在且未过期
+func (sc *safeCache[K, V]) del(key K) bool {
+	sc.m.Lock()
+	defer sc.m.Unlock()
+	delete(sc.notFound, key)
+	ok := sc.cache.Contains(key)
+	sc.cache.Del(key)
+	return ok
+}
+
+func (sc *safeCache[K, V]) len() int {
+	sc.m.Lock()
+	defer sc.m.Unlock()
+	return sc.cache.Len()
+}
+
+// usedBytes 返回底层缓存已使用的字节数，没有实现 MemoryUsage 时 ok 为 false
+func (sc *safeCache[K, V]) usedBytes() (n int, ok bool) {
+	mu, ok := unwrap(sc.cache).(MemoryUsage)
+	if !ok {
+		return 0, false
+	}
+	sc.m.Lock()
+	defer sc.m.Unlock()
+	return mu.UsedBytes(), true
+}
+
+func (sc *safeCache[K, V]) snapshot(w io.Writer) error {
+	sc.m.Lock()
+	defer sc.m.Unlock()
+	s, ok := sc.cache.(Snapshotter)
+	if !ok {
+		return ErrSnapshotUnsupported
+	}
+	return s.Snapshot(w)
+}
+
+func (sc *safeCache[K, V]) restore(r io.Reader) error {
+	sc.m.Lock()
+	defer sc.m.Unlock()
+	s, ok := sc.cache.(Snapshotter)
+	if !ok {
+		return ErrSnapshotUnsupported
+	}
+	return s.Restore(r)
 }
 
-func (sc *safeCache) stat() *Stat {
-	sc.m.RLock()
-	defer sc.m.RUnlock()
+func (sc *safeCache[K, V]) stat() *Stat {
 	return &Stat{
-		NHit: sc.nhit,
-		NGet: sc.nget,
+		NHit: int(atomic.LoadInt64(&sc.nhit)),
+		NGet: int(atomic.LoadInt64(&sc.nget)),
 	}
 }
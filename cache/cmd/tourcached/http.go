@@ -0,0 +1,109 @@
+package main
+
+import (
+	"encoding/json"
+	"io"
+	"math"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+const keysPath = "/keys/"
+
+// 请求体最大的字节数
+const maxBodyBytes = 64 << 20
+
+// setRequest PUT /keys/<key> 的请求体，TTL 单位为秒，0 表示永不过期
+type setRequest struct {
+	Value *string `json:"value"`
+	TTL   int64   `json:"ttl"`
+}
+
+type getResponse struct {
+	Key   string `json:"key"`
+	Value string `json:"value"`
+}
+
+type delResponse struct {
+	Deleted bool `json:"deleted"`
+}
+
+type errorResponse struct {
+	Error string `json:"error"`
+}
+
+// newHTTPHandler 返回 JSON HTTP API：
+//
+//	GET    /keys/<key>  获取值，不存在时返回 404
+//	PUT    /keys/<key>  写入值，请求体为 {"value": "...", "ttl": 秒}
+//	DELETE /keys/<key>  删除，返回 {"deleted": true|false}
+//	GET    /stats       统计信息
+//	GET    /metrics     Prometheus 文本格式的指标
+func newHTTPHandler(s *server) http.Handler {
+	mux := http.NewServeMux()
+	mux.Handle("/metrics", s.registry)
+	mux.HandleFunc(keysPath, func(w http.ResponseWriter, r *http.Request) {
+		key, err := url.PathUnescape(strings.TrimPrefix(r.URL.EscapedPath(), keysPath))
+		if err != nil || key == "" {
+			writeJSONError(w, http.StatusBadRequest, "invalid key")
+			return
+		}
+
+		switch r.Method {
+		case http.MethodGet:
+			val, ok := s.get(key)
+			if !ok {
+				writeJSONError(w, http.StatusNotFound, "key not found")
+				return
+			}
+			writeJSON(w, http.StatusOK, getResponse{Key: key, Value: string(val)})
+		case http.MethodPut:
+			var req setRequest
+			if err := json.NewDecoder(io.LimitReader(r.Body, maxBodyBytes)).Decode(&req); err != nil {
+				writeJSONError(w, http.StatusBadRequest, "invalid body: "+err.Error())
+				return
+			}
+			if req.Value == nil {
+				writeJSONError(w, http.StatusBadRequest, "missing value")
+				return
+			}
+			if req.TTL < 0 {
+				writeJSONError(w, http.StatusBadRequest, "ttl must not be negative")
+				return
+			}
+			// 超出 time.Duration 的范围时乘积会溢出为负数，导致永不过期
+			if req.TTL > math.MaxInt64/int64(time.Second) {
+				writeJSONError(w, http.StatusBadRequest, "ttl is too large")
+				return
+			}
+			s.set(key, []byte(*req.Value), time.Duration(req.TTL)*time.Second)
+			w.WriteHeader(http.StatusNoContent)
+		case http.MethodDelete:
+			writeJSON(w, http.StatusOK, delResponse{Deleted: s.del(key)})
+		default:
+			w.Header().Set("Allow", "GET, PUT, DELETE")
+			writeJSONError(w, http.StatusMethodNotAllowed, "method not allowed")
+		}
+	})
+	mux.HandleFunc("/stats", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			w.Header().Set("Allow", "GET")
+			writeJSONError(w, http.StatusMethodNotAllowed, "method not allowed")
+			return
+		}
+		writeJSON(w, http.StatusOK, s.info())
+	})
+	return mux
+}
+
+func writeJSON(w http.ResponseWriter, code int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(code)
+	json.NewEncoder(w).Encode(v)
+}
+
+func writeJSONError(w http.ResponseWriter, code int, msg string) {
+	writeJSON(w, code, errorResponse{Error: msg})
+}
@@ -0,0 +1,78 @@
+// tourcached 将 TourCache 作为独立的缓存服务，支持 Redis RESP 协议的一个子集和 JSON HTTP API，
+// 方便非 Go 的服务共用同一个缓存：
+//
+//	$ tourcached -policy lru -max-bytes 268435456
+//	$ redis-cli -p 6380 SET k v EX 60
+//	$ curl -X PUT -d '{"value":"v","ttl":60}' localhost:8080/keys/k
+package main
+
+import (
+	"cache"
+	"context"
+	"errors"
+	"flag"
+	"log"
+	"net"
+	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+)
+
+func main() {
+	var conf config
+	flag.StringVar(&conf.respAddr, "resp", ":6380", "RESP 协议监听的地址，为空表示不启用")
+	flag.StringVar(&conf.httpAddr, "http", ":8080", "HTTP API 监听的地址，为空表示不启用")
+	flag.StringVar(&conf.policy, "policy", "lru", "淘汰算法：lru、lfu、fifo、fast")
+	flag.IntVar(&conf.maxBytes, "max-bytes", cache.DefaultMaxBytes, "最大占用的字节数，包括值、key 和 entry 本身的开销，0 表示不限制")
+	flag.IntVar(&conf.shards, "shards", 256, "fast 的分片数，必须是 2 的幂")
+	flag.DurationVar(&conf.cleanupInterval, "cleanup", time.Minute, "后台清理过期 entry 的间隔，0 表示不清理")
+	flag.StringVar(&conf.snapshotPath, "snapshot", "", "快照文件的路径，启动时从快照恢复，退出时写入，为空表示不使用快照")
+	flag.DurationVar(&conf.snapshotInterval, "snapshot-interval", 0, "后台定期写入快照的间隔，0 表示只在退出时写入")
+	flag.Parse()
+
+	if conf.respAddr == "" && conf.httpAddr == "" {
+		log.Fatal("[tourcached] at least one of -resp and -http is required")
+	}
+
+	s, err := newServer(conf)
+	if err != nil {
+		log.Fatal("[tourcached] ", err)
+	}
+	defer s.close()
+
+	errc := make(chan error, 2)
+
+	rs := newRESPServer(s)
+	if conf.respAddr != "" {
+		ln, err := net.Listen("tcp", conf.respAddr)
+		if err != nil {
+			log.Fatal("[tourcached] ", err)
+		}
+		log.Printf("[tourcached] RESP listening on %s, policy %s", ln.Addr(), conf.policy)
+		go func() { errc <- rs.serve(ln) }()
+	}
+
+	hs := &http.Server{Addr: conf.httpAddr, Handler: newHTTPHandler(s)}
+	if conf.httpAddr != "" {
+		log.Printf("[tourcached] HTTP listening on %s, policy %s", conf.httpAddr, conf.policy)
+		go func() { errc <- hs.ListenAndServe() }()
+	}
+
+	sig := make(chan os.Signal, 1)
+	signal.Notify(sig, syscall.SIGINT, syscall.SIGTERM)
+	select {
+	case err := <-errc:
+		log.Printf("[tourcached] %v", err)
+	case <-sig:
+		log.Println("[tourcached] shutting down")
+	}
+
+	rs.close()
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := hs.Shutdown(ctx); err != nil && !errors.Is(err, http.ErrServerClosed) {
+		log.Printf("[tourcached] shutdown HTTP server: %v", err)
+	}
+}
@@ -0,0 +1,329 @@
+package main
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"math"
+	"net"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// 单个参数最大的字节数，和 Redis 的 proto-max-bulk-len 默认值一致
+const maxBulkLen = 512 << 20
+
+var errProtocol = errors.New("protocol error")
+
+// respServer 实现 Redis RESP 协议的一个子集：PING、GET、SET（支持 EX、PX）、DEL、DBSIZE、INFO、QUIT
+type respServer struct {
+	server *server
+
+	mu    sync.Mutex
+	ln    net.Listener
+	conns map[net.Conn]struct{}
+}
+
+func newRESPServer(s *server) *respServer {
+	return &respServer{server: s, conns: make(map[net.Conn]struct{})}
+}
+
+// serve 接受 ln 上的连接，每个连接一个 goroutine，ln 被关闭时返回
+func (rs *respServer) serve(ln net.Listener) error {
+	rs.mu.Lock()
+	rs.ln = ln
+	rs.mu.Unlock()
+
+	for {
+		conn, err := ln.Accept()
+		if err != nil {
+			return err
+		}
+
+		rs.mu.Lock()
+		rs.conns[conn] = struct{}{}
+		rs.mu.Unlock()
+		go rs.serveConn(conn)
+	}
+}
+
+// close 关闭监听和所有连接
+func (rs *respServer) close() error {
+	rs.mu.Lock()
+	defer rs.mu.Unlock()
+
+	for conn := range rs.conns {
+		conn.Close()
+	}
+	if rs.ln == nil {
+		return nil
+	}
+	return rs.ln.Close()
+}
+
+func (rs *respServer) serveConn(conn net.Conn) {
+	defer func() {
+		rs.mu.Lock()
+		delete(rs.conns, conn)
+		rs.mu.Unlock()
+		conn.Close()
+	}()
+
+	r := bufio.NewReader(conn)
+	w := bufio.NewWriter(conn)
+	for {
+		args, err := readCommand(r)
+		if err != nil {
+			if errors.Is(err, errProtocol) {
+				writeError(w, "ERR "+err.Error())
+				w.Flush()
+			} else if err != io.EOF && !errors.Is(err, net.ErrClosed) {
+				log.Printf("[tourcached] read from %s: %v", conn.RemoteAddr(), err)
+			}
+			return
+		}
+		if len(args) == 0 {
+			continue
+		}
+
+		quit := rs.exec(w, args)
+		// 客户端可能一次发送多个命令（pipeline），缓冲区中没有剩余的命令时才写回
+		if r.Buffered() == 0 || quit {
+			if err := w.Flush(); err != nil {
+				return
+			}
+		}
+		if quit {
+			return
+		}
+	}
+}
+
+// exec 执行一个命令并写入回复，返回 true 表示需要关闭连接
+func (rs *respServer) exec(w *bufio.Writer, args [][]byte) bool {
+	s := rs.server
+	name := strings.ToUpper(string(args[0]))
+	args = args[1:]
+
+	switch name {
+	case "PING":
+		if len(args) > 1 {
+			writeArgsError(w, name)
+		} else if len(args) == 1 {
+			writeBulk(w, args[0])
+		} else {
+			writeSimple(w, "PONG")
+		}
+	case "GET":
+		if len(args) != 1 {
+			writeArgsError(w, name)
+			return false
+		}
+		if val, ok := s.get(string(args[0])); ok {
+			writeBulk(w, val)
+		} else {
+			writeNull(w)
+		}
+	case "SET":
+		if len(args) < 2 {
+			writeArgsError(w, name)
+			return false
+		}
+		ttl, err := parseExpire(args[2:])
+		if err != nil {
+			writeError(w, "ERR "+err.Error())
+			return false
+		}
+		s.set(string(args[0]), args[1], ttl)
+		writeSimple(w, "OK")
+	case "DEL":
+		if len(args) == 0 {
+			writeArgsError(w, name)
+			return false
+		}
+		n := 0
+		for _, key := range args {
+			if s.del(string(key)) {
+				n++
+			}
+		}
+		writeInt(w, n)
+	case "DBSIZE":
+		writeInt(w, s.len())
+	case "INFO":
+		writeBulk(w, []byte(formatInfo(s.info())))
+	case "QUIT":
+		writeSimple(w, "OK")
+		return true
+	default:
+		writeError(w, fmt.Sprintf("ERR unknown command '%s'", strings.ToLower(name)))
+	}
+	return false
+}
+
+// parseExpire 解析 SET 的可选参数 EX seconds 和 PX milliseconds，都没有时返回 0，表示永不过期
+func parseExpire(opts [][]byte) (time.Duration, error) {
+	var ttl time.Duration
+	for i := 0; i < len(opts); i++ {
+		unit := time.Second
+		switch strings.ToUpper(string(opts[i])) {
+		case "EX":
+		case "PX":
+			unit = time.Millisecond
+		default:
+			return 0, errors.New("syntax error")
+		}
+		if ttl > 0 || i+1 >= len(opts) {
+			return 0, errors.New("syntax error")
+		}
+		i++
+		n, err := strconv.ParseInt(string(opts[i]), 10, 64)
+		if err != nil {
+			return 0, errors.New("value is not an integer or out of range")
+		}
+		// 超出 time.Duration 的范围时乘积会溢出为负数，导致永不过期
+		if n <= 0 || n > math.MaxInt64/int64(unit) {
+			return 0, errors.New("invalid expire time in 'set' command")
+		}
+		ttl = time.Duration(n) * unit
+	}
+	return ttl, nil
+}
+
+// formatInfo 按 Redis INFO 的格式输出，字段名尽量和 Redis 保持一致
+func formatInfo(i info) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "# Server\r\n")
+	fmt.Fprintf(&b, "policy:%s\r\n", i.Policy)
+	fmt.Fprintf(&b, "uptime_in_seconds:%d\r\n", i.UptimeSeconds)
+	fmt.Fprintf(&b, "\r\n# Memory\r\n")
+	fmt.Fprintf(&b, "used_memory:%d\r\n", i.HeapAlloc)
+	fmt.Fprintf(&b, "used_memory_dataset:%d\r\n", i.UsedBytes)
+	fmt.Fprintf(&b, "maxmemory:%d\r\n", i.MaxBytes)
+	fmt.Fprintf(&b, "\r\n# Stats\r\n")
+	fmt.Fprintf(&b, "keyspace_gets:%d\r\n", i.Gets)
+	fmt.Fprintf(&b, "keyspace_hits:%d\r\n", i.Hits)
+	fmt.Fprintf(&b, "keyspace_misses:%d\r\n", i.Misses)
+	fmt.Fprintf(&b, "total_sets:%d\r\n", i.Sets)
+	fmt.Fprintf(&b, "evicted_keys:%d\r\n", i.Evicted)
+	fmt.Fprintf(&b, "expired_keys:%d\r\n", i.Expired)
+	fmt.Fprintf(&b, "\r\n# Keyspace\r\n")
+	fmt.Fprintf(&b, "keys:%d\r\n", i.Keys)
+	return b.String()
+}
+
+// readCommand 读取一个命令，支持 RESP 数组（*<n>\r\n$<len>\r\n<arg>\r\n...）和以空格分隔的 inline 命令，
+// 后者方便用 telnet、nc 调试。每个参数都是新分配的，可以直接保存到缓存中
+func readCommand(r *bufio.Reader) ([][]byte, error) {
+	line, err := readLine(r)
+	if err != nil {
+		return nil, err
+	}
+	if len(line) == 0 {
+		return nil, nil
+	}
+	if line[0] != '*' {
+		fields := strings.Fields(string(line))
+		args := make([][]byte, len(fields))
+		for i, field := range fields {
+			args[i] = []byte(field)
+		}
+		return args, nil
+	}
+
+	n, err := strconv.Atoi(string(line[1:]))
+	if err != nil || n < 0 || n > 1024*1024 {
+		return nil, fmt.Errorf("%w: invalid multibulk length", errProtocol)
+	}
+	// n 同样来自客户端，参数随着读取追加
+	var args [][]byte
+	for i := 0; i < n; i++ {
+		line, err := readLine(r)
+		if err != nil {
+			return nil, err
+		}
+		if len(line) == 0 || line[0] != '$' {
+			return nil, fmt.Errorf("%w: expected '$', got '%s'", errProtocol, line)
+		}
+		size, err := strconv.Atoi(string(line[1:]))
+		if err != nil || size < 0 || size > maxBulkLen {
+			return nil, fmt.Errorf("%w: invalid bulk length", errProtocol)
+		}
+		arg, err := readBulk(r, size)
+		if err != nil {
+			return nil, err
+		}
+		args = append(args, arg)
+	}
+	return args, nil
+}
+
+// bulkChunkSize 读取 bulk string 时每次最多分配的字节数
+const bulkChunkSize = 64 << 10
+
+// readBulk 读取 size 个字节以及结尾的 \r\n。长度来自客户端，不能据此预先分配内存，
+// 按 bulkChunkSize 分块读取，内存随着数据的到达分配
+func readBulk(r *bufio.Reader, size int) ([]byte, error) {
+	var arg []byte
+	for remaining := size + 2; remaining > 0; {
+		n := remaining
+		if n > bulkChunkSize {
+			n = bulkChunkSize
+		}
+		arg = append(arg, make([]byte, n)...)
+		if _, err := io.ReadFull(r, arg[len(arg)-n:]); err != nil {
+			return nil, err
+		}
+		remaining -= n
+	}
+	if arg[size] != '\r' || arg[size+1] != '\n' {
+		return nil, fmt.Errorf("%w: expected CRLF after bulk string", errProtocol)
+	}
+	return arg[:size], nil
+}
+
+// readLine 读取一行，去掉结尾的 \r\n
+func readLine(r *bufio.Reader) ([]byte, error) {
+	line, err := r.ReadSlice('\n')
+	if err == bufio.ErrBufferFull {
+		return nil, fmt.Errorf("%w: line too long", errProtocol)
+	}
+	if err != nil {
+		return nil, err
+	}
+	line = line[:len(line)-1]
+	if n := len(line); n > 0 && line[n-1] == '\r' {
+		line = line[:n-1]
+	}
+	return line, nil
+}
+
+func writeSimple(w *bufio.Writer, s string) {
+	w.WriteString("+" + s + "\r\n")
+}
+
+func writeError(w *bufio.Writer, msg string) {
+	w.WriteString("-" + msg + "\r\n")
+}
+
+func writeArgsError(w *bufio.Writer, name string) {
+	writeError(w, fmt.Sprintf("ERR wrong number of arguments for '%s' command", strings.ToLower(name)))
+}
+
+func writeInt(w *bufio.Writer, n int) {
+	w.WriteString(":" + strconv.Itoa(n) + "\r\n")
+}
+
+func writeBulk(w *bufio.Writer, b []byte) {
+	w.WriteString("$" + strconv.Itoa(len(b)) + "\r\n")
+	w.Write(b)
+	w.WriteString("\r\n")
+}
+
+func writeNull(w *bufio.Writer) {
+	w.WriteString("$-1\r\n")
+}
@@ -0,0 +1,149 @@
+package main
+
+import (
+	"cache"
+	"cache/fast"
+	"cache/fifo"
+	"cache/lfu"
+	"cache/lru"
+	"fmt"
+	"runtime"
+	"time"
+)
+
+// config tourcached 的配置，通过命令行参数设置
+type config struct {
+	respAddr string
+	httpAddr string
+	// 淘汰算法：lru、lfu、fifo、fast
+	policy string
+	// 最大占用的字节数，包括值、key 和 entry 本身的开销，0 表示不限制
+	maxBytes int
+	// fast 的分片数，必须是 2 的幂
+	shards int
+	// 后台清理过期 entry 的间隔，0 表示不清理，只在访问时检查
+	cleanupInterval time.Duration
+	// 快照文件的路径，为空表示不使用快照；启动时从快照恢复，退出时写入
+	snapshotPath string
+	// 后台定期写入快照的间隔，0 表示只在退出时写入
+	snapshotInterval time.Duration
+}
+
+// newCache 按 policy 创建底层缓存
+func newCache(policy string, maxBytes, shards int, opts ...cache.Option) (cache.Cache, error) {
+	switch policy {
+	case "lru":
+		return lru.New(maxBytes, nil, opts...), nil
+	case "lfu":
+		return lfu.New(maxBytes, nil, opts...), nil
+	case "fifo":
+		return fifo.New(maxBytes, nil, opts...), nil
+	case "fast":
+		if shards <= 0 || shards&(shards-1) != 0 {
+			return nil, fmt.Errorf("shards must be a power of two, got %d", shards)
+		}
+		return fast.NewFastCache(0, maxBytes, shards, nil, opts...), nil
+	}
+	return nil, fmt.Errorf("unknown policy %q, must be one of lru, lfu, fifo, fast", policy)
+}
+
+// server 将 TourCache 通过 RESP 和 HTTP 提供给其他服务，值统一保存为 []byte
+type server struct {
+	conf      config
+	cache     cache.Cache
+	tourCache *cache.TourCache
+	metrics   *cache.Metrics
+	registry  *cache.Registry
+	startedAt time.Time
+}
+
+// newServer opts 同时用于底层缓存和 TourCache，测试中用来替换时钟
+func newServer(conf config, opts ...cache.Option) (*server, error) {
+	metrics := cache.NewMetrics("tourcached", conf.policy)
+	opts = append(opts, cache.WithMetrics(metrics))
+	c, err := newCache(conf.policy, conf.maxBytes, conf.shards, opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	if _, ok := c.(cache.Snapshotter); conf.snapshotPath != "" && !ok {
+		return nil, fmt.Errorf("policy %s does not support snapshots", conf.policy)
+	}
+
+	registry := cache.NewRegistry()
+	registry.Register(metrics)
+	opts = append(opts, cache.WithCleanupInterval(conf.cleanupInterval), cache.WithSnapshot(conf.snapshotPath, conf.snapshotInterval))
+	return &server{
+		conf:      conf,
+		cache:     c,
+		tourCache: cache.NewTourCache(nil, c, opts...),
+		metrics:   metrics,
+		registry:  registry,
+		startedAt: time.Now(),
+	}, nil
+}
+
+func (s *server) get(key string) ([]byte, bool) {
+	val, ok := s.tourCache.Get(key).([]byte)
+	return val, ok
+}
+
+// set ttl 小于等于 0 表示永不过期
+func (s *server) set(key string, value []byte, ttl time.Duration) {
+	s.tourCache.SetWithTTL(key, value, ttl)
+}
+
+func (s *server) del(key string) bool {
+	return s.tourCache.Del(key)
+}
+
+func (s *server) len() int {
+	return s.tourCache.Len()
+}
+
+// info 返回的统计信息，RESP 的 INFO 和 HTTP 的 /stats 共用
+type info struct {
+	Policy        string `json:"policy"`
+	MaxBytes      int    `json:"max_bytes"`
+	UptimeSeconds int64  `json:"uptime_seconds"`
+	Keys          int    `json:"keys"`
+	Gets          int    `json:"gets"`
+	Hits          int    `json:"hits"`
+	Misses        int    `json:"misses"`
+	Sets          uint64 `json:"sets"`
+	// 因容量不足被淘汰和过期被删除的 entry 个数
+	Evicted   uint64 `json:"evicted"`
+	Expired   uint64 `json:"expired"`
+	UsedBytes int    `json:"used_bytes"`
+	HeapAlloc uint64 `json:"heap_alloc"`
+}
+
+func (s *server) info() info {
+	stat := s.tourCache.Stat()
+	metrics := s.metrics.Snapshot()
+	var m runtime.MemStats
+	runtime.ReadMemStats(&m)
+
+	return info{
+		Policy:        s.conf.policy,
+		MaxBytes:      s.conf.maxBytes,
+		UptimeSeconds: int64(time.Since(s.startedAt) / time.Second),
+		Keys:          s.len(),
+		Gets:          stat.NGet,
+		Hits:          stat.NHit,
+		Misses:        stat.NGet - stat.NHit,
+		Sets:          metrics.Sets,
+		Evicted:       metrics.Evictions[cache.EvictCapacity.String()],
+		Expired:       metrics.Evictions[cache.EvictExpired.String()],
+		UsedBytes:     metrics.Bytes,
+		HeapAlloc:     m.HeapAlloc,
+	}
+}
+
+// close 停止后台清理
+func (s *server) close() {
+	s.tourCache.Close()
+	if c, ok := s.cache.(interface{ Close() }); ok {
+		c.Close()
+	}
+}
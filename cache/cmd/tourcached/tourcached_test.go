@@ -0,0 +1,418 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"cache"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"runtime"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/matryer/is"
+)
+
+// fakeClock 可以手动拨动的时钟
+type fakeClock struct {
+	mu  sync.Mutex
+	now time.Time
+}
+
+func (c *fakeClock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.now
+}
+
+func (c *fakeClock) Add(d time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.now = c.now.Add(d)
+}
+
+var policies = []string{"lru", "lfu", "fifo", "fast"}
+
+// startServer 在回环地址上启动 RESP 和 HTTP 服务
+func startServer(t *testing.T, policy string) (s *server, respAddr, httpURL string, clock *fakeClock) {
+	clock = &fakeClock{now: time.Unix(1600000000, 0)}
+	s, err := newServer(config{policy: policy, shards: 4}, cache.WithClock(clock.Now))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(s.close)
+
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	rs := newRESPServer(s)
+	go rs.serve(ln)
+	t.Cleanup(func() { rs.close() })
+
+	hs := httptest.NewServer(newHTTPHandler(s))
+	t.Cleanup(hs.Close)
+
+	return s, ln.Addr().String(), hs.URL, clock
+}
+
+// respClient 测试用的 RESP 客户端，回复统一转换为字符串：
+// 简单字符串为 +OK，错误为 -ERR ...，整数为 :1，空值为 (nil)，其余为 bulk 的内容
+type respClient struct {
+	t    *testing.T
+	conn net.Conn
+	r    *bufio.Reader
+}
+
+func dial(t *testing.T, addr string) *respClient {
+	conn, err := net.Dial("tcp", addr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { conn.Close() })
+	conn.SetDeadline(time.Now().Add(5 * time.Second))
+	return &respClient{t: t, conn: conn, r: bufio.NewReader(conn)}
+}
+
+func encodeCommand(args ...string) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "*%d\r\n", len(args))
+	for _, arg := range args {
+		fmt.Fprintf(&b, "$%d\r\n%s\r\n", len(arg), arg)
+	}
+	return b.String()
+}
+
+func (c *respClient) send(raw string) {
+	if _, err := c.conn.Write([]byte(raw)); err != nil {
+		c.t.Fatal(err)
+	}
+}
+
+func (c *respClient) do(args ...string) string {
+	c.send(encodeCommand(args...))
+	return c.reply()
+}
+
+func (c *respClient) reply() string {
+	line, err := c.r.ReadString('\n')
+	if err != nil {
+		c.t.Fatal(err)
+	}
+	line = strings.TrimSuffix(line, "\r\n")
+	switch line[0] {
+	case '+', '-', ':':
+		return line
+	case '$':
+		n, _ := strconv.Atoi(line[1:])
+		if n < 0 {
+			return "(nil)"
+		}
+		buf := make([]byte, n+2)
+		if _, err := io.ReadFull(c.r, buf); err != nil {
+			c.t.Fatal(err)
+		}
+		return string(buf[:n])
+	}
+	c.t.Fatalf("unexpected reply %q", line)
+	return ""
+}
+
+func TestRESP(t *testing.T) {
+	for _, policy := range policies {
+		t.Run(policy, func(t *testing.T) {
+			is := is.New(t)
+			_, addr, _, clock := startServer(t, policy)
+			c := dial(t, addr)
+
+			is.Equal(c.do("PING"), "+PONG")
+			is.Equal(c.do("GET", "k1"), "(nil)")
+			is.Equal(c.do("SET", "k1", "v1"), "+OK")
+			is.Equal(c.do("get", "k1"), "v1")
+			// 二进制安全
+			is.Equal(c.do("SET", "k2", "a\r\nb"), "+OK")
+			is.Equal(c.do("GET", "k2"), "a\r\nb")
+			is.Equal(c.do("DBSIZE"), ":2")
+
+			is.Equal(c.do("SET", "k3", "v3", "EX", "10"), "+OK")
+			is.Equal(c.do("SET", "k4", "v4", "px", "500"), "+OK")
+			clock.Add(time.Second)
+			is.Equal(c.do("GET", "k4"), "(nil)")
+			is.Equal(c.do("GET", "k3"), "v3")
+			clock.Add(10 * time.Second)
+			is.Equal(c.do("GET", "k3"), "(nil)")
+
+			is.Equal(c.do("DEL", "k1", "k2", "unknown"), ":2")
+			is.Equal(c.do("GET", "k1"), "(nil)")
+
+			info := c.do("INFO")
+			is.True(strings.Contains(info, "policy:"+policy+"\r\n"))
+			is.True(strings.Contains(info, "keyspace_hits:3\r\n"))
+			is.True(strings.Contains(info, "keyspace_misses:4\r\n"))
+
+			is.Equal(c.do("QUIT"), "+OK")
+		})
+	}
+}
+
+func TestRESPErrors(t *testing.T) {
+	is := is.New(t)
+	_, addr, _, _ := startServer(t, "lru")
+	c := dial(t, addr)
+
+	is.Equal(c.do("GET"), "-ERR wrong number of arguments for 'get' command")
+	is.Equal(c.do("SET", "k"), "-ERR wrong number of arguments for 'set' command")
+	is.Equal(c.do("SET", "k", "v", "EX"), "-ERR syntax error")
+	is.Equal(c.do("SET", "k", "v", "EX", "abc"), "-ERR value is not an integer or out of range")
+	is.Equal(c.do("SET", "k", "v", "EX", "0"), "-ERR invalid expire time in 'set' command")
+	// 超出 time.Duration 的范围
+	is.Equal(c.do("SET", "k", "v", "EX", "9223372036854775807"), "-ERR invalid expire time in 'set' command")
+	is.Equal(c.do("SET", "k", "v", "PX", "9223372036855"), "-ERR invalid expire time in 'set' command")
+	is.Equal(c.do("SET", "k", "v", "EX", "1", "PX", "1"), "-ERR syntax error")
+	is.Equal(c.do("SET", "k", "v", "NX"), "-ERR syntax error")
+	is.Equal(c.do("FLUSHALL"), "-ERR unknown command 'flushall'")
+	// 出错之后连接仍然可用
+	is.Equal(c.do("GET", "k"), "(nil)")
+
+	c.send("*1\r\n+GET\r\n")
+	is.Equal(c.reply(), "-ERR protocol error: expected '$', got '+GET'")
+}
+
+func TestReadCommandLargeBulk(t *testing.T) {
+	is := is.New(t)
+
+	// 声明了接近上限的长度，但只发送了几个字节，不能按声明的长度分配内存
+	r := bufio.NewReader(strings.NewReader("*2\r\n$3\r\nGET\r\n$" + strconv.Itoa(maxBulkLen) + "\r\nab"))
+	var before, after runtime.MemStats
+	runtime.ReadMemStats(&before)
+	_, err := readCommand(r)
+	runtime.ReadMemStats(&after)
+	is.Equal(err, io.ErrUnexpectedEOF)
+	is.True(after.TotalAlloc-before.TotalAlloc < 1<<20)
+
+	// 超过一个分块的参数可以正常读取
+	value := strings.Repeat("x", 3*bulkChunkSize+1)
+	args, err := readCommand(bufio.NewReader(strings.NewReader(encodeCommand("SET", "k", value))))
+	is.NoErr(err)
+	is.Equal(len(args), 3)
+	is.Equal(string(args[2]), value)
+}
+
+func TestRESPPipelineAndInline(t *testing.T) {
+	is := is.New(t)
+	_, addr, _, _ := startServer(t, "lru")
+	c := dial(t, addr)
+
+	c.send(encodeCommand("SET", "k1", "v1") + encodeCommand("SET", "k2", "v2") + encodeCommand("GET", "k1") + encodeCommand("DBSIZE"))
+	is.Equal(c.reply(), "+OK")
+	is.Equal(c.reply(), "+OK")
+	is.Equal(c.reply(), "v1")
+	is.Equal(c.reply(), ":2")
+
+	c.send("GET k2\r\n")
+	is.Equal(c.reply(), "v2")
+	c.send("\r\nPING\n")
+	is.Equal(c.reply(), "+PONG")
+}
+
+func TestRESPConcurrentClients(t *testing.T) {
+	is := is.New(t)
+	s, addr, _, _ := startServer(t, "fast")
+
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			c := dial(t, addr)
+			for j := 0; j < 100; j++ {
+				key := fmt.Sprintf("k%d-%d", i, j)
+				c.do("SET", key, key)
+				if got := c.do("GET", key); got != key {
+					t.Errorf("GET %s = %q", key, got)
+				}
+			}
+		}(i)
+	}
+	wg.Wait()
+	is.Equal(s.len(), 800)
+}
+
+func TestMaxBytes(t *testing.T) {
+	for _, policy := range policies {
+		t.Run(policy, func(t *testing.T) {
+			is := is.New(t)
+
+			// 先写入一个 entry，得到每个 entry 占用的字节数，包括 key 和 entry 本身的开销；不限制容量时同样统计
+			s, err := newServer(config{policy: policy, shards: 1})
+			is.NoErr(err)
+			s.set("0", []byte("ab"), 0)
+			entryBytes := s.info().UsedBytes
+			s.close()
+			is.True(entryBytes > len("0")+len("ab"))
+
+			s, err = newServer(config{policy: policy, maxBytes: 5 * entryBytes, shards: 1})
+			is.NoErr(err)
+			defer s.close()
+			for i := 0; i < 10; i++ {
+				s.set(strconv.Itoa(i), []byte("ab"), 0)
+			}
+			is.Equal(s.len(), 5)
+
+			i := s.info()
+			is.Equal(i.Evicted, uint64(5))
+			is.Equal(i.UsedBytes, 5*entryBytes)
+		})
+	}
+}
+
+func TestSnapshot(t *testing.T) {
+	for _, policy := range policies {
+		t.Run(policy, func(t *testing.T) {
+			is := is.New(t)
+
+			conf := config{policy: policy, shards: 4, snapshotPath: filepath.Join(t.TempDir(), "tourcached.snapshot")}
+			s, err := newServer(conf)
+			is.NoErr(err)
+			s.set("k1", []byte("v1"), 0)
+			s.set("k2", []byte("v2"), time.Minute)
+			// 退出时写入快照
+			s.close()
+
+			s, err = newServer(conf)
+			is.NoErr(err)
+			defer s.close()
+			is.Equal(s.len(), 2)
+			val, ok := s.get("k2")
+			is.True(ok)
+			is.Equal(val, []byte("v2"))
+		})
+	}
+}
+
+func TestNewServerInvalid(t *testing.T) {
+	is := is.New(t)
+
+	_, err := newServer(config{policy: "random"})
+	is.True(err != nil)
+	_, err = newServer(config{policy: "fast", shards: 3})
+	is.True(err != nil)
+}
+
+func doHTTP(t *testing.T, method, url, body string) (int, map[string]interface{}) {
+	req, err := http.NewRequest(method, url, strings.NewReader(body))
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+
+	var data map[string]interface{}
+	if resp.StatusCode != http.StatusNoContent {
+		if err := json.NewDecoder(resp.Body).Decode(&data); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return resp.StatusCode, data
+}
+
+func TestHTTP(t *testing.T) {
+	for _, policy := range policies {
+		t.Run(policy, func(t *testing.T) {
+			is := is.New(t)
+			_, addr, url, clock := startServer(t, policy)
+
+			code, data := doHTTP(t, http.MethodGet, url+"/keys/k1", "")
+			is.Equal(code, http.StatusNotFound)
+			is.Equal(data["error"], "key not found")
+
+			code, _ = doHTTP(t, http.MethodPut, url+"/keys/k1", `{"value":"v1"}`)
+			is.Equal(code, http.StatusNoContent)
+			code, data = doHTTP(t, http.MethodGet, url+"/keys/k1", "")
+			is.Equal(code, http.StatusOK)
+			is.Equal(data["value"], "v1")
+
+			// key 需要转义
+			code, _ = doHTTP(t, http.MethodPut, url+"/keys/a%2Fb%20c", `{"value":"v2","ttl":10}`)
+			is.Equal(code, http.StatusNoContent)
+			code, data = doHTTP(t, http.MethodGet, url+"/keys/a%2Fb%20c", "")
+			is.Equal(code, http.StatusOK)
+			is.Equal(data["key"], "a/b c")
+			clock.Add(10 * time.Second)
+			code, _ = doHTTP(t, http.MethodGet, url+"/keys/a%2Fb%20c", "")
+			is.Equal(code, http.StatusNotFound)
+			// 已过期的 key 视为不存在，fast 在读锁下不会删除过期的 entry，这里顺便删除
+			code, data = doHTTP(t, http.MethodDelete, url+"/keys/a%2Fb%20c", "")
+			is.Equal(code, http.StatusOK)
+			is.Equal(data["deleted"], false)
+
+			// 两种协议访问的是同一个缓存
+			c := dial(t, addr)
+			is.Equal(c.do("GET", "k1"), "v1")
+			is.Equal(c.do("SET", "k2", "v2"), "+OK")
+			code, data = doHTTP(t, http.MethodGet, url+"/keys/k2", "")
+			is.Equal(code, http.StatusOK)
+			is.Equal(data["value"], "v2")
+
+			code, data = doHTTP(t, http.MethodDelete, url+"/keys/k1", "")
+			is.Equal(code, http.StatusOK)
+			is.Equal(data["deleted"], true)
+			code, data = doHTTP(t, http.MethodDelete, url+"/keys/k1", "")
+			is.Equal(code, http.StatusOK)
+			is.Equal(data["deleted"], false)
+
+			code, data = doHTTP(t, http.MethodGet, url+"/stats", "")
+			is.Equal(code, http.StatusOK)
+			is.Equal(data["policy"], policy)
+			is.Equal(data["keys"], 1.0)
+			is.Equal(data["hits"], 4.0)
+			is.Equal(data["misses"], 2.0)
+			// fast 不会在读取时删除过期的 entry，之后的 DELETE 算作主动删除
+			if policy != "fast" {
+				is.Equal(data["expired"], 1.0)
+			}
+
+			resp, err := http.Get(url + "/metrics")
+			is.NoErr(err)
+			defer resp.Body.Close()
+			body, err := io.ReadAll(resp.Body)
+			is.NoErr(err)
+			is.True(strings.Contains(string(body), `tourcache_hits_total{cache="tourcached",policy="`+policy+`"} 4`))
+		})
+	}
+}
+
+func TestHTTPErrors(t *testing.T) {
+	is := is.New(t)
+	_, _, url, _ := startServer(t, "lru")
+
+	for _, body := range []string{`not json`, `{}`, `{"value":"v","ttl":-1}`, `{"value":"v","ttl":9223372037}`} {
+		code, _ := doHTTP(t, http.MethodPut, url+"/keys/k", body)
+		is.Equal(code, http.StatusBadRequest)
+	}
+	code, _ := doHTTP(t, http.MethodGet, url+"/keys/", "")
+	is.Equal(code, http.StatusBadRequest)
+	code, _ = doHTTP(t, http.MethodPost, url+"/keys/k", `{"value":"v"}`)
+	is.Equal(code, http.StatusMethodNotAllowed)
+
+	var buf bytes.Buffer
+	buf.WriteString(`{"value":"`)
+	buf.WriteString(strings.Repeat("x", 1000))
+	buf.WriteString(`"}`)
+	code, _ = doHTTP(t, http.MethodPut, url+"/keys/big", buf.String())
+	is.Equal(code, http.StatusNoContent)
+	code, data := doHTTP(t, http.MethodGet, url+"/keys/big", "")
+	is.Equal(code, http.StatusOK)
+	is.Equal(len(data["value"].(string)), 1000)
+}
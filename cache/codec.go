@@ -0,0 +1,31 @@
+package cache
+
+import (
+	"bytes"
+	"encoding/gob"
+)
+
+// Codec 将缓存的值序列化为字节，用于在节点间传输等场景
+type Codec interface {
+	Marshal(v interface{}) ([]byte, error)
+	Unmarshal(data []byte) (interface{}, error)
+}
+
+// GobCodec 使用 encoding/gob 序列化，基本类型可以直接使用，自定义类型需要先通过 gob.Register 注册
+type GobCodec struct{}
+
+func (GobCodec) Marshal(v interface{}) ([]byte, error) {
+	var buf bytes.Buffer
+	if err := gob.NewEncoder(&buf).Encode(&v); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func (GobCodec) Unmarshal(data []byte) (interface{}, error) {
+	var v interface{}
+	if err := gob.NewDecoder(bytes.NewReader(data)).Decode(&v); err != nil {
+		return nil, err
+	}
+	return v, nil
+}
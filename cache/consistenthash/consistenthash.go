@@ -0,0 +1,196 @@
+// Package consistenthash 实现了带虚拟节点的一致性哈希，参考 groupcache 的 consistenthash。
+package consistenthash
+
+import (
+	"cache/fast"
+	"hash/crc32"
+	"sort"
+	"strconv"
+)
+
+// Hash 将 key 映射为 64 位哈希值
+type Hash func(key string) uint64
+
+// FNV64a 使用 FNV-1a 计算哈希值，是默认的哈希函数
+func FNV64a(key string) uint64 {
+	return fmix64(fast.Sum64(key))
+}
+
+// CRC32 使用 crc32（IEEE）计算哈希值。结果经过 fmix64 混淆，环上的位置与 groupcache 并不相同
+func CRC32(key string) uint64 {
+	return fmix64(uint64(crc32.ChecksumIEEE([]byte(key))))
+}
+
+// fmix64 是 MurmurHash3 的 finalizer。FNV-1a 和 crc32 对只有末尾几个字符不同的 key（如 key-1、key-2）
+// 计算出的哈希值高位几乎相同，直接放到环上会导致分布严重不均，混淆后每一位都受所有输入位影响
+func fmix64(h uint64) uint64 {
+	h ^= h >> 33
+	h *= 0xff51afd7ed558ccd
+	h ^= h >> 33
+	h *= 0xc4ceb9fe1a85ec53
+	h ^= h >> 33
+	return h
+}
+
+// Map 是一致性哈希环，每个节点对应 replicas 个虚拟节点。它不是并发安全的。
+type Map struct {
+	hash     Hash
+	replicas int
+	// 排好序的虚拟节点哈希值
+	keys []uint64
+	// 虚拟节点哈希值到节点的映射
+	ring  map[uint64]string
+	nodes map[string]struct{}
+	// 按加入顺序排列的节点，虚拟节点冲突时先加入的节点优先
+	order []string
+}
+
+// New 创建一个 Map，replicas 小于 1 时按 1 处理，fn 为 nil 时使用 FNV64a
+func New(replicas int, fn Hash) *Map {
+	if replicas < 1 {
+		replicas = 1
+	}
+	if fn == nil {
+		fn = FNV64a
+	}
+	return &Map{
+		hash:     fn,
+		replicas: replicas,
+		ring:     make(map[uint64]string),
+		nodes:    make(map[string]struct{}),
+	}
+}
+
+// IsEmpty 判断是否没有任何节点
+func (m *Map) IsEmpty() bool {
+	return len(m.keys) == 0
+}
+
+// Nodes 返回排好序的所有节点
+func (m *Map) Nodes() []string {
+	nodes := make([]string, 0, len(m.nodes))
+	for node := range m.nodes {
+		nodes = append(nodes, node)
+	}
+	sort.Strings(nodes)
+	return nodes
+}
+
+// Add 添加节点，已存在的节点会被忽略
+func (m *Map) Add(nodes ...string) {
+	for _, node := range nodes {
+		if _, ok := m.nodes[node]; ok {
+			continue
+		}
+		m.nodes[node] = struct{}{}
+		m.order = append(m.order, node)
+
+		for i := 0; i < m.replicas; i++ {
+			hash := m.hash(m.virtualKey(node, i))
+			// 与其他虚拟节点冲突时，保留先加入的那个
+			if _, ok := m.ring[hash]; ok {
+				continue
+			}
+			m.ring[hash] = node
+			m.keys = append(m.keys, hash)
+		}
+	}
+	sort.Slice(m.keys, func(i, j int) bool { return m.keys[i] < m.keys[j] })
+}
+
+// Remove 移除节点，原来属于该节点的 key 会分配给环上的下一个节点，其他 key 不受影响。
+// 加入时与被移除节点冲突而被忽略的虚拟节点会还给原来的节点
+func (m *Map) Remove(nodes ...string) {
+	freed := make(map[uint64]struct{})
+	for _, node := range nodes {
+		if _, ok := m.nodes[node]; !ok {
+			continue
+		}
+		delete(m.nodes, node)
+
+		for i := 0; i < m.replicas; i++ {
+			hash := m.hash(m.virtualKey(node, i))
+			if m.ring[hash] == node {
+				delete(m.ring, hash)
+				freed[hash] = struct{}{}
+			}
+		}
+	}
+	if len(freed) == 0 {
+		return
+	}
+
+	order := m.order[:0]
+	for _, node := range m.order {
+		if _, ok := m.nodes[node]; ok {
+			order = append(order, node)
+		}
+	}
+	m.order = order
+
+	// 按加入顺序把空出来的位置还给冲突的节点，m.keys 中仍保留着这些哈希值，顺序不变
+	for _, node := range m.order {
+		for i := 0; i < m.replicas; i++ {
+			hash := m.hash(m.virtualKey(node, i))
+			if _, ok := freed[hash]; !ok {
+				continue
+			}
+			if _, ok := m.ring[hash]; !ok {
+				m.ring[hash] = node
+			}
+		}
+	}
+
+	keys := m.keys[:0]
+	for _, hash := range m.keys {
+		if _, ok := m.ring[hash]; ok {
+			keys = append(keys, hash)
+		}
+	}
+	m.keys = keys
+}
+
+// Get 返回 key 所属的节点，没有任何节点时返回空字符串
+func (m *Map) Get(key string) string {
+	if m.IsEmpty() {
+		return ""
+	}
+	return m.ring[m.keys[m.search(key)]]
+}
+
+// GetN 沿着环顺时针返回 key 对应的 n 个不同节点，第一个与 Get 的结果相同，可用于选择副本；
+// 节点数不足 n 时返回所有节点
+func (m *Map) GetN(key string, n int) []string {
+	if m.IsEmpty() || n <= 0 {
+		return nil
+	}
+	if n > len(m.nodes) {
+		n = len(m.nodes)
+	}
+
+	nodes := make([]string, 0, n)
+	seen := make(map[string]struct{}, n)
+	for i, start := 0, m.search(key); len(nodes) < n && i < len(m.keys); i++ {
+		node := m.ring[m.keys[(start+i)%len(m.keys)]]
+		if _, ok := seen[node]; ok {
+			continue
+		}
+		seen[node] = struct{}{}
+		nodes = append(nodes, node)
+	}
+	return nodes
+}
+
+// search 返回 key 在环上顺时针遇到的第一个虚拟节点的下标
+func (m *Map) search(key string) int {
+	hash := m.hash(key)
+	idx := sort.Search(len(m.keys), func(i int) bool { return m.keys[i] >= hash })
+	if idx == len(m.keys) {
+		idx = 0
+	}
+	return idx
+}
+
+func (m *Map) virtualKey(node string, i int) string {
+	return node + "#" + strconv.Itoa(i)
+}
@@ -0,0 +1,202 @@
+package consistenthash
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/matryer/is"
+)
+
+func nodeNames(n int) []string {
+	nodes := make([]string, n)
+	for i := range nodes {
+		nodes[i] = fmt.Sprintf("10.0.0.%d:8000", i+1)
+	}
+	return nodes
+}
+
+func keyNames(n int) []string {
+	keys := make([]string, n)
+	for i := range keys {
+		keys[i] = "key-" + strconv.Itoa(i)
+	}
+	return keys
+}
+
+func TestHashing(t *testing.T) {
+	is := is.New(t)
+
+	// 直接把数字字符串当作哈希值，便于推算结果
+	m := New(3, func(key string) uint64 {
+		var s string
+		for _, r := range key {
+			if r >= '0' && r <= '9' {
+				s += string(r)
+			}
+		}
+		i, _ := strconv.ParseUint(s, 10, 64)
+		return i
+	})
+	is.Equal(m.Get("1"), "")
+
+	// 虚拟节点：2 -> 20 21 22，4 -> 40 41 42，6 -> 60 61 62
+	m.Add("6", "4", "2")
+	for key, node := range map[string]string{"2": "2", "11": "2", "23": "4", "27": "4", "63": "2"} {
+		is.Equal(m.Get(key), node)
+	}
+	is.Equal(m.GetN("23", 2), []string{"4", "6"})
+	is.Equal(m.GetN("63", 5), []string{"2", "4", "6"})
+
+	// 加入 8 -> 80 81 82 之后，27 仍然属于 4，63 改为属于 8
+	m.Add("8")
+	is.Equal(m.Get("27"), "4")
+	is.Equal(m.Get("63"), "8")
+
+	m.Remove("8", "4")
+	is.Equal(m.Get("27"), "6")
+	is.Equal(m.Get("63"), "2")
+	is.Equal(m.Nodes(), []string{"2", "6"})
+}
+
+func TestRemoveCollision(t *testing.T) {
+	is := is.New(t)
+
+	// a#i 和 b#i 都映射到 i，c#i 映射到 10+i，其他 key 直接当作哈希值
+	m := New(3, func(key string) uint64 {
+		i, _ := strconv.ParseUint(key[strings.IndexByte(key, '#')+1:], 10, 64)
+		if key[0] == 'c' {
+			i += 10
+		}
+		return i
+	})
+	m.Add("a", "b", "c")
+	is.Equal(m.Get("1"), "a")
+	is.Equal(m.Get("5"), "c")
+
+	// a 被移除后，b 之前因冲突被忽略的虚拟节点重新生效
+	m.Remove("a")
+	is.Equal(m.Get("1"), "b")
+	is.Equal(m.Get("5"), "c")
+	is.Equal(m.Nodes(), []string{"b", "c"})
+
+	m.Remove("b")
+	is.Equal(m.Get("1"), "c")
+	m.Remove("c")
+	is.True(m.IsEmpty())
+}
+
+func TestConsistency(t *testing.T) {
+	is := is.New(t)
+
+	m1 := New(10, nil)
+	m2 := New(10, nil)
+	m1.Add("Bill", "Bob", "Bonny")
+	m2.Add("Bob", "Bonny", "Bill", "Bob")
+	for _, key := range keyNames(100) {
+		is.Equal(m1.Get(key), m2.Get(key))
+	}
+}
+
+func TestGetN(t *testing.T) {
+	is := is.New(t)
+
+	m := New(50, nil)
+	is.Equal(m.GetN("key", 3), []string(nil))
+
+	m.Add(nodeNames(5)...)
+	for _, key := range keyNames(100) {
+		nodes := m.GetN(key, 3)
+		is.Equal(len(nodes), 3)
+		is.Equal(nodes[0], m.Get(key))
+		is.True(nodes[0] != nodes[1] && nodes[1] != nodes[2] && nodes[0] != nodes[2])
+	}
+	is.Equal(len(m.GetN("key", 10)), 5)
+}
+
+// stddev 返回各节点 key 数相对于平均值的标准差（比例）
+func stddev(m *Map, keys []string) float64 {
+	counts := make(map[string]int)
+	for _, key := range keys {
+		counts[m.Get(key)]++
+	}
+
+	nodes := m.Nodes()
+	mean := float64(len(keys)) / float64(len(nodes))
+	var sum float64
+	for _, node := range nodes {
+		d := float64(counts[node]) - mean
+		sum += d * d
+	}
+	return math.Sqrt(sum/float64(len(nodes))) / mean
+}
+
+func TestDistribution(t *testing.T) {
+	keys := keyNames(100000)
+	for name, fn := range map[string]Hash{"fnv64a": FNV64a, "crc32": CRC32} {
+		for _, replicas := range []int{1, 10, 100, 500} {
+			m := New(replicas, fn)
+			m.Add(nodeNames(10)...)
+			sd := stddev(m, keys)
+			t.Logf("%s replicas=%d stddev=%.2f%%", name, replicas, sd*100)
+			if replicas >= 100 && sd > 0.15 {
+				t.Errorf("%s replicas=%d: stddev %.2f%% is too large", name, replicas, sd*100)
+			}
+		}
+	}
+}
+
+func TestKeyMovement(t *testing.T) {
+	is := is.New(t)
+
+	keys := keyNames(100000)
+	nodes := nodeNames(11)
+	m := New(100, nil)
+	m.Add(nodes[:10]...)
+	before := make(map[string]string, len(keys))
+	for _, key := range keys {
+		before[key] = m.Get(key)
+	}
+
+	// 加入节点后，只有分配给新节点的 key 会移动，约占 1/11
+	m.Add(nodes[10])
+	moved := 0
+	for _, key := range keys {
+		if node := m.Get(key); node != before[key] {
+			is.Equal(node, nodes[10])
+			moved++
+		}
+	}
+	ratio := float64(moved) / float64(len(keys))
+	t.Logf("add node: %.2f%% keys moved", ratio*100)
+	is.True(ratio > 0.5/11 && ratio < 2.0/11)
+
+	// 移除节点后，只有原来属于该节点的 key 会移动
+	m.Remove(nodes[10], nodes[0])
+	moved = 0
+	for _, key := range keys {
+		if node := m.Get(key); node != before[key] {
+			is.Equal(before[key], nodes[0])
+			moved++
+		}
+	}
+	ratio = float64(moved) / float64(len(keys))
+	t.Logf("remove node: %.2f%% keys moved", ratio*100)
+	is.True(ratio > 0.5/10 && ratio < 2.0/10)
+}
+
+func BenchmarkGet(b *testing.B) {
+	for _, n := range []int{8, 32, 128, 512} {
+		b.Run(strconv.Itoa(n), func(b *testing.B) {
+			m := New(50, nil)
+			m.Add(nodeNames(n)...)
+			keys := keyNames(1024)
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				m.Get(keys[i&1023])
+			}
+		})
+	}
+}
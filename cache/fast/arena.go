@@ -0,0 +1,157 @@
+package fast
+
+import (
+	"cache"
+	"errors"
+	"fmt"
+	"io"
+	"time"
+)
+
+var (
+	// ErrEntryTooLarge entry 序列化后比一个分片的容量还大
+	ErrEntryTooLarge = errors.New("fast: entry is larger than shard capacity")
+	// ErrKeyTooLarge key 的长度超过 65535
+	ErrKeyTooLarge = errors.New("fast: key is too large")
+)
+
+// 每个分片最大的字节数，偏移量用 uint32 保存，同时不能超过 32 位平台的 int
+const maxShardBytes = 1<<31 - 1
+
+type arenaCache struct {
+	shards    []*arenaShard
+	shardMask uint64
+	hash      fnv64a
+
+	stopJanitor func()
+}
+
+// NewArenaCache 创建一个分片的并发安全字节缓存，entry 序列化后保存在每个分片的环形缓冲区中，
+// 适合存放大量 entry，减轻 GC 的压力。shardsNum 必须是 2 的幂，maxBytes 平均分配到每个分片，0 表示使用 cache.DefaultMaxBytes，
+// 缓冲区按需扩容。空间不足时淘汰最早写入的 entry。opts 中的 CleanupInterval 用于启动后台清理过期 entry
+func NewArenaCache(maxBytes, shardsNum int, onEvicted cache.OnEvicted, opts ...cache.Option) *arenaCache {
+	checkShardsNum(shardsNum)
+	if maxBytes <= 0 {
+		maxBytes = cache.DefaultMaxBytes
+	}
+	shardBytes := maxBytes / shardsNum
+	if shardBytes > maxShardBytes {
+		shardBytes = maxShardBytes
+	}
+
+	o := cache.NewOptions(opts...)
+	onEvicted = cache.ObserveEvicted(o.Metrics, onEvicted)
+	arenaCache := &arenaCache{
+		hash:      newDefaultHasher(),
+		shards:    make([]*arenaShard, shardsNum),
+		shardMask: uint64(shardsNum - 1),
+	}
+	for i := 0; i < shardsNum; i++ {
+		arenaCache.shards[i] = newArenaShard(shardBytes, onEvicted, o)
+	}
+	arenaCache.stopJanitor = cache.StartJanitor(o.CleanupInterval, func() {
+		arenaCache.DeleteExpired()
+	})
+
+	return arenaCache
+}
+
+func (c *arenaCache) getShard(hashedKey uint64) *arenaShard {
+	return c.shards[hashedKey&c.shardMask]
+}
+
+// Set 写入 key 对应的值，value 会被拷贝，过期时间为默认的 TTL
+func (c *arenaCache) Set(key string, value []byte) error {
+	hashedKey := c.hash.Sum64(key)
+	shard := c.getShard(hashedKey)
+	return shard.set(key, hashedKey, value, shard.opts.ExpireAt(shard.opts.DefaultTTL))
+}
+
+// SetWithTTL 同 Set，ttl 小于等于 0 表示永不过期
+func (c *arenaCache) SetWithTTL(key string, value []byte, ttl time.Duration) error {
+	hashedKey := c.hash.Sum64(key)
+	shard := c.getShard(hashedKey)
+	return shard.set(key, hashedKey, value, shard.opts.ExpireAt(ttl))
+}
+
+// Get 返回 key 对应的值的拷贝，nil 表示 key 不存在或已过期
+func (c *arenaCache) Get(key string) []byte {
+	hashedKey := c.hash.Sum64(key)
+	return c.getShard(hashedKey).get(key, hashedKey)
+}
+
+func (c *arenaCache) Del(key string) {
+	hashedKey := c.hash.Sum64(key)
+	c.getShard(hashedKey).del(key, hashedKey)
+}
+
+func (c *arenaCache) Len() int {
+	length := 0
+	for _, shard := range c.shards {
+		length += shard.len()
+	}
+	return length
+}
+
+func (c *arenaCache) DeleteExpired() int {
+	n := 0
+	for _, shard := range c.shards {
+		n += shard.deleteExpired()
+	}
+	return n
+}
+
+// Snapshot 逐个分片保存所有未过期的记录，分片内按写入的先后排序，值为 []byte
+func (c *arenaCache) Snapshot(w io.Writer) error {
+	var entries []cache.SnapshotEntry
+	for _, shard := range c.shards {
+		entries = shard.entries(entries)
+	}
+	return cache.WriteSnapshot(w, c.shards[0].opts.Codec, entries)
+}
+
+// Restore 按快照中的顺序写入记录，值必须是 []byte
+func (c *arenaCache) Restore(r io.Reader) error {
+	opts := c.shards[0].opts
+	entries, err := cache.ReadSnapshot(r, opts.Codec)
+	if err != nil {
+		return err
+	}
+	for _, se := range entries {
+		key, value, err := cache.Unpack[string, []byte](se)
+		if err != nil {
+			return err
+		}
+		if opts.Expired(se.ExpireAt) {
+			continue
+		}
+		hashedKey := c.hash.Sum64(key)
+		if err := c.getShard(hashedKey).set(key, hashedKey, value, se.ExpireAt); err != nil {
+			return fmt.Errorf("fast: restore %s: %w", key, err)
+		}
+	}
+	return nil
+}
+
+// Stat 返回所有分片的统计信息之和，哈希冲突时后写入的 key 会覆盖之前的
+func (c *arenaCache) Stat() Stat {
+	var stat Stat
+	for _, shard := range c.shards {
+		stat.add(shard.stat())
+	}
+	return stat
+}
+
+// ShardStats 返回每个分片的统计信息
+func (c *arenaCache) ShardStats() []Stat {
+	stats := make([]Stat, len(c.shards))
+	for i, shard := range c.shards {
+		stats[i] = shard.stat()
+	}
+	return stats
+}
+
+// Close 停止后台清理
+func (c *arenaCache) Close() {
+	c.stopJanitor()
+}
@@ -0,0 +1,315 @@
+package fast
+
+import (
+	"cache"
+	"encoding/binary"
+	"sync"
+	"time"
+)
+
+// arena 中每个 entry 的头部：过期时间（UnixNano，0 表示永不过期）、key 的哈希值、key 长度、value 长度
+const (
+	expireAtOffset = 0
+	hashOffset     = 8
+	keyLenOffset   = 16
+	valueLenOffset = 18
+	headerSize     = 22
+
+	// arena 初始大小，写满后成倍扩容直到上限
+	initialArenaSize = 64 * 1024
+)
+
+// arenaShard 将序列化后的 entry 依次写入一个环形字节缓冲区，索引只保存哈希值到偏移量的映射，
+// map 中没有指针，GC 不需要扫描。空间不足时从最旧的 entry 开始淘汰（FIFO），
+// 被删除或覆盖的 entry 会一直占用空间，直到被淘汰。
+type arenaShard struct {
+	locker sync.RWMutex
+
+	// 当一个 entry 从缓存中移除时调用该回调函数，默认为 nil；value 是 []byte 的拷贝
+	onEvicted cache.OnEvicted
+
+	opts cache.Options
+
+	index map[uint64]uint32
+	buf   []byte
+	// 环形缓冲区的最大字节数
+	capacity int
+
+	// 最旧的 entry 的偏移量，下一个 entry 写入的偏移量
+	head, tail int
+	// 数据绕回到开头时，wrap 为绕回前数据的结束位置，此时数据分布在 [head, wrap) 和 [0, tail)
+	wrapped bool
+	wrap    int
+	// 缓冲区中的 entry 个数，包括已被删除或覆盖的
+	count int
+
+	shardStat
+}
+
+func newArenaShard(capacity int, onEvicted cache.OnEvicted, opts cache.Options) *arenaShard {
+	size := initialArenaSize
+	if size > capacity {
+		size = capacity
+	}
+	return &arenaShard{
+		onEvicted: onEvicted,
+		opts:      opts,
+		index:     make(map[uint64]uint32),
+		buf:       make([]byte, size),
+		capacity:  capacity,
+	}
+}
+
+// set 追加一个 entry，哈希值相同的旧 entry（包括哈希冲突的其他 key）不再可见；expireAt 为零值表示永不过期
+func (s *arenaShard) set(key string, hashedKey uint64, value []byte, expireAt time.Time) error {
+	if len(key) > 0xffff {
+		return ErrKeyTooLarge
+	}
+	size := headerSize + len(key) + len(value)
+	if size > s.capacity {
+		return ErrEntryTooLarge
+	}
+
+	s.locker.Lock()
+	defer s.locker.Unlock()
+
+	if off, ok := s.index[hashedKey]; ok {
+		delete(s.index, hashedKey)
+		if !s.keyEqual(int(off), key) {
+			s.collide()
+			s.evicted(int(off), cache.EvictCapacity)
+		}
+	}
+
+	off := s.alloc(size)
+	var expireAtNano int64
+	if !expireAt.IsZero() {
+		expireAtNano = expireAt.UnixNano()
+	}
+	b := s.buf[off : off+size]
+	binary.LittleEndian.PutUint64(b[expireAtOffset:], uint64(expireAtNano))
+	binary.LittleEndian.PutUint64(b[hashOffset:], hashedKey)
+	binary.LittleEndian.PutUint16(b[keyLenOffset:], uint16(len(key)))
+	binary.LittleEndian.PutUint32(b[valueLenOffset:], uint32(len(value)))
+	copy(b[headerSize:], key)
+	copy(b[headerSize+len(key):], value)
+
+	s.index[hashedKey] = uint32(off)
+	return nil
+}
+
+// get 返回 value 的拷贝，nil 表示 key 不存在或已过期
+func (s *arenaShard) get(key string, hashedKey uint64) []byte {
+	s.locker.RLock()
+	defer s.locker.RUnlock()
+
+	off, ok := s.index[hashedKey]
+	if !ok {
+		s.miss()
+		return nil
+	}
+	if !s.keyEqual(int(off), key) {
+		s.collide()
+		s.miss()
+		return nil
+	}
+	if s.expired(int(off)) {
+		// 读锁下不能删除，交给后台清理或淘汰
+		s.miss()
+		return nil
+	}
+	s.hit()
+
+	value := s.value(int(off))
+	dst := make([]byte, len(value))
+	copy(dst, value)
+	return dst
+}
+
+// del 删除 key 对应的 entry，entry 占用的空间在被淘汰时才释放
+func (s *arenaShard) del(key string, hashedKey uint64) {
+	s.locker.Lock()
+	defer s.locker.Unlock()
+
+	if off, ok := s.index[hashedKey]; ok && s.keyEqual(int(off), key) {
+		delete(s.index, hashedKey)
+		s.evicted(int(off), cache.EvictDeleted)
+	}
+}
+
+// deleteExpired 删除所有已过期的 entry，返回删除的个数
+func (s *arenaShard) deleteExpired() int {
+	s.locker.Lock()
+	defer s.locker.Unlock()
+
+	n := 0
+	off := s.head
+	for i := 0; i < s.count; i++ {
+		if s.live(off) && s.expired(off) {
+			delete(s.index, s.hash(off))
+			s.evicted(off, cache.EvictExpired)
+			n++
+		}
+		off = s.next(off)
+	}
+	return n
+}
+
+// entries 将所有未过期的 entry 按写入的先后追加到 entries 中，值为拷贝
+func (s *arenaShard) entries(entries []cache.SnapshotEntry) []cache.SnapshotEntry {
+	s.locker.RLock()
+	defer s.locker.RUnlock()
+
+	off := s.head
+	for i := 0; i < s.count; i++ {
+		if s.live(off) && !s.expired(off) {
+			se := cache.SnapshotEntry{Key: s.key(off), Value: append([]byte(nil), s.value(off)...)}
+			if expireAt := int64(binary.LittleEndian.Uint64(s.buf[off+expireAtOffset:])); expireAt != 0 {
+				se.ExpireAt = time.Unix(0, expireAt)
+			}
+			entries = append(entries, se)
+		}
+		off = s.next(off)
+	}
+	return entries
+}
+
+func (s *arenaShard) len() int {
+	s.locker.RLock()
+	defer s.locker.RUnlock()
+
+	return len(s.index)
+}
+
+// alloc 在缓冲区中分配 size 个连续的字节，返回偏移量。
+// 尾部空间不足时优先扩容，已达到上限则绕回开头，必要时淘汰最旧的 entry
+func (s *arenaShard) alloc(size int) int {
+	for {
+		if s.count == 0 {
+			s.head, s.tail, s.wrapped = 0, 0, false
+		}
+
+		if !s.wrapped {
+			if s.tail+size <= len(s.buf) {
+				break
+			}
+			if len(s.buf) < s.capacity {
+				s.grow(s.tail + size)
+				continue
+			}
+			if s.head >= size {
+				s.wrapped, s.wrap, s.tail = true, s.tail, 0
+				break
+			}
+		} else if s.head-s.tail >= size {
+			break
+		}
+
+		s.evictOldest()
+	}
+
+	off := s.tail
+	s.tail += size
+	s.count++
+	return off
+}
+
+// grow 将缓冲区扩容到至少 n 个字节，只在数据没有绕回时调用，偏移量保持不变
+func (s *arenaShard) grow(n int) {
+	size := len(s.buf) * 2
+	if size < n {
+		size = n
+	}
+	if size > s.capacity {
+		size = s.capacity
+	}
+	buf := make([]byte, size)
+	copy(buf, s.buf[:s.tail])
+	s.buf = buf
+}
+
+// evictOldest 释放最旧的 entry 占用的空间，仍然可见的 entry 从索引中删除
+func (s *arenaShard) evictOldest() {
+	off := s.head
+	if s.live(off) {
+		delete(s.index, s.hash(off))
+		reason := cache.EvictCapacity
+		if s.expired(off) {
+			reason = cache.EvictExpired
+		}
+		s.evicted(off, reason)
+	}
+
+	// 淘汰的是绕回前的最后一个 entry 时数据不再分布在两段，要在 next 把偏移量变为 0 之前判断
+	if s.wrapped && off+s.size(off) == s.wrap {
+		s.head, s.wrapped = 0, false
+	} else {
+		s.head = s.next(off)
+	}
+	s.count--
+}
+
+// next 返回 off 之后的 entry 的偏移量
+func (s *arenaShard) next(off int) int {
+	off += s.size(off)
+	if s.wrapped && off == s.wrap {
+		off = 0
+	}
+	return off
+}
+
+// live 判断 off 处的 entry 是否仍然在索引中
+func (s *arenaShard) live(off int) bool {
+	idx, ok := s.index[s.hash(off)]
+	return ok && int(idx) == off
+}
+
+func (s *arenaShard) expired(off int) bool {
+	expireAt := int64(binary.LittleEndian.Uint64(s.buf[off+expireAtOffset:]))
+	return expireAt != 0 && s.opts.Expired(time.Unix(0, expireAt))
+}
+
+func (s *arenaShard) hash(off int) uint64 {
+	return binary.LittleEndian.Uint64(s.buf[off+hashOffset:])
+}
+
+func (s *arenaShard) keyLen(off int) int {
+	return int(binary.LittleEndian.Uint16(s.buf[off+keyLenOffset:]))
+}
+
+func (s *arenaShard) valueLen(off int) int {
+	return int(binary.LittleEndian.Uint32(s.buf[off+valueLenOffset:]))
+}
+
+func (s *arenaShard) size(off int) int {
+	return headerSize + s.keyLen(off) + s.valueLen(off)
+}
+
+func (s *arenaShard) key(off int) string {
+	start := off + headerSize
+	return string(s.buf[start : start+s.keyLen(off)])
+}
+
+// keyEqual 比较 off 处的 key，不会分配内存
+func (s *arenaShard) keyEqual(off int, key string) bool {
+	start := off + headerSize
+	return string(s.buf[start:start+s.keyLen(off)]) == key
+}
+
+func (s *arenaShard) value(off int) []byte {
+	start := off + headerSize + s.keyLen(off)
+	return s.buf[start : start+s.valueLen(off)]
+}
+
+// evicted 统计并调用 onEvicted，off 处的 entry 已经从索引中删除
+func (s *arenaShard) evicted(off int, reason cache.EvictReason) {
+	if reason != cache.EvictDeleted {
+		s.evict()
+	}
+	if s.onEvicted == nil {
+		return
+	}
+	value := make([]byte, s.valueLen(off))
+	copy(value, s.value(off))
+	s.onEvicted(s.key(off), value, reason)
+}
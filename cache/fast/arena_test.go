@@ -0,0 +1,105 @@
+package fast
+
+import (
+	"cache"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/matryer/is"
+)
+
+func TestArenaSet(t *testing.T) {
+	is := is.New(t)
+
+	c := NewArenaCache(1024, 4, nil)
+	is.NoErr(c.Set("k1", []byte("v1")))
+	is.NoErr(c.Set("k2", []byte{}))
+	is.Equal(c.Get("k1"), []byte("v1"))
+	is.Equal(c.Get("k2"), []byte{})
+	is.Equal(c.Get("k3"), nil)
+
+	is.NoErr(c.Set("k1", []byte("value1")))
+	is.Equal(c.Get("k1"), []byte("value1"))
+	is.Equal(c.Len(), 2)
+
+	c.Del("k1")
+	is.Equal(c.Get("k1"), nil)
+	is.Equal(c.Len(), 1)
+
+	is.Equal(c.Set("big", make([]byte, 1024)), ErrEntryTooLarge)
+}
+
+func TestArenaWrapAround(t *testing.T) {
+	is := is.New(t)
+
+	evicted := make([]string, 0)
+	onEvicted := func(key string, value interface{}, reason cache.EvictReason) {
+		is.Equal(reason, cache.EvictCapacity)
+		is.Equal(value, []byte(key))
+		evicted = append(evicted, key)
+	}
+	// 每个 entry 占 headerSize+2+2 字节，缓冲区可以放 4 个
+	c := NewArenaCache((headerSize+4)*4+10, 1, onEvicted)
+	for i := 10; i < 30; i++ {
+		key := strconv.Itoa(i)
+		is.NoErr(c.Set(key, []byte(key)))
+		is.Equal(c.Get(key), []byte(key))
+	}
+
+	is.Equal(c.Len(), 4)
+	is.Equal(len(evicted), 16)
+	for i, key := range evicted {
+		is.Equal(key, strconv.Itoa(i+10))
+	}
+	for i := 26; i < 30; i++ {
+		is.Equal(c.Get(strconv.Itoa(i)), []byte(strconv.Itoa(i)))
+	}
+}
+
+func TestArenaSteadyWrap(t *testing.T) {
+	is := is.New(t)
+
+	// 每个 entry 正好 100 字节，缓冲区放满 10 个后反复绕回，每次写入只淘汰最旧的一个
+	c := NewArenaCache(1000, 1, nil)
+	for i := 0; i < 100; i++ {
+		key := strconv.Itoa(100 + i)
+		is.NoErr(c.Set(key, make([]byte, 100-headerSize-len(key))))
+		if i >= 9 {
+			is.Equal(c.Len(), 10)
+		}
+	}
+	is.Equal(c.Get("189"), nil)
+	is.Equal(len(c.Get("190")), 100-headerSize-3)
+}
+
+func TestArenaCollision(t *testing.T) {
+	is := is.New(t)
+
+	s := newArenaShard(1024, nil, cache.NewOptions())
+	is.NoErr(s.set("k1", 1, []byte("v1"), time.Time{}))
+	// 不同的 key 哈希值相同，不能返回其他 key 的值
+	is.Equal(s.get("k2", 1), nil)
+	is.Equal(s.stat().Collisions, uint64(1))
+
+	is.NoErr(s.set("k2", 1, []byte("v2"), time.Time{}))
+	is.Equal(s.get("k2", 1), []byte("v2"))
+	is.Equal(s.get("k1", 1), nil)
+	is.Equal(s.len(), 1)
+}
+
+func TestArenaTTL(t *testing.T) {
+	is := is.New(t)
+
+	now := time.Unix(1600000000, 0)
+	clock := func() time.Time { return now }
+	c := NewArenaCache(1024, 1, nil, cache.WithClock(clock))
+	is.NoErr(c.SetWithTTL("k1", []byte("v1"), time.Second))
+	is.NoErr(c.Set("k2", []byte("v2")))
+
+	now = now.Add(time.Second)
+	is.Equal(c.Get("k1"), nil)
+	is.Equal(c.DeleteExpired(), 1)
+	is.Equal(c.Len(), 1)
+	is.Equal(c.Get("k2"), []byte("v2"))
+}
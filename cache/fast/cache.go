@@ -1,20 +1,46 @@
 package fast
 
+import (
+	"cache"
+	"io"
+	"sort"
+	"time"
+)
+
 type fastCache struct {
 	shards    []*cacheShard
 	shardMask uint64
 	hash      fnv64a
+	// 逻辑时钟，用于在分片之间比较 entry 的新旧
+	clock uint64
+
+	stopJanitor func()
 }
 
-func NewFastCache(maxEntries, shardsNum int, onEvicted func(key string, value interface{})) *fastCache {
+// NewFastCache 创建一个分片的并发安全 Cache，shardsNum 必须是 2 的幂。
+// maxEntries 是每个分片最大存放的 entry 个数；maxBytes 是所有分片总的最大字节数，平均分配到每个分片，
+// 包括值、key 以及每个 entry 的固定开销，值通过 cache.CalcLen 计算。两者为 0 表示不限制。
+// opts 中的 CleanupInterval 用于启动后台清理过期 entry
+func NewFastCache(maxEntries, maxBytes, shardsNum int, onEvicted cache.OnEvicted, opts ...cache.Option) *fastCache {
+	checkShardsNum(shardsNum)
+	shardBytes := maxBytes / shardsNum
+	if maxBytes > 0 && shardBytes == 0 {
+		shardBytes = 1
+	}
+
+	o := cache.NewOptions(opts...)
+	onEvicted = cache.ObserveEvicted(o.Metrics, onEvicted)
 	fastCache := &fastCache{
 		hash:      newDefaultHasher(),
 		shards:    make([]*cacheShard, shardsNum),
 		shardMask: uint64(shardsNum - 1),
 	}
 	for i := 0; i < shardsNum; i++ {
-		fastCache.shards[i] = newCacheShard(maxEntries, onEvicted)
+		fastCache.shards[i] = newCacheShard(maxEntries, shardBytes, onEvicted, o, &fastCache.clock)
 	}
+	fastCache.stopJanitor = cache.StartJanitor(o.CleanupInterval, func() {
+		fastCache.DeleteExpired()
+	})
 
 	return fastCache
 }
@@ -25,13 +51,24 @@ func (c *fastCache) getShard(key string) *cacheShard {
 }
 
 func (c *fastCache) Set(key string, value interface{}) {
-	c.getShard(key).set(key, value)
+	shard := c.getShard(key)
+	shard.set(key, value, shard.opts.ExpireAt(shard.opts.DefaultTTL))
+}
+
+func (c *fastCache) SetWithTTL(key string, value interface{}, ttl time.Duration) {
+	shard := c.getShard(key)
+	shard.set(key, value, shard.opts.ExpireAt(ttl))
 }
 
 func (c *fastCache) Get(key string) interface{} {
 	return c.getShard(key).get(key)
 }
 
+// Contains 判断 key 是否存在且未过期，只加读锁，不会调整淘汰顺序，也不计入命中率
+func (c *fastCache) Contains(key string) bool {
+	return c.getShard(key).contains(key)
+}
+
 func (c *fastCache) Del(key string) {
 	c.getShard(key).del(key)
 }
@@ -44,6 +81,95 @@ func (c *fastCache) Len() int {
 	return length
 }
 
+// UsedBytes 实现 cache.MemoryUsage
+func (c *fastCache) UsedBytes() int {
+	n := 0
+	for _, shard := range c.shards {
+		n += shard.used()
+	}
+	return n
+}
+
+// DelOldest 比较每个分片中最旧的 entry 的时间戳，淘汰其中最旧的一个。
+// 比较和淘汰之间没有加全局锁，并发写入时淘汰的可能不是严格意义上最旧的
 func (c *fastCache) DelOldest() {
-	panic("no implements")
+	var (
+		oldest   *cacheShard
+		oldestAt uint64
+	)
+	for _, shard := range c.shards {
+		if accessedAt, ok := shard.oldest(); ok && (oldest == nil || accessedAt < oldestAt) {
+			oldest, oldestAt = shard, accessedAt
+		}
+	}
+	if oldest != nil {
+		oldest.delOldest()
+	}
+}
+
+func (c *fastCache) DeleteExpired() int {
+	n := 0
+	for _, shard := range c.shards {
+		n += shard.deleteExpired()
+	}
+	return n
+}
+
+// Snapshot 保存所有分片中未过期的记录，按最近访问的先后排序，写入快照期间逐个锁住分片
+func (c *fastCache) Snapshot(w io.Writer) error {
+	var ens []entry
+	for _, shard := range c.shards {
+		ens = shard.entries(ens)
+	}
+	sort.Slice(ens, func(i, j int) bool {
+		return ens[i].accessedAt < ens[j].accessedAt
+	})
+
+	entries := make([]cache.SnapshotEntry, len(ens))
+	for i, en := range ens {
+		entries[i] = cache.SnapshotEntry{Key: en.key, Value: en.value, ExpireAt: en.expireAt}
+	}
+	return cache.WriteSnapshot(w, c.shards[0].opts.Codec, entries)
+}
+
+// Restore 按快照中的顺序写入记录，恢复后淘汰顺序和保存时一致
+func (c *fastCache) Restore(r io.Reader) error {
+	opts := c.shards[0].opts
+	entries, err := cache.ReadSnapshot(r, opts.Codec)
+	if err != nil {
+		return err
+	}
+	for _, se := range entries {
+		key, value, err := cache.Unpack[string, interface{}](se)
+		if err != nil {
+			return err
+		}
+		if !opts.Expired(se.ExpireAt) {
+			c.getShard(key).set(key, value, se.ExpireAt)
+		}
+	}
+	return nil
+}
+
+// Stat 返回所有分片的统计信息之和
+func (c *fastCache) Stat() Stat {
+	var stat Stat
+	for _, shard := range c.shards {
+		stat.add(shard.stat())
+	}
+	return stat
+}
+
+// ShardStats 返回每个分片的统计信息，可以用来观察 key 的分布是否均匀
+func (c *fastCache) ShardStats() []Stat {
+	stats := make([]Stat, len(c.shards))
+	for i, shard := range c.shards {
+		stats[i] = shard.stat()
+	}
+	return stats
+}
+
+// Close 停止后台清理
+func (c *fastCache) Close() {
+	c.stopJanitor()
 }
@@ -0,0 +1,93 @@
+package fast
+
+import (
+	"cache"
+	"strconv"
+	"testing"
+
+	"github.com/matryer/is"
+)
+
+func TestDelOldest(t *testing.T) {
+	is := is.New(t)
+
+	c := NewFastCache(0, 0, 8, nil)
+	for i := 0; i < 20; i++ {
+		c.Set(strconv.Itoa(i), i)
+	}
+	// 访问后变新，不会被淘汰
+	is.Equal(c.Get("0"), 0)
+
+	for i := 1; i <= 5; i++ {
+		c.DelOldest()
+		is.Equal(c.Get(strconv.Itoa(i)), nil)
+	}
+	is.Equal(c.Get("0"), 0)
+	is.Equal(c.Len(), 15)
+
+	for i := 0; i < 20; i++ {
+		c.DelOldest()
+	}
+	is.Equal(c.Len(), 0)
+}
+
+func TestMaxBytes(t *testing.T) {
+	is := is.New(t)
+
+	// 每个分片最多 4 个值为 int32、key 长度不超过 2 的 entry
+	entryBytes := cache.CalcLen(int32(0)) + cache.KeySize("10") + entryOverhead
+	shardBytes := 4 * entryBytes
+	c := NewFastCache(0, 4*shardBytes, 4, nil)
+	for i := 0; i < 100; i++ {
+		c.Set(strconv.Itoa(i), int32(i))
+	}
+	is.True(c.Len() <= 16)
+	for _, shard := range c.shards {
+		is.True(shard.usedBytes <= shardBytes)
+	}
+
+	// 写入占满整个分片的值时，其他 entry 都会被淘汰
+	big := make([]byte, shardBytes-cache.CalcLen([]byte{})-cache.KeySize("big")-entryOverhead)
+	c.Set("big", big)
+	is.Equal(c.Get("big"), big)
+	is.Equal(c.getShard("big").usedBytes, shardBytes)
+	is.Equal(c.getShard("big").len(), 1)
+}
+
+func TestStat(t *testing.T) {
+	is := is.New(t)
+
+	c := NewFastCache(1, 0, 2, nil)
+	c.Set("k1", 1)
+	c.Set("k2", 2)
+	c.Set("k3", 3)
+	c.Get("k1")
+	c.Get("k3")
+	c.Get("unknown")
+	c.Del("k3")
+
+	stat := c.Stat()
+	is.Equal(stat.Hits+stat.Misses, uint64(3))
+	is.Equal(stat.Evictions, uint64(3-c.Len()-1))
+
+	var sum Stat
+	for _, s := range c.ShardStats() {
+		sum.add(s)
+	}
+	is.Equal(sum, stat)
+}
+
+func TestShardsNum(t *testing.T) {
+	for _, n := range []int{0, 3, 6, 100} {
+		func() {
+			defer func() {
+				if recover() == nil {
+					t.Errorf("shardsNum %d: expected panic", n)
+				}
+			}()
+			NewFastCache(0, 0, n, nil)
+		}()
+	}
+	NewFastCache(0, 0, 1, nil)
+	NewFastCache(0, 0, 64, nil)
+}
@@ -28,3 +28,8 @@ func (f fnv64a) Sum64(key string) uint64 {
 
 	return hash
 }
+
+// Sum64 使用 FNV-1a 计算 key 的 64 位哈希值，供其他包（如 consistenthash）复用
+func Sum64(key string) uint64 {
+	return newDefaultHasher().Sum64(key)
+}
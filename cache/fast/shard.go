@@ -1,88 +1,223 @@
 package fast
 
 import (
+	"cache"
 	"container/list"
 	"sync"
+	"sync/atomic"
+	"time"
+	"unsafe"
 )
 
+// promoteBufferSize 每个分片缓冲的待提升 entry 个数，缓冲区满时丢弃，和 Ristretto 一样用少量精度换取读操作不加写锁
+const promoteBufferSize = 64
+
 type cacheShard struct {
 	locker sync.RWMutex
 
 	// 最大存放 entry 个数
 	maxEntries int
+	// 最大的字节数，包括值、key 以及 entryOverhead；0 表示不限制
+	maxBytes int
+	// 已使用的字节数，包括值、key 以及 entryOverhead
+	usedBytes int
 	// 当一个 entry 从缓存中移除是调用该回调函数，默认为 nil
 	// groupcache 中的 key 是任意的可比较类型；value 是 interface{}
-	onEvicted func(key string, value interface{})
+	onEvicted cache.OnEvicted
+
+	opts cache.Options
 
 	ll    *list.List
 	cache map[string]*list.Element
+	// get 只加读锁，不能调整链表，命中的 entry 先放入缓冲区，在下一次加写锁时移到尾部
+	promotions chan *list.Element
+
+	// 所有分片共享的逻辑时钟，每次写入或访问加一
+	clock *uint64
+	shardStat
 }
 
+// entryOverhead 每个 entry 除了 key 和值以外的开销：entry 结构体和链表节点
+const entryOverhead = int(unsafe.Sizeof(entry{})) + cache.ListElementSize
+
 type entry struct {
 	key   string
 	value interface{}
+	// 过期时间，零值表示永不过期
+	expireAt time.Time
+	// 最近一次写入或访问的逻辑时间戳，用于在分片之间比较新旧；读锁下也会更新，使用原子操作。
+	// 缓冲的提升可能被丢弃，链表中的顺序和它不一定完全一致
+	accessedAt uint64
+	// entry 占用的字节数，包括 key 和 entryOverhead
+	size int
 }
 
-// new 创建一个新的 cacheShard，如果 maxBytes 是 0，表示没有容量限制
-func newCacheShard(maxEntries int, onEvicted func(key string, value interface{})) *cacheShard {
+// new 创建一个新的 cacheShard，如果 maxEntries 和 maxBytes 都是 0，表示没有容量限制
+func newCacheShard(maxEntries, maxBytes int, onEvicted cache.OnEvicted, opts cache.Options, clock *uint64) *cacheShard {
 	return &cacheShard{
 		maxEntries: maxEntries,
+		maxBytes:   maxBytes,
 		onEvicted:  onEvicted,
+		opts:       opts,
 		ll:         list.New(),
 		cache:      make(map[string]*list.Element),
+		promotions: make(chan *list.Element, promoteBufferSize),
+		clock:      clock,
 	}
 }
 
-// set 往 Cache 尾部增加一个元素（如果已经存在，则放入尾部，并更新值）
-func (c *cacheShard) set(key string, value interface{}) {
+// set 往 Cache 尾部增加一个元素（如果已经存在，则放入尾部，并更新值），expireAt 为零值表示永不过期
+func (c *cacheShard) set(key string, value interface{}, expireAt time.Time) {
 	c.locker.Lock()
 	defer c.locker.Unlock()
+	c.promote()
 
+	size := cache.CalcLen(value) + cache.KeySize(key) + entryOverhead
 	if e, ok := c.cache[key]; ok {
 		c.ll.MoveToBack(e)
 		en := e.Value.(*entry)
+		c.usedBytes += size - en.size
 		en.value = value
-		return
+		en.expireAt = expireAt
+		en.size = size
+		atomic.StoreUint64(&en.accessedAt, c.tick())
+	} else {
+		en := &entry{key: key, value: value, expireAt: expireAt, accessedAt: c.tick(), size: size}
+		c.cache[key] = c.ll.PushBack(en)
+		c.usedBytes += size
 	}
 
-	en := &entry{key, value}
-	e := c.ll.PushBack(en)
-	c.cache[key] = e
-
-	if c.maxEntries > 0 && c.ll.Len() > c.maxEntries {
-		c.removeElement(c.ll.Front())
+	for c.ll.Len() > 0 && ((c.maxEntries > 0 && c.ll.Len() > c.maxEntries) ||
+		(c.maxBytes > 0 && c.usedBytes > c.maxBytes)) {
+		c.removeElement(c.ll.Front(), cache.EvictCapacity)
 	}
 }
 
-// get 从 cache 中获取 key 对应的值，nil 表示 key 不存在
+// get 从 cache 中获取 key 对应的值，nil 表示 key 不存在或已过期。
+// 只加读锁，命中的 entry 通过缓冲区延迟移到尾部
 func (c *cacheShard) get(key string) interface{} {
+	c.locker.RLock()
+	e, ok := c.cache[key]
+	if !ok {
+		c.locker.RUnlock()
+		c.miss()
+		return nil
+	}
+	en := e.Value.(*entry)
+	if c.opts.Expired(en.expireAt) {
+		// 读锁下不能删除，交给后台清理或下一次写入
+		c.locker.RUnlock()
+		c.miss()
+		return nil
+	}
+	value := en.value
+	atomic.StoreUint64(&en.accessedAt, c.tick())
+	c.locker.RUnlock()
+
+	c.hit()
+	c.bufferPromotion(e)
+	return value
+}
+
+// contains 判断 key 是否存在且未过期
+func (c *cacheShard) contains(key string) bool {
 	c.locker.RLock()
 	defer c.locker.RUnlock()
 
-	if e, ok := c.cache[key]; ok {
+	e, ok := c.cache[key]
+	return ok && !c.opts.Expired(e.Value.(*entry).expireAt)
+}
+
+// bufferPromotion 将 e 放入缓冲区，缓冲区满时如果能拿到写锁就立即处理，否则丢弃这次提升
+func (c *cacheShard) bufferPromotion(e *list.Element) {
+	select {
+	case c.promotions <- e:
+		return
+	default:
+	}
+
+	if c.locker.TryLock() {
+		c.promote()
 		c.ll.MoveToBack(e)
-		return e.Value.(*entry).value
+		c.locker.Unlock()
 	}
+}
 
-	return nil
+// promote 将缓冲区中的 entry 移到尾部，需要持有写锁；已经被移除的 entry 会被 MoveToBack 忽略
+func (c *cacheShard) promote() {
+	for {
+		select {
+		case e := <-c.promotions:
+			c.ll.MoveToBack(e)
+		default:
+			return
+		}
+	}
 }
 
 // del 从 cache 中删除 key 对应的元素
 func (c *cacheShard) del(key string) {
 	c.locker.Lock()
 	defer c.locker.Unlock()
+	c.promote()
 
 	if e, ok := c.cache[key]; ok {
-		c.removeElement(e)
+		c.removeElement(e, cache.EvictDeleted)
+	}
+}
+
+// oldest 返回最旧的记录的逻辑时间戳，分片为空时 ok 为 false
+func (c *cacheShard) oldest() (accessedAt uint64, ok bool) {
+	c.locker.Lock()
+	defer c.locker.Unlock()
+	c.promote()
+
+	if e := c.ll.Front(); e != nil {
+		return atomic.LoadUint64(&e.Value.(*entry).accessedAt), true
 	}
+	return 0, false
 }
 
 // delOldest 从 cache 中删除最旧的记录
 func (c *cacheShard) delOldest() {
 	c.locker.Lock()
 	defer c.locker.Unlock()
+	c.promote()
+
+	c.removeElement(c.ll.Front(), cache.EvictCapacity)
+}
+
+// deleteExpired 删除所有已过期的记录，返回删除的个数
+func (c *cacheShard) deleteExpired() int {
+	c.locker.Lock()
+	defer c.locker.Unlock()
+	c.promote()
+
+	n := 0
+	for e := c.ll.Front(); e != nil; {
+		next := e.Next()
+		if c.opts.Expired(e.Value.(*entry).expireAt) {
+			c.removeElement(e, cache.EvictExpired)
+			n++
+		}
+		e = next
+	}
+	return n
+}
+
+// entries 将所有未过期的记录按从旧到新的顺序追加到 ens 中
+func (c *cacheShard) entries(ens []entry) []entry {
+	c.locker.Lock()
+	defer c.locker.Unlock()
+	c.promote()
 
-	c.removeElement(c.ll.Front())
+	for e := c.ll.Front(); e != nil; e = e.Next() {
+		en := e.Value.(*entry)
+		if !c.opts.Expired(en.expireAt) {
+			ens = append(ens, entry{key: en.key, value: en.value, expireAt: en.expireAt, accessedAt: atomic.LoadUint64(&en.accessedAt)})
+		}
+	}
+	return ens
 }
 
 // len 返回当前 cache 中的记录数
@@ -93,7 +228,15 @@ func (c *cacheShard) len() int {
 	return c.ll.Len()
 }
 
-func (c *cacheShard) removeElement(e *list.Element) {
+// used 返回已使用的字节数
+func (c *cacheShard) used() int {
+	c.locker.RLock()
+	defer c.locker.RUnlock()
+
+	return c.usedBytes
+}
+
+func (c *cacheShard) removeElement(e *list.Element, reason cache.EvictReason) {
 	if e == nil {
 		return
 	}
@@ -101,8 +244,16 @@ func (c *cacheShard) removeElement(e *list.Element) {
 	c.ll.Remove(e)
 	en := e.Value.(*entry)
 	delete(c.cache, en.key)
+	c.usedBytes -= en.size
+	if reason != cache.EvictDeleted {
+		c.evict()
+	}
 
 	if c.onEvicted != nil {
-		c.onEvicted(en.key, en.value)
+		c.onEvicted(en.key, en.value, reason)
 	}
 }
+
+func (c *cacheShard) tick() uint64 {
+	return atomic.AddUint64(c.clock, 1)
+}
@@ -0,0 +1,59 @@
+package fast
+
+import (
+	"fmt"
+	"sync/atomic"
+)
+
+// Stat 分片或整个缓存的统计信息
+type Stat struct {
+	Hits, Misses uint64
+	// 因容量不足（包括 DelOldest）或过期被移除的 entry 个数，不包括 Del
+	Evictions uint64
+	// 不同的 key 哈希值相同的次数，只有 NewArenaCache 按哈希值索引，才会发生冲突
+	Collisions uint64
+}
+
+func (s *Stat) add(o Stat) {
+	s.Hits += o.Hits
+	s.Misses += o.Misses
+	s.Evictions += o.Evictions
+	s.Collisions += o.Collisions
+}
+
+// shardStat 分片的计数器，读锁下也会更新，使用原子操作
+type shardStat struct {
+	hits, misses, evictions, collisions uint64
+}
+
+func (s *shardStat) hit() {
+	atomic.AddUint64(&s.hits, 1)
+}
+
+func (s *shardStat) miss() {
+	atomic.AddUint64(&s.misses, 1)
+}
+
+func (s *shardStat) evict() {
+	atomic.AddUint64(&s.evictions, 1)
+}
+
+func (s *shardStat) collide() {
+	atomic.AddUint64(&s.collisions, 1)
+}
+
+func (s *shardStat) stat() Stat {
+	return Stat{
+		Hits:       atomic.LoadUint64(&s.hits),
+		Misses:     atomic.LoadUint64(&s.misses),
+		Evictions:  atomic.LoadUint64(&s.evictions),
+		Collisions: atomic.LoadUint64(&s.collisions),
+	}
+}
+
+// checkShardsNum 分片通过 hash&shardMask 选择，分片数必须是 2 的幂
+func checkShardsNum(shardsNum int) {
+	if shardsNum <= 0 || shardsNum&(shardsNum-1) != 0 {
+		panic(fmt.Sprintf("fast: shardsNum must be a power of two, got %d", shardsNum))
+	}
+}
@@ -3,100 +3,196 @@ package fifo
 import (
 	"cache"
 	"container/list"
+	"io"
+	"time"
+	"unsafe"
 )
 
-// fifo 是一个 FIFO cache。它不是并发安全的。
-type fifo struct {
+// Cache 是一个 FIFO cache。它不是并发安全的。
+type Cache[K comparable, V any] struct {
 	// 缓存的最大容量，单位字节
 	// groupcache 使用的是最大存放 entry 个数
 	maxBytes int
+	// 计算值占用的字节数
+	sizer cache.Sizer[V]
 	// 当一个 entry 从缓存中移除时调用该回调函数，默认为 nil
 	// groupcache 中的 key 是任意的可比较类型；value 是 interface{}
-	onEvicted func(key string, value interface{})
+	onEvicted cache.TypedOnEvicted[K, V]
 
-	// 已使用的字节数，只包括值，key 不算
+	// 已使用的字节数，包括值、key 以及 entry 结构体等开销
 	usedBytes int
+	// 每个 entry 除了 key 和值以外的开销，见 entryOverhead
+	overhead int
+
+	opts cache.Options
 
 	ll    *list.List
-	cache map[string]*list.Element
+	cache map[K]*list.Element
 }
 
-type entry struct {
-	key   string
-	value interface{}
+type entry[K comparable, V any] struct {
+	key   K
+	value V
+	// 过期时间，零值表示永不过期
+	expireAt time.Time
+	// 写入时计算的 entry 占用的字节数，包括 key 和 entryOverhead
+	size int
 }
 
-func (e *entry) Len() int {
-	return cache.CalcLen(e.value)
+// entryOverhead 每个 entry 除了 key 和值以外的开销：entry 结构体和链表节点
+func entryOverhead[K comparable, V any]() int {
+	return int(unsafe.Sizeof(entry[K, V]{})) + cache.ListElementSize
 }
 
 // New 创建一个新的 Cache，如果 maxBytes 是 0，表示没有容量限制
-func New(maxBytes int, onEvicted func(string, interface{})) cache.Cache {
-	return &fifo{
+func New(maxBytes int, onEvicted cache.OnEvicted, opts ...cache.Option) cache.Cache {
+	return cache.Untyped(NewCache[string, interface{}](maxBytes, nil, cache.TypedOnEvicted[string, interface{}](onEvicted), opts...))
+}
+
+// NewCache 创建一个新的泛型 Cache，如果 maxBytes 是 0，表示没有容量限制；sizer 计算值占用的字节数，为 nil 时使用 cache.CalcLen，key 和 entry 本身的开销另外计算
+func NewCache[K comparable, V any](maxBytes int, sizer cache.Sizer[V], onEvicted cache.TypedOnEvicted[K, V], opts ...cache.Option) *Cache[K, V] {
+	o := cache.NewOptions(opts...)
+	return &Cache[K, V]{
 		maxBytes:  maxBytes,
-		onEvicted: onEvicted,
+		sizer:     cache.SizerOrDefault(sizer),
+		overhead:  entryOverhead[K, V](),
+		onEvicted: cache.ObserveEvicted(o.Metrics, onEvicted),
+		opts:      o,
 		ll:        list.New(),
-		cache:     make(map[string]*list.Element),
+		cache:     make(map[K]*list.Element),
 	}
 }
 
-// Set 往 Cache 尾部增加一个元素（如果已经存在，则放入尾部，并修改值）
-func (f *fifo) Set(key string, value interface{}) {
+// Set 往 Cache 尾部增加一个元素（如果已经存在，则放入尾部，并修改值），过期时间为默认的 TTL
+func (f *Cache[K, V]) Set(key K, value V) {
+	f.SetWithTTL(key, value, f.opts.DefaultTTL)
+}
+
+// SetWithTTL 同 Set，ttl 小于等于 0 表示永不过期
+func (f *Cache[K, V]) SetWithTTL(key K, value V, ttl time.Duration) {
+	f.set(key, value, f.opts.ExpireAt(ttl))
+}
+
+func (f *Cache[K, V]) set(key K, value V, expireAt time.Time) {
+	size := f.sizer(value) + cache.KeySize(key) + f.overhead
 	if e, ok := f.cache[key]; ok {
 		f.ll.MoveToBack(e)
-		et := e.Value.(*entry)
-		f.usedBytes = f.usedBytes - cache.CalcLen(et.value) + cache.CalcLen(value)
+		et := e.Value.(*entry[K, V])
+		f.usedBytes = f.usedBytes - et.size + size
 		et.value = value
+		et.expireAt = expireAt
+		et.size = size
 		return
 	}
 
-	et := &entry{key, value}
+	et := &entry[K, V]{key, value, expireAt, size}
 	e := f.ll.PushBack(et)
 	f.cache[key] = e
 
-	f.usedBytes += et.Len()
+	f.usedBytes += size
 	if f.maxBytes > 0 && f.usedBytes > f.maxBytes {
-		f.DelOldest()
+		f.removeElement(f.ll.Front(), cache.EvictCapacity)
 	}
 }
 
-// Get 从 cache 中获取 key 对应的值，nil 表示 key 不存在
-func (f *fifo) Get(key string) interface{} {
+// Get 从 cache 中获取 key 对应的值，ok 为 false 表示 key 不存在或已过期
+func (f *Cache[K, V]) Get(key K) (value V, ok bool) {
 	if e, ok := f.cache[key]; ok {
-		return e.Value.(*entry).value
+		et := e.Value.(*entry[K, V])
+		if f.opts.Expired(et.expireAt) {
+			f.removeElement(e, cache.EvictExpired)
+			return value, false
+		}
+		return et.value, true
 	}
 
-	return nil
+	return value, false
+}
+
+// Contains 判断 key 是否存在且未过期，不会调整淘汰顺序
+func (f *Cache[K, V]) Contains(key K) bool {
+	e, ok := f.cache[key]
+	return ok && !f.opts.Expired(e.Value.(*entry[K, V]).expireAt)
 }
 
 // Del 从 cache 中删除 key 对应的记录
-func (f *fifo) Del(key string) {
+func (f *Cache[K, V]) Del(key K) {
 	if e, ok := f.cache[key]; ok {
-		f.removeElement(e)
+		f.removeElement(e, cache.EvictDeleted)
 	}
 }
 
 // DelOldest 从 cache 中删除最旧的记录
-func (f *fifo) DelOldest() {
-	f.removeElement(f.ll.Front())
+func (f *Cache[K, V]) DelOldest() {
+	f.removeElement(f.ll.Front(), cache.EvictCapacity)
+}
+
+// DeleteExpired 删除所有已过期的记录，返回删除的个数
+func (f *Cache[K, V]) DeleteExpired() int {
+	n := 0
+	for e := f.ll.Front(); e != nil; {
+		next := e.Next()
+		if f.opts.Expired(e.Value.(*entry[K, V]).expireAt) {
+			f.removeElement(e, cache.EvictExpired)
+			n++
+		}
+		e = next
+	}
+
+	return n
+}
+
+// Snapshot 按写入的先后的顺序保存所有未过期的记录
+func (f *Cache[K, V]) Snapshot(w io.Writer) error {
+	entries := make([]cache.SnapshotEntry, 0, f.ll.Len())
+	for e := f.ll.Front(); e != nil; e = e.Next() {
+		et := e.Value.(*entry[K, V])
+		if !f.opts.Expired(et.expireAt) {
+			entries = append(entries, cache.SnapshotEntry{Key: et.key, Value: et.value, ExpireAt: et.expireAt})
+		}
+	}
+	return cache.WriteSnapshot(w, f.opts.Codec, entries)
+}
+
+// Restore 按快照中的顺序写入记录，恢复后淘汰顺序和保存时一致
+func (f *Cache[K, V]) Restore(r io.Reader) error {
+	entries, err := cache.ReadSnapshot(r, f.opts.Codec)
+	if err != nil {
+		return err
+	}
+	for _, se := range entries {
+		key, value, err := cache.Unpack[K, V](se)
+		if err != nil {
+			return err
+		}
+		if !f.opts.Expired(se.ExpireAt) {
+			f.set(key, value, se.ExpireAt)
+		}
+	}
+	return nil
+}
+
+// UsedBytes 实现 cache.MemoryUsage
+func (f *Cache[K, V]) UsedBytes() int {
+	return f.usedBytes
 }
 
-// Len 返回当前 cache 中的记录数
-func (f *fifo) Len() int {
+// Len 返回当前 cache 中的记录数，包括已过期但还未删除的记录
+func (f *Cache[K, V]) Len() int {
 	return f.ll.Len()
 }
 
-func (f *fifo) removeElement(e *list.Element) {
+func (f *Cache[K, V]) removeElement(e *list.Element, reason cache.EvictReason) {
 	if e == nil {
 		return
 	}
 
 	f.ll.Remove(e)
-	et := e.Value.(*entry)
-	f.usedBytes -= et.Len()
+	et := e.Value.(*entry[K, V])
+	f.usedBytes -= et.size
 	delete(f.cache, et.key)
 
 	if f.onEvicted != nil {
-		f.onEvicted(et.key, et.value)
+		f.onEvicted(et.key, et.value, reason)
 	}
 }
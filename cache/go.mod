@@ -1,5 +1,5 @@
 module cache
 
-go 1.15
+go 1.18
 
 require github.com/matryer/is v1.4.0
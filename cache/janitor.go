@@ -0,0 +1,49 @@
+package cache
+
+import (
+	"sync"
+	"time"
+)
+
+// janitor 在后台定期调用 clean 清理过期的 entry
+type janitor struct {
+	stop chan struct{}
+	done chan struct{}
+}
+
+// StartJanitor 启动后台清理，每隔 interval 调用一次 clean，返回的 stop 函数会等待后台 goroutine 退出，
+// interval 小于等于 0 时不启动后台清理
+func StartJanitor(interval time.Duration, clean func()) (stop func()) {
+	if interval <= 0 {
+		return func() {}
+	}
+
+	j := &janitor{
+		stop: make(chan struct{}),
+		done: make(chan struct{}),
+	}
+	go j.run(interval, clean)
+
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			close(j.stop)
+			<-j.done
+		})
+	}
+}
+
+func (j *janitor) run(interval time.Duration, clean func()) {
+	defer close(j.done)
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			clean()
+		case <-j.stop:
+			return
+		}
+	}
+}
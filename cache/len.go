@@ -1,44 +1,173 @@
 package cache
 
 import (
-	"fmt"
-	"runtime"
+	"container/list"
+	"reflect"
+	"unsafe"
 )
 
+const (
+	stringHeaderSize = int(unsafe.Sizeof(""))
+	sliceHeaderSize  = int(unsafe.Sizeof([]byte(nil)))
+	pointerSize      = int(unsafe.Sizeof(uintptr(0)))
+
+	// ListElementSize container/list 中一个节点占用的字节数
+	ListElementSize = int(unsafe.Sizeof(list.Element{}))
+
+	// hmapSize runtime 中 map 头部的大小
+	hmapSize = 48
+)
+
+// CalcLen 计算值占用的字节数，包括 string、slice 的头部以及它们引用的内存；
+// 实现了 Value 的类型使用 Len，其他类型通过 SizeOf 用反射计算
 func CalcLen(value interface{}) int {
 	var n int
 	switch v := value.(type) {
 	case Value:
 		n = v.Len()
 	case string:
-		if runtime.GOARCH == "amd64" {
-			n = 16 + len(v)
-		} else {
-			n = 8 + len(v)
-		}
+		n = stringHeaderSize + len(v)
 	case []byte:
-		n = len(v)
+		n = sliceHeaderSize + cap(v)
 	case bool, int8, uint8:
 		n = 1
 	case int16, uint16:
 		n = 2
 	case int32, uint32, float32:
 		n = 4
-	case int64, uint64, float64:
-		n = 8
-	case int, uint:
-		if runtime.GOARCH == "amd64" {
-			n = 8
-		} else {
-			n = 4
-		}
-	case complex64:
+	case int64, uint64, float64, complex64:
 		n = 8
+	case int, uint, uintptr:
+		n = pointerSize
 	case complex128:
 		n = 16
 	default:
-		panic(fmt.Sprintf("%T is not implement cache.Value", value))
+		n = SizeOf(value)
 	}
 
 	return n
 }
+
+// KeySize 返回 key 保存在 map[K]*T 中占用的字节数：map 中的一项（按平均装载因子 6.5/8 折算）加上 key 引用的内存，
+// 如 string 的内容。缓存的 entry 结构体中通常还保存了一份 key，它的头部包含在结构体的大小中，内容和 map 共享
+func KeySize[K comparable](key K) int {
+	n := (int(unsafe.Sizeof(key)) + pointerSize + 1) * 8 * 10 / 65
+	if s, ok := interface{}(key).(string); ok {
+		return n + len(s)
+	}
+	if reflect.TypeOf(&key).Elem().Kind() == reflect.Interface {
+		// interface 的头部已经算在 map 中，动态值另外分配
+		return n + SizeOf(key)
+	}
+	return n + SizeOf(key) - int(unsafe.Sizeof(key))
+}
+
+// SizeOf 通过反射计算 value 占用的字节数，包括指针、slice、map、interface 等引用的内存，
+// 同一块内存被多次引用时只计算一次。map 的大小按装载因子估算，chan 和 func 只计算自身的大小。
+// value 为 nil 时返回 0
+func SizeOf(value interface{}) int {
+	if value == nil {
+		return 0
+	}
+	v := reflect.ValueOf(value)
+	s := sizer{seen: make(map[uintptr]struct{})}
+	return int(v.Type().Size()) + s.indirect(v)
+}
+
+type sizer struct {
+	// 已经计算过的指针、slice 和 map，避免循环引用和重复计算
+	seen map[uintptr]struct{}
+}
+
+// visit 返回 p 是否是第一次访问
+func (s sizer) visit(p uintptr) bool {
+	if _, ok := s.seen[p]; ok {
+		return false
+	}
+	s.seen[p] = struct{}{}
+	return true
+}
+
+// indirect 返回 v 引用的内存的大小，不包括 v 自身
+func (s sizer) indirect(v reflect.Value) int {
+	switch v.Kind() {
+	case reflect.String:
+		return v.Len()
+	case reflect.Ptr:
+		if v.IsNil() || !s.visit(v.Pointer()) {
+			return 0
+		}
+		return int(v.Type().Elem().Size()) + s.indirect(v.Elem())
+	case reflect.Interface:
+		if v.IsNil() {
+			return 0
+		}
+		// 除了指针、map 等本身就是一个指针的类型，保存在 interface 中的值会被分配到堆上
+		e := v.Elem()
+		switch e.Kind() {
+		case reflect.Ptr, reflect.Map, reflect.Chan, reflect.Func, reflect.UnsafePointer:
+			return s.indirect(e)
+		}
+		return int(e.Type().Size()) + s.indirect(e)
+	case reflect.Slice:
+		if v.IsNil() || !s.visit(v.Pointer()) {
+			return 0
+		}
+		n := v.Cap() * int(v.Type().Elem().Size())
+		if !pointerFree(v.Type().Elem()) {
+			for i := 0; i < v.Len(); i++ {
+				n += s.indirect(v.Index(i))
+			}
+		}
+		return n
+	case reflect.Array:
+		n := 0
+		if !pointerFree(v.Type().Elem()) {
+			for i := 0; i < v.Len(); i++ {
+				n += s.indirect(v.Index(i))
+			}
+		}
+		return n
+	case reflect.Struct:
+		n := 0
+		for i := 0; i < v.NumField(); i++ {
+			n += s.indirect(v.Field(i))
+		}
+		return n
+	case reflect.Map:
+		if v.IsNil() || !s.visit(v.Pointer()) {
+			return 0
+		}
+		t := v.Type()
+		// 每个 bucket 存放 8 项，每项还有 1 字节的 tophash，平均装载因子为 6.5
+		n := hmapSize + (int(t.Key().Size())+int(t.Elem().Size())+1)*v.Len()*8*10/65
+		if !pointerFree(t.Key()) || !pointerFree(t.Elem()) {
+			iter := v.MapRange()
+			for iter.Next() {
+				n += s.indirect(iter.Key()) + s.indirect(iter.Value())
+			}
+		}
+		return n
+	}
+	return 0
+}
+
+// pointerFree 判断类型 t 是否不引用其他内存，这样的 slice、array 不需要逐个元素计算
+func pointerFree(t reflect.Type) bool {
+	switch t.Kind() {
+	case reflect.Bool, reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr,
+		reflect.Float32, reflect.Float64, reflect.Complex64, reflect.Complex128:
+		return true
+	case reflect.Array:
+		return pointerFree(t.Elem())
+	case reflect.Struct:
+		for i := 0; i < t.NumField(); i++ {
+			if !pointerFree(t.Field(i).Type) {
+				return false
+			}
+		}
+		return true
+	}
+	return false
+}
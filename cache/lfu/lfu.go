@@ -3,97 +3,199 @@ package lfu
 import (
 	"cache"
 	"container/heap"
+	"io"
+	"sort"
+	"time"
 )
 
-// lfu 是一个 LFU cache。它不是并发安全的。
-type lfu struct {
+// Cache 是一个 LFU cache。它不是并发安全的。
+type Cache[K comparable, V any] struct {
 	// 缓存最大的容量，单位字节；
 	// groupcache 使用的是最大存放 entry 个数
 	maxBytes int
+	// 计算值占用的字节数
+	sizer cache.Sizer[V]
 	// 当一个 entry 从缓存中移除是调用该回调函数，默认为 nil
 	// groupcache 中的 key 是任意的可比较类型；value 是 interface{}
-	onEvicted func(string, interface{})
+	onEvicted cache.TypedOnEvicted[K, V]
 
-	// 已使用的字节数，只包括值，key 不算
+	// 已使用的字节数，包括值、key 以及 entry 结构体等开销
 	usedBytes int
+	// 每个 entry 除了 key 和值以外的开销，见 entryOverhead
+	overhead int
+	// 下一个写入的 entry 的序号
+	seq uint64
 
-	queue *queue
-	cache map[string]*entry
+	opts cache.Options
+
+	queue *queue[K, V]
+	cache map[K]*entry[K, V]
+}
+
+// New 创建一个新的 Cache，如果 maxBytes 是 0，表示没有容量限制；访问频率相同时先淘汰 key 较小的
+func New(maxBytes int, onEvicted cache.OnEvicted, opts ...cache.Option) cache.Cache {
+	l := NewCache[string, interface{}](maxBytes, nil, cache.TypedOnEvicted[string, interface{}](onEvicted), opts...)
+	l.queue.keyLess = func(a, b string) bool {
+		return a < b
+	}
+	return cache.Untyped(l)
 }
 
-// New 创建一个新的 Cache，如果 maxBytes 是 0，表示没有容量限制
-func New(maxBytes int, onEvicted func(string, interface{})) cache.Cache {
-	q := make(queue, 0, 1024)
-	return &lfu{
+// NewCache 创建一个新的泛型 Cache，如果 maxBytes 是 0，表示没有容量限制；sizer 计算值占用的字节数，为 nil 时使用 cache.CalcLen，key 和 entry 本身的开销另外计算。
+// 访问频率相同时先淘汰更早写入的
+func NewCache[K comparable, V any](maxBytes int, sizer cache.Sizer[V], onEvicted cache.TypedOnEvicted[K, V], opts ...cache.Option) *Cache[K, V] {
+	o := cache.NewOptions(opts...)
+	return &Cache[K, V]{
 		maxBytes:  maxBytes,
-		onEvicted: onEvicted,
-		queue:     &q,
-		cache:     make(map[string]*entry),
+		sizer:     cache.SizerOrDefault(sizer),
+		overhead:  entryOverhead[K, V](),
+		onEvicted: cache.ObserveEvicted(o.Metrics, onEvicted),
+		opts:      o,
+		queue:     &queue[K, V]{entries: make([]*entry[K, V], 0, 1024)},
+		cache:     make(map[K]*entry[K, V]),
 	}
 }
 
-// Set 往 Cache 增加一个元素（如果已经存在，更新值，并增加权重，重新构建堆）
-func (l *lfu) Set(key string, value interface{}) {
+// Set 往 Cache 增加一个元素（如果已经存在，更新值，并增加权重，重新构建堆），过期时间为默认的 TTL
+func (l *Cache[K, V]) Set(key K, value V) {
+	l.SetWithTTL(key, value, l.opts.DefaultTTL)
+}
+
+// SetWithTTL 同 Set，ttl 小于等于 0 表示永不过期
+func (l *Cache[K, V]) SetWithTTL(key K, value V, ttl time.Duration) {
+	l.set(key, value, l.opts.ExpireAt(ttl))
+}
+
+func (l *Cache[K, V]) set(key K, value V, expireAt time.Time) *entry[K, V] {
+	size := l.sizer(value) + cache.KeySize(key) + l.overhead
 	if e, ok := l.cache[key]; ok {
-		l.usedBytes = l.usedBytes - cache.CalcLen(e.value) + cache.CalcLen(value)
+		l.usedBytes = l.usedBytes - e.size + size
+		e.expireAt = expireAt
+		e.size = size
 		l.queue.update(e, value, e.weight+1)
-		return
+		return e
 	}
 
-	et := &entry{key: key, value: value}
-	if l.maxBytes > 0 && l.usedBytes+et.Len() > l.maxBytes {
-		l.removeElement(heap.Pop(l.queue))
+	et := &entry[K, V]{key: key, value: value, expireAt: expireAt, size: size, seq: l.seq}
+	l.seq++
+	if l.maxBytes > 0 && l.usedBytes+size > l.maxBytes {
+		l.DelOldest()
 	}
 
 	heap.Push(l.queue, et)
 	l.cache[key] = et
 
-	l.usedBytes += et.Len()
+	l.usedBytes += size
+	return et
 }
 
-// Get 从 cache 中获取 key 对应的值，nil 表示 key 不存在
-func (l *lfu) Get(key string) interface{} {
+// Get 从 cache 中获取 key 对应的值，ok 为 false 表示 key 不存在或已过期
+func (l *Cache[K, V]) Get(key K) (value V, ok bool) {
 	if e, ok := l.cache[key]; ok {
+		if l.opts.Expired(e.expireAt) {
+			heap.Remove(l.queue, e.index)
+			l.removeElement(e, cache.EvictExpired)
+			return value, false
+		}
+
 		l.queue.update(e, e.value, e.weight+1)
-		return e.value
+		return e.value, true
 	}
 
-	return nil
+	return value, false
+}
+
+// Contains 判断 key 是否存在且未过期，不会增加访问频率
+func (l *Cache[K, V]) Contains(key K) bool {
+	e, ok := l.cache[key]
+	return ok && !l.opts.Expired(e.expireAt)
 }
 
 // Del 从 cache 中删除 key 对应的元素
-func (l *lfu) Del(key string) {
+func (l *Cache[K, V]) Del(key K) {
 	if e, ok := l.cache[key]; ok {
 		heap.Remove(l.queue, e.index)
-		l.removeElement(e)
+		l.removeElement(e, cache.EvictDeleted)
 	}
 }
 
-// DelOldest 从 cache 中删除最旧的记录
-func (l *lfu) DelOldest() {
+// DelOldest 从 cache 中删除访问频率最低的记录
+func (l *Cache[K, V]) DelOldest() {
 	if l.queue.Len() == 0 {
 		return
 	}
-	l.removeElement(heap.Pop(l.queue))
+	l.removeElement(heap.Pop(l.queue).(*entry[K, V]), cache.EvictCapacity)
 }
 
-// Len 返回当前 cache 中的记录数
-func (l *lfu) Len() int {
-	return l.queue.Len()
+// DeleteExpired 删除所有已过期的记录，返回删除的个数
+func (l *Cache[K, V]) DeleteExpired() int {
+	n := 0
+	for _, e := range l.cache {
+		if l.opts.Expired(e.expireAt) {
+			heap.Remove(l.queue, e.index)
+			l.removeElement(e, cache.EvictExpired)
+			n++
+		}
+	}
+
+	return n
 }
 
-func (l *lfu) removeElement(x interface{}) {
-	if x == nil {
-		return
+// Snapshot 按淘汰顺序保存所有未过期的记录及其访问频率，恢复时容量不足会先淘汰频率低的
+func (l *Cache[K, V]) Snapshot(w io.Writer) error {
+	ets := make([]*entry[K, V], 0, l.queue.Len())
+	for _, e := range l.queue.entries {
+		if !l.opts.Expired(e.expireAt) {
+			ets = append(ets, e)
+		}
+	}
+	sort.Slice(ets, func(i, j int) bool {
+		return l.queue.less(ets[i], ets[j])
+	})
+
+	entries := make([]cache.SnapshotEntry, len(ets))
+	for i, e := range ets {
+		entries[i] = cache.SnapshotEntry{Key: e.key, Value: e.value, ExpireAt: e.expireAt, Weight: e.weight}
+	}
+	return cache.WriteSnapshot(w, l.opts.Codec, entries)
+}
+
+// Restore 写入快照中的记录，并恢复保存时的访问频率
+func (l *Cache[K, V]) Restore(r io.Reader) error {
+	entries, err := cache.ReadSnapshot(r, l.opts.Codec)
+	if err != nil {
+		return err
 	}
+	for _, se := range entries {
+		key, value, err := cache.Unpack[K, V](se)
+		if err != nil {
+			return err
+		}
+		if l.opts.Expired(se.ExpireAt) {
+			continue
+		}
+		e := l.set(key, value, se.ExpireAt)
+		l.queue.update(e, e.value, se.Weight)
+	}
+	return nil
+}
 
-	et := x.(*entry)
+// UsedBytes 实现 cache.MemoryUsage
+func (l *Cache[K, V]) UsedBytes() int {
+	return l.usedBytes
+}
+
+// Len 返回当前 cache 中的记录数，包括已过期但还未删除的记录
+func (l *Cache[K, V]) Len() int {
+	return l.queue.Len()
+}
 
+func (l *Cache[K, V]) removeElement(et *entry[K, V], reason cache.EvictReason) {
 	delete(l.cache, et.key)
 
-	l.usedBytes -= et.Len()
+	l.usedBytes -= et.size
 
 	if l.onEvicted != nil {
-		l.onEvicted(et.key, et.value)
+		l.onEvicted(et.key, et.value, reason)
 	}
 }
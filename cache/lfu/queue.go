@@ -1,58 +1,78 @@
 package lfu
 
 import (
-	"cache"
 	"container/heap"
+	"time"
+	"unsafe"
 )
 
-type entry struct {
-	key    string
-	value  interface{}
+type entry[K comparable, V any] struct {
+	key    K
+	value  V
 	weight int
 	index  int
+	// 写入的顺序
+	seq uint64
+	// 过期时间，零值表示永不过期
+	expireAt time.Time
+	// 写入时计算的 entry 占用的字节数，包括 key 和 entryOverhead
+	size int
 }
 
-func (e *entry) Len() int {
-	return cache.CalcLen(e.value)
+// entryOverhead 每个 entry 除了 key 和值以外的开销：entry 结构体和堆中的一个指针
+func entryOverhead[K comparable, V any]() int {
+	return int(unsafe.Sizeof(entry[K, V]{})) + int(unsafe.Sizeof(&entry[K, V]{}))
 }
 
-type queue []*entry
-
-func (q queue) Len() int {
-	return len(q)
+type queue[K comparable, V any] struct {
+	entries []*entry[K, V]
+	// 访问频率相同时比较 key，为 nil 时比较写入的顺序
+	keyLess func(a, b K) bool
 }
 
-func (q queue) Less(i, j int) bool {
-	if q[i].weight == q[j].weight {
-		return q[i].key < q[j].key
+// less 判断 a 是否应该比 b 先被淘汰
+func (q *queue[K, V]) less(a, b *entry[K, V]) bool {
+	if a.weight != b.weight {
+		return a.weight < b.weight
+	}
+	if q.keyLess != nil {
+		return q.keyLess(a.key, b.key)
 	}
-	return q[i].weight < q[j].weight
+	return a.seq < b.seq
+}
+
+func (q *queue[K, V]) Len() int {
+	return len(q.entries)
+}
+
+func (q *queue[K, V]) Less(i, j int) bool {
+	return q.less(q.entries[i], q.entries[j])
 }
 
-func (q queue) Swap(i, j int) {
-	q[i], q[j] = q[j], q[i]
-	q[i].index = i
-	q[j].index = j
+func (q *queue[K, V]) Swap(i, j int) {
+	q.entries[i], q.entries[j] = q.entries[j], q.entries[i]
+	q.entries[i].index = i
+	q.entries[j].index = j
 }
 
-func (q *queue) Push(x interface{}) {
-	et := x.(*entry)
-	et.index = len(*q)
-	*q = append(*q, et)
+func (q *queue[K, V]) Push(x interface{}) {
+	et := x.(*entry[K, V])
+	et.index = len(q.entries)
+	q.entries = append(q.entries, et)
 }
 
-func (q *queue) Pop() interface{} {
-	old := *q
+func (q *queue[K, V]) Pop() interface{} {
+	old := q.entries
 	n := len(old)
 	et := old[n-1]
 	old[n-1] = nil // avoid memory leak
 	et.index = -1  // for safety
-	*q = old[0 : n-1]
+	q.entries = old[0 : n-1]
 	return et
 }
 
 // update modifies the weight and value of an entry in the queue.
-func (q *queue) update(et *entry, value interface{}, weight int) {
+func (q *queue[K, V]) update(et *entry[K, V], value V, weight int) {
 	et.value = value
 	et.weight = weight
 	heap.Fix(q, et.index)
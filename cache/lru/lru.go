@@ -3,101 +3,198 @@ package lru
 import (
 	"cache"
 	"container/list"
+	"io"
+	"time"
+	"unsafe"
 )
 
-// lru 是一个 LRU cache。它不是并发安全的。
-type lru struct {
+// Cache 是一个 LRU cache。它不是并发安全的。
+type Cache[K comparable, V any] struct {
 	// 缓存最大的容量，单位字节；
 	// groupcache 使用的是最大存放 entry 个数
 	maxBytes int
+	// 计算值占用的字节数
+	sizer cache.Sizer[V]
 	// 当一个 entry 从缓存中移除是调用该回调函数，默认为 nil
 	// groupcache 中的 key 是任意的可比较类型；value 是 interface{}
-	onEvicted func(string, interface{})
+	onEvicted cache.TypedOnEvicted[K, V]
 
-	// 已使用的字节数，只包括值，key 不算
+	// 已使用的字节数，包括值、key 以及 entry 结构体等开销
 	usedBytes int
+	// 每个 entry 除了 key 和值以外的开销，见 entryOverhead
+	overhead int
+
+	opts cache.Options
 
 	ll    *list.List
-	cache map[string]*list.Element
+	cache map[K]*list.Element
 }
 
-type entry struct {
-	key   string
-	value interface{}
+type entry[K comparable, V any] struct {
+	key   K
+	value V
+	// 过期时间，零值表示永不过期
+	expireAt time.Time
+	// 写入时计算的 entry 占用的字节数，包括 key 和 entryOverhead
+	size int
 }
 
-func (e *entry) Len() int {
-	return cache.CalcLen(e.value)
+// entryOverhead 每个 entry 除了 key 和值以外的开销：entry 结构体和链表节点
+func entryOverhead[K comparable, V any]() int {
+	return int(unsafe.Sizeof(entry[K, V]{})) + cache.ListElementSize
 }
 
 // New 创建一个新的 Cache，如果 maxBytes 是 0，表示没有容量限制
-func New(maxBytes int, onEvicted func(string, interface{})) cache.Cache {
-	return &lru{
+func New(maxBytes int, onEvicted cache.OnEvicted, opts ...cache.Option) cache.Cache {
+	return cache.Untyped(NewCache[string, interface{}](maxBytes, nil, cache.TypedOnEvicted[string, interface{}](onEvicted), opts...))
+}
+
+// NewCache 创建一个新的泛型 Cache，如果 maxBytes 是 0，表示没有容量限制；sizer 计算值占用的字节数，为 nil 时使用 cache.CalcLen，key 和 entry 本身的开销另外计算
+func NewCache[K comparable, V any](maxBytes int, sizer cache.Sizer[V], onEvicted cache.TypedOnEvicted[K, V], opts ...cache.Option) *Cache[K, V] {
+	o := cache.NewOptions(opts...)
+	return &Cache[K, V]{
 		maxBytes:  maxBytes,
-		onEvicted: onEvicted,
+		sizer:     cache.SizerOrDefault(sizer),
+		overhead:  entryOverhead[K, V](),
+		onEvicted: cache.ObserveEvicted(o.Metrics, onEvicted),
+		opts:      o,
 		ll:        list.New(),
-		cache:     make(map[string]*list.Element),
+		cache:     make(map[K]*list.Element),
 	}
 }
 
-// Set 往 Cache 尾部增加一个元素（如果已经存在，则放入尾部，并更新值）
-func (l *lru) Set(key string, value interface{}) {
+// Set 往 Cache 尾部增加一个元素（如果已经存在，则放入尾部，并更新值），过期时间为默认的 TTL
+func (l *Cache[K, V]) Set(key K, value V) {
+	l.SetWithTTL(key, value, l.opts.DefaultTTL)
+}
+
+// SetWithTTL 同 Set，ttl 小于等于 0 表示永不过期
+func (l *Cache[K, V]) SetWithTTL(key K, value V, ttl time.Duration) {
+	l.set(key, value, l.opts.ExpireAt(ttl))
+}
+
+func (l *Cache[K, V]) set(key K, value V, expireAt time.Time) {
+	size := l.sizer(value) + cache.KeySize(key) + l.overhead
 	if e, ok := l.cache[key]; ok {
 		l.ll.MoveToBack(e)
-		et := e.Value.(*entry)
-		l.usedBytes = l.usedBytes - cache.CalcLen(et.value) + cache.CalcLen(value)
+		et := e.Value.(*entry[K, V])
+		l.usedBytes = l.usedBytes - et.size + size
 		et.value = value
+		et.expireAt = expireAt
+		et.size = size
 		return
 	}
 
-	et := &entry{key, value}
+	et := &entry[K, V]{key, value, expireAt, size}
 	e := l.ll.PushBack(et)
 	l.cache[key] = e
 
-	l.usedBytes += et.Len()
+	l.usedBytes += size
 	if l.maxBytes > 0 && l.usedBytes > l.maxBytes {
-		l.DelOldest()
+		l.removeElement(l.ll.Front(), cache.EvictCapacity)
 	}
 }
 
-// Get 从 cache 中获取 key 对应的值，nil 表示 key 不存在
-func (l *lru) Get(key string) interface{} {
+// Get 从 cache 中获取 key 对应的值，ok 为 false 表示 key 不存在或已过期
+func (l *Cache[K, V]) Get(key K) (value V, ok bool) {
 	if e, ok := l.cache[key]; ok {
+		et := e.Value.(*entry[K, V])
+		if l.opts.Expired(et.expireAt) {
+			l.removeElement(e, cache.EvictExpired)
+			return value, false
+		}
+
 		l.ll.MoveToBack(e)
-		return e.Value.(*entry).value
+		return et.value, true
 	}
 
-	return nil
+	return value, false
+}
+
+// Contains 判断 key 是否存在且未过期，不会调整淘汰顺序
+func (l *Cache[K, V]) Contains(key K) bool {
+	e, ok := l.cache[key]
+	return ok && !l.opts.Expired(e.Value.(*entry[K, V]).expireAt)
 }
 
 // Del 从 cache 中删除 key 对应的元素
-func (l *lru) Del(key string) {
+func (l *Cache[K, V]) Del(key K) {
 	if e, ok := l.cache[key]; ok {
-		l.removeElement(e)
+		l.removeElement(e, cache.EvictDeleted)
 	}
 }
 
 // DelOldest 从 cache 中删除最旧的记录
-func (l *lru) DelOldest() {
-	l.removeElement(l.ll.Front())
+func (l *Cache[K, V]) DelOldest() {
+	l.removeElement(l.ll.Front(), cache.EvictCapacity)
+}
+
+// DeleteExpired 删除所有已过期的记录，返回删除的个数
+func (l *Cache[K, V]) DeleteExpired() int {
+	n := 0
+	for e := l.ll.Front(); e != nil; {
+		next := e.Next()
+		if l.opts.Expired(e.Value.(*entry[K, V]).expireAt) {
+			l.removeElement(e, cache.EvictExpired)
+			n++
+		}
+		e = next
+	}
+
+	return n
+}
+
+// Snapshot 按从旧到新的顺序保存所有未过期的记录
+func (l *Cache[K, V]) Snapshot(w io.Writer) error {
+	entries := make([]cache.SnapshotEntry, 0, l.ll.Len())
+	for e := l.ll.Front(); e != nil; e = e.Next() {
+		et := e.Value.(*entry[K, V])
+		if !l.opts.Expired(et.expireAt) {
+			entries = append(entries, cache.SnapshotEntry{Key: et.key, Value: et.value, ExpireAt: et.expireAt})
+		}
+	}
+	return cache.WriteSnapshot(w, l.opts.Codec, entries)
+}
+
+// Restore 按快照中的顺序写入记录，恢复后淘汰顺序和保存时一致
+func (l *Cache[K, V]) Restore(r io.Reader) error {
+	entries, err := cache.ReadSnapshot(r, l.opts.Codec)
+	if err != nil {
+		return err
+	}
+	for _, se := range entries {
+		key, value, err := cache.Unpack[K, V](se)
+		if err != nil {
+			return err
+		}
+		if !l.opts.Expired(se.ExpireAt) {
+			l.set(key, value, se.ExpireAt)
+		}
+	}
+	return nil
+}
+
+// UsedBytes 实现 cache.MemoryUsage
+func (l *Cache[K, V]) UsedBytes() int {
+	return l.usedBytes
 }
 
-// Len 返回当前 cache 中的记录数
-func (l *lru) Len() int {
+// Len 返回当前 cache 中的记录数，包括已过期但还未删除的记录
+func (l *Cache[K, V]) Len() int {
 	return l.ll.Len()
 }
 
-func (l *lru) removeElement(e *list.Element) {
+func (l *Cache[K, V]) removeElement(e *list.Element, reason cache.EvictReason) {
 	if e == nil {
 		return
 	}
 
 	l.ll.Remove(e)
-	et := e.Value.(*entry)
-	l.usedBytes -= et.Len()
+	et := e.Value.(*entry[K, V])
+	l.usedBytes -= et.size
 	delete(l.cache, et.key)
 
 	if l.onEvicted != nil {
-		l.onEvicted(et.key, et.value)
+		l.onEvicted(et.key, et.value, reason)
 	}
 }
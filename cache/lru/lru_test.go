@@ -1,15 +1,19 @@
 package lru
 
 import (
+	"cache"
 	"testing"
 
 	"github.com/matryer/is"
 )
 
+// entryBytes 值为 int32、key 长度为 2 的 entry 占用的字节数
+var entryBytes = cache.CalcLen(int32(0)) + cache.KeySize("k1") + entryOverhead[string, interface{}]()
+
 func TestSet(t *testing.T) {
 	is := is.New(t)
 
-	cache := New(8, nil)
+	cache := New(2*entryBytes, nil)
 	cache.DelOldest()
 	cache.Set("k1", int32(1))
 	cache.Set("k2", int32(2))
@@ -31,10 +35,10 @@ func TestOnEvicted(t *testing.T) {
 	is := is.New(t)
 
 	keys := make([]string, 0, 8)
-	onEvicted := func(key string, value interface{}) {
+	onEvicted := func(key string, value interface{}, reason cache.EvictReason) {
 		keys = append(keys, key)
 	}
-	cache := New(8, onEvicted)
+	cache := New(2*entryBytes, onEvicted)
 
 	cache.Set("k1", int32(1))
 	cache.Set("k2", int32(2))
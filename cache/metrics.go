@@ -0,0 +1,223 @@
+package cache
+
+import (
+	"errors"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// MemoryUsage 可以报告已使用字节数的缓存，lru、lfu、fifo、arc、twoq、tinylfu 和 fast 都实现了该接口
+type MemoryUsage interface {
+	// UsedBytes 返回已使用的字节数，计算方式和 maxBytes 相同；arc、twoq 还包括只记录 key 的 ghost 链表
+	UsedBytes() int
+}
+
+// DefaultLatencyBuckets 加载耗时直方图默认的桶，单位秒
+var DefaultLatencyBuckets = []float64{.0005, .001, .0025, .005, .01, .025, .05, .1, .25, .5, 1, 2.5, 5, 10}
+
+// Metrics 记录一个缓存的指标，所有方法都是并发安全的，nil 的 *Metrics 不记录任何数据。
+// 通过 WithMetrics 同时传给淘汰算法和 TourCache：前者记录移除的 entry，后者记录命中、写入、加载以及当前的 entry 个数和字节数。
+// 一个 Metrics 只能用于一个缓存
+type Metrics struct {
+	// 原子操作的字段放在最前面，保证在 32 位平台上 64 位对齐
+	hits, misses, sets uint64
+	// 按 EvictReason 统计，下标为 EvictReason
+	evictions [EvictDeleted + 1]uint64
+	// 加载结果：成功、ErrNotFound、其他错误
+	loads, loadNotFound, loadErrors uint64
+	loadLatency                     *Histogram
+
+	name, policy string
+
+	mu sync.RWMutex
+	// 获取当前的 entry 个数和字节数，由 TourCache 设置；bytes 为 nil 表示缓存没有实现 MemoryUsage
+	entries, bytes func() int
+}
+
+// NewMetrics 创建一个 Metrics，name 和 policy 作为导出时的标签，用于区分不同的缓存
+func NewMetrics(name, policy string) *Metrics {
+	return &Metrics{
+		name:        name,
+		policy:      policy,
+		loadLatency: NewHistogram(DefaultLatencyBuckets),
+	}
+}
+
+func (m *Metrics) Name() string {
+	return m.name
+}
+
+func (m *Metrics) Policy() string {
+	return m.policy
+}
+
+func (m *Metrics) hit() {
+	if m != nil {
+		atomic.AddUint64(&m.hits, 1)
+	}
+}
+
+func (m *Metrics) miss() {
+	if m != nil {
+		atomic.AddUint64(&m.misses, 1)
+	}
+}
+
+func (m *Metrics) set() {
+	if m != nil {
+		atomic.AddUint64(&m.sets, 1)
+	}
+}
+
+func (m *Metrics) evicted(reason EvictReason) {
+	if m != nil && reason > 0 && int(reason) < len(m.evictions) {
+		atomic.AddUint64(&m.evictions[reason], 1)
+	}
+}
+
+// loaded 记录一次加载的耗时和结果
+func (m *Metrics) loaded(d time.Duration, err error) {
+	if m == nil {
+		return
+	}
+	switch {
+	case err == nil:
+		atomic.AddUint64(&m.loads, 1)
+	case errors.Is(err, ErrNotFound):
+		atomic.AddUint64(&m.loadNotFound, 1)
+	default:
+		atomic.AddUint64(&m.loadErrors, 1)
+	}
+	m.loadLatency.Observe(d.Seconds())
+}
+
+// observe 设置获取当前 entry 个数和字节数的函数
+func (m *Metrics) observe(entries, bytes func() int) {
+	if m == nil {
+		return
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.entries, m.bytes = entries, bytes
+}
+
+// ObserveEvicted 返回一个记录移除原因后再调用 onEvicted 的回调函数，onEvicted 可以为 nil；m 为 nil 时直接返回 onEvicted。
+// 淘汰算法在创建时用它包装 Options.Metrics
+func ObserveEvicted[F ~func(K, V, EvictReason), K comparable, V any](m *Metrics, onEvicted F) F {
+	if m == nil {
+		return onEvicted
+	}
+	return func(key K, value V, reason EvictReason) {
+		m.evicted(reason)
+		if onEvicted != nil {
+			onEvicted(key, value, reason)
+		}
+	}
+}
+
+// MetricsSnapshot 某一时刻的指标，同时也是 expvar 导出的 JSON 格式
+type MetricsSnapshot struct {
+	Name   string `json:"name"`
+	Policy string `json:"policy"`
+
+	Hits   uint64 `json:"hits"`
+	Misses uint64 `json:"misses"`
+	Sets   uint64 `json:"sets"`
+	// key 为 EvictReason.String()
+	Evictions map[string]uint64 `json:"evictions"`
+
+	Loads        uint64            `json:"loads"`
+	LoadNotFound uint64            `json:"load_not_found"`
+	LoadErrors   uint64            `json:"load_errors"`
+	LoadLatency  HistogramSnapshot `json:"load_latency_seconds"`
+
+	Entries int `json:"entries"`
+	// 缓存没有实现 MemoryUsage 时为 -1
+	Bytes int `json:"bytes"`
+}
+
+// Snapshot 返回当前的指标
+func (m *Metrics) Snapshot() MetricsSnapshot {
+	s := MetricsSnapshot{
+		Name:         m.name,
+		Policy:       m.policy,
+		Hits:         atomic.LoadUint64(&m.hits),
+		Misses:       atomic.LoadUint64(&m.misses),
+		Sets:         atomic.LoadUint64(&m.sets),
+		Evictions:    make(map[string]uint64, len(m.evictions)-1),
+		Loads:        atomic.LoadUint64(&m.loads),
+		LoadNotFound: atomic.LoadUint64(&m.loadNotFound),
+		LoadErrors:   atomic.LoadUint64(&m.loadErrors),
+		LoadLatency:  m.loadLatency.Snapshot(),
+		Bytes:        -1,
+	}
+	for reason := EvictExpired; reason <= EvictDeleted; reason++ {
+		s.Evictions[reason.String()] = atomic.LoadUint64(&m.evictions[reason])
+	}
+
+	m.mu.RLock()
+	entries, bytes := m.entries, m.bytes
+	m.mu.RUnlock()
+	if entries != nil {
+		s.Entries = entries()
+	}
+	if bytes != nil {
+		s.Bytes = bytes()
+	}
+	return s
+}
+
+// Histogram 累积直方图，并发安全
+type Histogram struct {
+	count uint64
+	// 所有观测值之和，单位为 1e-9，用整数保存以便原子累加
+	sumNanos int64
+	// 每个桶的上界，升序
+	bounds []float64
+	// counts[i] 为落在 (bounds[i-1], bounds[i]] 中的个数，最后一个为大于所有上界的个数
+	counts []uint64
+}
+
+// NewHistogram 创建一个 Histogram，bounds 为每个桶的上界，必须是升序
+func NewHistogram(bounds []float64) *Histogram {
+	return &Histogram{
+		bounds: bounds,
+		counts: make([]uint64, len(bounds)+1),
+	}
+}
+
+// Observe 记录一个观测值
+func (h *Histogram) Observe(v float64) {
+	i := 0
+	for i < len(h.bounds) && v > h.bounds[i] {
+		i++
+	}
+	atomic.AddUint64(&h.counts[i], 1)
+	atomic.AddUint64(&h.count, 1)
+	atomic.AddInt64(&h.sumNanos, int64(v*1e9))
+}
+
+// HistogramSnapshot 某一时刻的直方图，Counts 是累积的，Counts[i] 为小于等于 Bounds[i] 的个数
+type HistogramSnapshot struct {
+	Bounds []float64 `json:"bounds"`
+	Counts []uint64  `json:"counts"`
+	Count  uint64    `json:"count"`
+	Sum    float64   `json:"sum"`
+}
+
+// Snapshot 返回当前的直方图，并发写入时各个值之间可能有细微的不一致
+func (h *Histogram) Snapshot() HistogramSnapshot {
+	s := HistogramSnapshot{
+		Bounds: h.bounds,
+		Counts: make([]uint64, len(h.bounds)),
+		Count:  atomic.LoadUint64(&h.count),
+		Sum:    float64(atomic.LoadInt64(&h.sumNanos)) / 1e9,
+	}
+	var cumulative uint64
+	for i := range h.bounds {
+		cumulative += atomic.LoadUint64(&h.counts[i])
+		s.Counts[i] = cumulative
+	}
+	return s
+}
@@ -0,0 +1,147 @@
+package cache
+
+import (
+	"bufio"
+	"expvar"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// Registry 管理一组 Metrics，通过 expvar 或 Prometheus 文本格式导出
+type Registry struct {
+	mu      sync.RWMutex
+	metrics map[string]*Metrics
+}
+
+// DefaultRegistry 默认的 Registry
+var DefaultRegistry = NewRegistry()
+
+func NewRegistry() *Registry {
+	return &Registry{metrics: make(map[string]*Metrics)}
+}
+
+// Register 注册 Metrics，同名的会被替换
+func (r *Registry) Register(ms ...*Metrics) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, m := range ms {
+		r.metrics[m.name] = m
+	}
+}
+
+// Unregister 删除名为 name 的 Metrics
+func (r *Registry) Unregister(name string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	delete(r.metrics, name)
+}
+
+// Snapshot 返回所有 Metrics 当前的指标，按名称排序
+func (r *Registry) Snapshot() []MetricsSnapshot {
+	r.mu.RLock()
+	ms := make([]*Metrics, 0, len(r.metrics))
+	for _, m := range r.metrics {
+		ms = append(ms, m)
+	}
+	r.mu.RUnlock()
+
+	sort.Slice(ms, func(i, j int) bool {
+		return ms[i].name < ms[j].name
+	})
+	snapshots := make([]MetricsSnapshot, len(ms))
+	for i, m := range ms {
+		snapshots[i] = m.Snapshot()
+	}
+	return snapshots
+}
+
+// Var 返回导出所有指标的 expvar.Var，JSON 格式为 {"<name>": MetricsSnapshot}
+func (r *Registry) Var() expvar.Var {
+	return expvar.Func(func() interface{} {
+		snapshots := r.Snapshot()
+		vars := make(map[string]MetricsSnapshot, len(snapshots))
+		for _, s := range snapshots {
+			vars[s.Name] = s
+		}
+		return vars
+	})
+}
+
+// PublishExpvar 将所有指标发布到 expvar 的 name 下，可以通过 /debug/vars 查看；
+// 和 expvar.Publish 一样，name 重复时会 panic
+func (r *Registry) PublishExpvar(name string) {
+	expvar.Publish(name, r.Var())
+}
+
+// WritePrometheus 按 Prometheus 的文本格式写入所有指标，标签 cache 和 policy 分别为 Metrics 的名称和淘汰算法
+func (r *Registry) WritePrometheus(w io.Writer) error {
+	snapshots := r.Snapshot()
+	bw := bufio.NewWriter(w)
+
+	family := func(name, typ, help string, write func(s MetricsSnapshot, labels string)) {
+		fmt.Fprintf(bw, "# HELP %s %s\n# TYPE %s %s\n", name, help, name, typ)
+		for _, s := range snapshots {
+			write(s, fmt.Sprintf("cache=%s,policy=%s", quoteLabel(s.Name), quoteLabel(s.Policy)))
+		}
+	}
+	counter := func(name, help string, value func(s MetricsSnapshot) uint64) {
+		family(name, "counter", help, func(s MetricsSnapshot, labels string) {
+			fmt.Fprintf(bw, "%s{%s} %d\n", name, labels, value(s))
+		})
+	}
+
+	counter("tourcache_hits_total", "Number of cache hits.", func(s MetricsSnapshot) uint64 { return s.Hits })
+	counter("tourcache_misses_total", "Number of cache misses.", func(s MetricsSnapshot) uint64 { return s.Misses })
+	counter("tourcache_sets_total", "Number of entries written.", func(s MetricsSnapshot) uint64 { return s.Sets })
+	family("tourcache_evictions_total", "counter", "Number of entries removed from the cache, by reason.", func(s MetricsSnapshot, labels string) {
+		for reason := EvictExpired; reason <= EvictDeleted; reason++ {
+			fmt.Fprintf(bw, "tourcache_evictions_total{%s,reason=%q} %d\n", labels, reason.String(), s.Evictions[reason.String()])
+		}
+	})
+	family("tourcache_loads_total", "counter", "Number of loader calls, by result.", func(s MetricsSnapshot, labels string) {
+		fmt.Fprintf(bw, "tourcache_loads_total{%s,result=\"ok\"} %d\n", labels, s.Loads)
+		fmt.Fprintf(bw, "tourcache_loads_total{%s,result=\"not_found\"} %d\n", labels, s.LoadNotFound)
+		fmt.Fprintf(bw, "tourcache_loads_total{%s,result=\"error\"} %d\n", labels, s.LoadErrors)
+	})
+	family("tourcache_load_duration_seconds", "histogram", "Loader latency in seconds.", func(s MetricsSnapshot, labels string) {
+		h := s.LoadLatency
+		for i, bound := range h.Bounds {
+			fmt.Fprintf(bw, "tourcache_load_duration_seconds_bucket{%s,le=%q} %d\n", labels, formatFloat(bound), h.Counts[i])
+		}
+		fmt.Fprintf(bw, "tourcache_load_duration_seconds_bucket{%s,le=\"+Inf\"} %d\n", labels, h.Count)
+		fmt.Fprintf(bw, "tourcache_load_duration_seconds_sum{%s} %s\n", labels, formatFloat(h.Sum))
+		fmt.Fprintf(bw, "tourcache_load_duration_seconds_count{%s} %d\n", labels, h.Count)
+	})
+	family("tourcache_entries", "gauge", "Number of entries in the cache, including expired ones not yet removed.", func(s MetricsSnapshot, labels string) {
+		fmt.Fprintf(bw, "tourcache_entries{%s} %d\n", labels, s.Entries)
+	})
+	family("tourcache_bytes", "gauge", "Bytes used by the cache, as counted against its maxBytes.", func(s MetricsSnapshot, labels string) {
+		// 没有实现 MemoryUsage 的缓存不导出
+		if s.Bytes >= 0 {
+			fmt.Fprintf(bw, "tourcache_bytes{%s} %d\n", labels, s.Bytes)
+		}
+	})
+
+	return bw.Flush()
+}
+
+// ServeHTTP 以 Prometheus 的文本格式返回所有指标，可以直接注册为 /metrics
+func (r *Registry) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+	r.WritePrometheus(w)
+}
+
+// quoteLabel 按 Prometheus 文本格式转义标签值：反斜杠、双引号和换行
+func quoteLabel(v string) string {
+	v = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`).Replace(v)
+	return `"` + v + `"`
+}
+
+func formatFloat(f float64) string {
+	return strconv.FormatFloat(f, 'g', -1, 64)
+}
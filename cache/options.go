@@ -0,0 +1,152 @@
+package cache
+
+import "time"
+
+// EvictReason 表示 entry 从缓存中移除的原因
+type EvictReason int
+
+const (
+	// EvictExpired entry 已过期
+	EvictExpired EvictReason = iota + 1
+	// EvictCapacity 超出容量被淘汰，包括调用 DelOldest
+	EvictCapacity
+	// EvictDeleted 调用 Del 主动删除
+	EvictDeleted
+)
+
+func (r EvictReason) String() string {
+	switch r {
+	case EvictExpired:
+		return "expired"
+	case EvictCapacity:
+		return "capacity"
+	case EvictDeleted:
+		return "deleted"
+	}
+	return "unknown"
+}
+
+// OnEvicted 当一个 entry 从缓存中移除时调用的回调函数，更新已存在 key 的值时不会调用
+type OnEvicted func(key string, value interface{}, reason EvictReason)
+
+// Options 创建缓存时的可选配置
+type Options struct {
+	// 通过 Set 写入的 entry 的过期时间，0 表示永不过期
+	DefaultTTL time.Duration
+	// 后台清理过期 entry 的间隔，0 表示不启动后台清理，过期的 entry 只在访问时移除；
+	// lru、lfu、fifo 不是并发安全的，需要通过 TourCache 启动后台清理
+	CleanupInterval time.Duration
+	// 加载结果为 ErrNotFound 时缓存的时间，0 表示不缓存，仅对 TourCache 生效
+	NegativeTTL time.Duration
+	// 最多缓存的不存在的 key 的个数，超出时随机移除一个，默认为 DefaultMaxNotFound，仅对 TourCache 生效
+	MaxNotFound int
+	// 预计最多存放的 entry 个数，仅对 tinylfu 生效，用于确定访问频率 sketch 的大小；0 表示根据容量估算
+	ExpectedEntries int
+	// 获取当前时间，默认为 time.Now，主要用于测试
+	Now func() time.Time
+	// 快照中值的序列化方式，默认为 GobCodec
+	Codec Codec
+	// 快照文件的路径，仅对 TourCache 生效：创建时从该文件恢复，Close 时写入；为空表示不使用快照
+	SnapshotPath string
+	// 后台定期写入快照的间隔，0 表示只在 Close 时写入，仅对 TourCache 生效
+	SnapshotInterval time.Duration
+	// 记录缓存的指标，为 nil 表示不记录；淘汰算法记录移除的 entry，TourCache 记录其他指标
+	Metrics *Metrics
+}
+
+type Option func(*Options)
+
+// WithDefaultTTL 设置通过 Set 写入的 entry 的过期时间
+func WithDefaultTTL(ttl time.Duration) Option {
+	return func(o *Options) {
+		o.DefaultTTL = ttl
+	}
+}
+
+// WithCleanupInterval 设置后台清理过期 entry 的间隔
+func WithCleanupInterval(interval time.Duration) Option {
+	return func(o *Options) {
+		o.CleanupInterval = interval
+	}
+}
+
+// WithNegativeTTL 设置 TourCache 缓存加载结果为 ErrNotFound 的时间，应设置得比较短
+func WithNegativeTTL(ttl time.Duration) Option {
+	return func(o *Options) {
+		o.NegativeTTL = ttl
+	}
+}
+
+// WithMaxNotFound 设置 TourCache 最多缓存的不存在的 key 的个数
+func WithMaxNotFound(n int) Option {
+	return func(o *Options) {
+		o.MaxNotFound = n
+	}
+}
+
+// WithExpectedEntries 设置预计最多存放的 entry 个数，tinylfu 据此确定访问频率 sketch 的大小
+func WithExpectedEntries(n int) Option {
+	return func(o *Options) {
+		o.ExpectedEntries = n
+	}
+}
+
+// WithClock 设置获取当前时间的函数
+func WithClock(now func() time.Time) Option {
+	return func(o *Options) {
+		o.Now = now
+	}
+}
+
+// WithCodec 设置快照中值的序列化方式
+func WithCodec(codec Codec) Option {
+	return func(o *Options) {
+		o.Codec = codec
+	}
+}
+
+// WithSnapshot 设置 TourCache 的快照文件和后台定期写入快照的间隔
+func WithSnapshot(path string, interval time.Duration) Option {
+	return func(o *Options) {
+		o.SnapshotPath = path
+		o.SnapshotInterval = interval
+	}
+}
+
+// WithMetrics 设置记录缓存指标的 Metrics，需要同时传给淘汰算法和 TourCache
+func WithMetrics(m *Metrics) Option {
+	return func(o *Options) {
+		o.Metrics = m
+	}
+}
+
+// NewOptions 应用 opts 并返回最终的配置
+func NewOptions(opts ...Option) Options {
+	o := Options{Now: time.Now}
+	for _, opt := range opts {
+		opt(&o)
+	}
+	if o.Now == nil {
+		o.Now = time.Now
+	}
+	if o.Codec == nil {
+		o.Codec = GobCodec{}
+	}
+	if o.MaxNotFound <= 0 {
+		o.MaxNotFound = DefaultMaxNotFound
+	}
+	return o
+}
+
+// ExpireAt 根据 ttl 计算过期时间，ttl 小于等于 0 时返回零值，表示永不过期
+func (o Options) ExpireAt(ttl time.Duration) time.Time {
+	if ttl <= 0 {
+		return time.Time{}
+	}
+	return o.Now().Add(ttl)
+}
+
+// Expired 判断过期时间为 expireAt 的 entry 是否已过期
+func (o Options) Expired(expireAt time.Time) bool {
+	return !expireAt.IsZero() && !o.Now().Before(expireAt)
+}
@@ -0,0 +1,65 @@
+package peer
+
+import (
+	"cache"
+	"cache/singleflight"
+	"context"
+	"errors"
+	"log"
+)
+
+// Group 是一个带命名空间的分布式缓存，key 属于当前节点时通过 loader 加载并缓存，
+// 否则从所属节点获取；所属节点不可用时回退到本地加载
+type Group struct {
+	name      string
+	mainCache *cache.TourCache
+	peers     PeerPicker
+	// 合并对远程节点的并发获取
+	fetchGroup singleflight.Group
+}
+
+// newGroup 创建 Group，getter 同时实现了 cache.Loader 时使用 Load 加载，opts 传递给 cache.NewTourCache
+func newGroup(name string, c cache.Cache, getter cache.Getter, peers PeerPicker, opts ...cache.Option) *Group {
+	return &Group{
+		name:      name,
+		mainCache: cache.NewTourCache(getter, c, opts...),
+		peers:     peers,
+	}
+}
+
+func (g *Group) Name() string {
+	return g.name
+}
+
+// Get 获取 key 对应的值，key 不存在时返回 cache.ErrNotFound
+func (g *Group) Get(ctx context.Context, key string) (interface{}, error) {
+	if g.peers != nil {
+		if peer, ok := g.peers.PickPeer(key); ok {
+			val, err := g.fetchGroup.DoContext(ctx, key, func() (interface{}, error) {
+				// 获取结果由多个调用者共享，不能因为其中一个取消而中断，超时由 PeerGetter 自己控制
+				return peer.Fetch(context.Background(), g.name, key)
+			})
+			if err == nil || errors.Is(err, cache.ErrNotFound) || ctx.Err() != nil {
+				return val, err
+			}
+			log.Printf("[TourCache] fetch %s/%s from peer err: %v, fallback to local", g.name, key, err)
+		}
+	}
+
+	return g.getLocally(ctx, key)
+}
+
+// getLocally 从本地缓存获取，未命中时通过 loader 加载
+func (g *Group) getLocally(ctx context.Context, key string) (interface{}, error) {
+	return g.mainCache.GetContext(ctx, key)
+}
+
+// Stat 返回本地缓存的命中统计
+func (g *Group) Stat() *cache.Stat {
+	return g.mainCache.Stat()
+}
+
+// Close 停止本地缓存的后台清理
+func (g *Group) Close() {
+	g.mainCache.Close()
+}
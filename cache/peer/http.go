@@ -0,0 +1,222 @@
+package peer
+
+import (
+	"bytes"
+	"cache"
+	"cache/consistenthash"
+	"context"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	defaultBasePath = "/_tourcache/"
+	defaultReplicas = 50
+	defaultTimeout  = 3 * time.Second
+)
+
+// HTTPPool 管理一组通过 HTTP 通信的节点，同时作为 http.Handler 为其他节点提供 /_tourcache/<group>/<key>
+type HTTPPool struct {
+	// 当前节点的地址，如 http://10.0.0.1:8000
+	self     string
+	basePath string
+	replicas int
+	hash     consistenthash.Hash
+	codec    cache.Codec
+	client   *http.Client
+
+	mu      sync.RWMutex
+	ring    *consistenthash.Map
+	getters map[string]*httpGetter
+	groups  map[string]*Group
+}
+
+type PoolOption func(*HTTPPool)
+
+// WithBasePath 设置节点间通信的路径前缀，默认为 /_tourcache/
+func WithBasePath(basePath string) PoolOption {
+	return func(p *HTTPPool) {
+		p.basePath = basePath
+	}
+}
+
+// WithReplicas 设置每个节点在一致性哈希环上的虚拟节点数，默认为 50
+func WithReplicas(replicas int) PoolOption {
+	return func(p *HTTPPool) {
+		p.replicas = replicas
+	}
+}
+
+// WithHash 设置一致性哈希使用的哈希函数，默认为 consistenthash.FNV64a
+func WithHash(hash consistenthash.Hash) PoolOption {
+	return func(p *HTTPPool) {
+		p.hash = hash
+	}
+}
+
+// WithCodec 设置值的序列化方式，默认为 cache.GobCodec
+func WithCodec(codec cache.Codec) PoolOption {
+	return func(p *HTTPPool) {
+		p.codec = codec
+	}
+}
+
+// WithClient 设置请求其他节点使用的 http.Client，默认超时时间为 3 秒
+func WithClient(client *http.Client) PoolOption {
+	return func(p *HTTPPool) {
+		p.client = client
+	}
+}
+
+// NewHTTPPool 创建 HTTPPool，self 为当前节点的地址，需要与 Set 中使用的地址一致
+func NewHTTPPool(self string, opts ...PoolOption) *HTTPPool {
+	p := &HTTPPool{
+		self:     self,
+		basePath: defaultBasePath,
+		replicas: defaultReplicas,
+		codec:    cache.GobCodec{},
+		client:   &http.Client{Timeout: defaultTimeout},
+		groups:   make(map[string]*Group),
+	}
+	for _, opt := range opts {
+		opt(p)
+	}
+	p.ring = consistenthash.New(p.replicas, p.hash)
+
+	return p
+}
+
+// Set 设置所有节点的地址（包括当前节点），会替换之前的节点
+func (p *HTTPPool) Set(peers ...string) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.ring = consistenthash.New(p.replicas, p.hash)
+	p.ring.Add(peers...)
+	p.getters = make(map[string]*httpGetter, len(peers))
+	for _, peer := range peers {
+		p.getters[peer] = &httpGetter{
+			baseURL: strings.TrimSuffix(peer, "/") + p.basePath,
+			codec:   p.codec,
+			client:  p.client,
+		}
+	}
+}
+
+// PickPeer 实现 PeerPicker
+func (p *HTTPPool) PickPeer(key string) (PeerGetter, bool) {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+
+	if peer := p.ring.Get(key); peer != "" && peer != p.self {
+		return p.getters[peer], true
+	}
+	return nil, false
+}
+
+// NewGroup 创建一个使用该 HTTPPool 的 Group，同名的 Group 会被替换
+func (p *HTTPPool) NewGroup(name string, c cache.Cache, getter cache.Getter, opts ...cache.Option) *Group {
+	g := newGroup(name, c, getter, p, opts...)
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.groups[name] = g
+	return g
+}
+
+// Group 返回名为 name 的 Group，不存在时返回 nil
+func (p *HTTPPool) Group(name string) *Group {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	return p.groups[name]
+}
+
+// ServeHTTP 处理 <basePath><group>/<key>，只从本地获取，不会再转发给其他节点
+func (p *HTTPPool) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	path := r.URL.EscapedPath()
+	if !strings.HasPrefix(path, p.basePath) {
+		http.Error(w, "unexpected path: "+path, http.StatusBadRequest)
+		return
+	}
+
+	parts := strings.SplitN(path[len(p.basePath):], "/", 2)
+	if len(parts) != 2 {
+		http.Error(w, "bad request", http.StatusBadRequest)
+		return
+	}
+	groupName, err := url.PathUnescape(parts[0])
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	key, err := url.PathUnescape(parts[1])
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	group := p.Group(groupName)
+	if group == nil {
+		// 不能返回 404，否则会被当作 key 不存在
+		http.Error(w, "no such group: "+groupName, http.StatusBadRequest)
+		return
+	}
+
+	val, err := group.getLocally(r.Context(), key)
+	if errors.Is(err, cache.ErrNotFound) {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	body, err := p.codec.Marshal(val)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/octet-stream")
+	w.Write(body)
+}
+
+// httpGetter 通过 HTTP 从其他节点获取值
+type httpGetter struct {
+	baseURL string
+	codec   cache.Codec
+	client  *http.Client
+}
+
+// Fetch 实现 PeerGetter
+func (h *httpGetter) Fetch(ctx context.Context, group, key string) (interface{}, error) {
+	u := h.baseURL + url.PathEscape(group) + "/" + url.PathEscape(key)
+	req, err := http.NewRequest(http.MethodGet, u, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := h.client.Do(req.WithContext(ctx))
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	body, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+	switch resp.StatusCode {
+	case http.StatusOK:
+		return h.codec.Unmarshal(body)
+	case http.StatusNotFound:
+		return nil, cache.ErrNotFound
+	}
+	return nil, fmt.Errorf("peer returned %s: %s", resp.Status, bytes.TrimSpace(body))
+}
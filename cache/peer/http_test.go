@@ -0,0 +1,143 @@
+package peer
+
+import (
+	"cache"
+	"cache/lru"
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/matryer/is"
+)
+
+// node 是测试用的一个节点
+type node struct {
+	pool   *HTTPPool
+	group  *Group
+	server *httptest.Server
+
+	mu    sync.Mutex
+	loads map[string]int
+}
+
+func (n *node) load(ctx context.Context, key string) (interface{}, error) {
+	n.mu.Lock()
+	n.loads[key]++
+	n.mu.Unlock()
+
+	if strings.HasPrefix(key, "missing") {
+		return nil, cache.ErrNotFound
+	}
+	return "val:" + key, nil
+}
+
+func (n *node) loadCount(key string) int {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	return n.loads[key]
+}
+
+// startNodes 启动 num 个节点，它们组成同一个集群
+func startNodes(t *testing.T, num int) []*node {
+	nodes := make([]*node, num)
+	addrs := make([]string, num)
+	for i := range nodes {
+		n := &node{loads: make(map[string]int)}
+		n.server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			n.pool.ServeHTTP(w, r)
+		}))
+		t.Cleanup(n.server.Close)
+		nodes[i] = n
+		addrs[i] = n.server.URL
+	}
+
+	for i, n := range nodes {
+		n.pool = NewHTTPPool(addrs[i])
+		n.pool.Set(addrs...)
+		n.group = n.pool.NewGroup("scores", lru.New(0, nil), cache.LoaderFunc(n.load))
+	}
+	return nodes
+}
+
+func TestGroupGet(t *testing.T) {
+	is := is.New(t)
+
+	nodes := startNodes(t, 3)
+	keys := make([]string, 50)
+	for i := range keys {
+		keys[i] = fmt.Sprintf("key-%d", i)
+	}
+
+	// 从任意节点获取，都由 key 所属的节点加载，并且只加载一次
+	for _, n := range nodes {
+		for _, key := range keys {
+			val, err := n.group.Get(context.Background(), key)
+			is.NoErr(err)
+			is.Equal(val, "val:"+key)
+		}
+	}
+
+	owned := make([]int, len(nodes))
+	for _, key := range keys {
+		total := 0
+		for i, n := range nodes {
+			if c := n.loadCount(key); c > 0 {
+				is.Equal(c, 1)
+				_, remote := n.pool.PickPeer(key)
+				is.True(!remote)
+				owned[i]++
+			}
+			total += n.loadCount(key)
+		}
+		is.Equal(total, 1)
+	}
+	for _, c := range owned {
+		is.True(c > 0)
+	}
+
+	_, err := nodes[0].group.Get(context.Background(), "missing-key")
+	is.True(errors.Is(err, cache.ErrNotFound))
+}
+
+func TestGroupPeerUnreachable(t *testing.T) {
+	is := is.New(t)
+
+	nodes := startNodes(t, 2)
+	var key string
+	for i := 0; ; i++ {
+		key = fmt.Sprintf("key-%d", i)
+		if _, remote := nodes[0].pool.PickPeer(key); remote {
+			break
+		}
+	}
+
+	nodes[1].server.Close()
+	val, err := nodes[0].group.Get(context.Background(), key)
+	is.NoErr(err)
+	is.Equal(val, "val:"+key)
+	is.Equal(nodes[0].loadCount(key), 1)
+	is.Equal(nodes[1].loadCount(key), 0)
+}
+
+func TestServeHTTP(t *testing.T) {
+	is := is.New(t)
+
+	nodes := startNodes(t, 1)
+	get := func(path string) int {
+		resp, err := http.Get(nodes[0].server.URL + path)
+		is.NoErr(err)
+		resp.Body.Close()
+		return resp.StatusCode
+	}
+
+	is.Equal(get("/_tourcache/scores/a%2Fb"), http.StatusOK)
+	is.Equal(nodes[0].loadCount("a/b"), 1)
+	is.Equal(get("/_tourcache/scores/missing"), http.StatusNotFound)
+	is.Equal(get("/_tourcache/unknown/a"), http.StatusBadRequest)
+	is.Equal(get("/_tourcache/scores"), http.StatusBadRequest)
+}
@@ -0,0 +1,15 @@
+// Package peer 实现了 groupcache 风格的分布式缓存：
+// 通过一致性哈希将 key 分配给各个节点，由所属节点负责加载和缓存，其他节点通过 HTTP 向其获取。
+package peer
+
+import "context"
+
+// PeerPicker 根据 key 选择所属的远程节点，key 属于当前节点时返回 false
+type PeerPicker interface {
+	PickPeer(key string) (PeerGetter, bool)
+}
+
+// PeerGetter 从远程节点获取 group 中 key 对应的值，key 不存在时返回 cache.ErrNotFound
+type PeerGetter interface {
+	Fetch(ctx context.Context, group, key string) (interface{}, error)
+}
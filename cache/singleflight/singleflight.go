@@ -0,0 +1,91 @@
+// Package singleflight 合并对同一个 key 的并发加载，参考 groupcache 的 singleflight，
+// 增加了 context 支持：等待者可以通过 context 提前返回，而加载本身不受影响。
+package singleflight
+
+import (
+	"context"
+	"fmt"
+	"runtime/debug"
+	"sync"
+)
+
+// PanicError fn 发生 panic 时返回给所有调用者的错误，fn 在独立的 goroutine 中执行，
+// 不能让 panic 导致整个进程退出
+type PanicError struct {
+	// recover 得到的值
+	Value interface{}
+	// 发生 panic 时的调用栈
+	Stack []byte
+}
+
+func (p *PanicError) Error() string {
+	return fmt.Sprintf("singleflight: panic: %v\n\n%s", p.Value, p.Stack)
+}
+
+// call 表示一次正在进行或已经完成的加载
+type call[V any] struct {
+	done chan struct{}
+	// 除了发起者以外，等待这次加载的调用者个数
+	dups int
+
+	val V
+	err error
+}
+
+// TypedGroup 管理不同 key 的加载，零值可以直接使用
+type TypedGroup[K comparable, V any] struct {
+	mu sync.Mutex
+	m  map[K]*call[V]
+}
+
+// Group 是 key 为 string、值为 interface{} 的 TypedGroup
+type Group = TypedGroup[string, interface{}]
+
+// Do 执行 fn 并返回结果，同一时刻同一个 key 只会执行一次 fn，
+// 其他调用者等待这次执行完成并得到相同的结果
+func (g *TypedGroup[K, V]) Do(key K, fn func() (V, error)) (V, error) {
+	return g.DoContext(context.Background(), key, fn)
+}
+
+// DoContext 同 Do，ctx 取消时当前调用者立即返回 ctx.Err()，
+// fn 会在独立的 goroutine 中继续执行完，结果仍然交给其他等待者；fn 发生 panic 时返回 *PanicError
+func (g *TypedGroup[K, V]) DoContext(ctx context.Context, key K, fn func() (V, error)) (V, error) {
+	g.mu.Lock()
+	if g.m == nil {
+		g.m = make(map[K]*call[V])
+	}
+	c, ok := g.m[key]
+	if !ok {
+		c = &call[V]{done: make(chan struct{})}
+		g.m[key] = c
+		go g.call(c, key, fn)
+	} else {
+		c.dups++
+	}
+	g.mu.Unlock()
+
+	select {
+	case <-c.done:
+		return c.val, c.err
+	case <-ctx.Done():
+		var zero V
+		return zero, ctx.Err()
+	}
+}
+
+func (g *TypedGroup[K, V]) call(c *call[V], key K, fn func() (V, error)) {
+	defer func() {
+		g.mu.Lock()
+		delete(g.m, key)
+		g.mu.Unlock()
+		close(c.done)
+	}()
+	defer func() {
+		if r := recover(); r != nil {
+			var zero V
+			c.val, c.err = zero, &PanicError{Value: r, Stack: debug.Stack()}
+		}
+	}()
+
+	c.val, c.err = fn()
+}
@@ -0,0 +1,126 @@
+package singleflight
+
+import (
+	"context"
+	"errors"
+	"runtime"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/matryer/is"
+)
+
+func TestDo(t *testing.T) {
+	is := is.New(t)
+
+	var g Group
+	v, err := g.Do("key", func() (interface{}, error) {
+		return "bar", nil
+	})
+	is.NoErr(err)
+	is.Equal(v, "bar")
+
+	someErr := errors.New("some error")
+	v, err = g.Do("key", func() (interface{}, error) {
+		return nil, someErr
+	})
+	is.Equal(err, someErr)
+	is.Equal(v, nil)
+}
+
+func TestDoDupSuppress(t *testing.T) {
+	is := is.New(t)
+
+	var g Group
+	release := make(chan struct{})
+	var calls int32
+	fn := func() (interface{}, error) {
+		atomic.AddInt32(&calls, 1)
+		<-release
+		return "bar", nil
+	}
+
+	const n = 10
+	type result struct {
+		v   interface{}
+		err error
+	}
+	results := make(chan result, n)
+	for i := 0; i < n; i++ {
+		go func() {
+			v, err := g.Do("key", fn)
+			results <- result{v, err}
+		}()
+	}
+	// 所有调用者都在等待同一次加载后再让它完成
+	waitDups(&g, "key", n-1)
+	close(release)
+	for i := 0; i < n; i++ {
+		r := <-results
+		is.NoErr(r.err)
+		is.Equal(r.v, "bar")
+	}
+
+	is.Equal(atomic.LoadInt32(&calls), int32(1))
+}
+
+// waitDups 等待 key 正在进行的加载有 n 个等待者
+func waitDups(g *Group, key string, n int) {
+	for {
+		g.mu.Lock()
+		c, ok := g.m[key]
+		done := ok && c.dups >= n
+		g.mu.Unlock()
+		if done {
+			return
+		}
+		runtime.Gosched()
+	}
+}
+
+func TestDoPanic(t *testing.T) {
+	is := is.New(t)
+
+	var g Group
+	v, err := g.Do("key", func() (interface{}, error) {
+		panic("boom")
+	})
+	var pe *PanicError
+	is.True(errors.As(err, &pe))
+	is.Equal(pe.Value, "boom")
+	is.Equal(v, nil)
+
+	// panic 之后同一个 key 可以再次加载
+	v, err = g.Do("key", func() (interface{}, error) {
+		return "bar", nil
+	})
+	is.NoErr(err)
+	is.Equal(v, "bar")
+}
+
+func TestDoContextCancel(t *testing.T) {
+	is := is.New(t)
+
+	var g Group
+	release := make(chan struct{})
+	fn := func() (interface{}, error) {
+		<-release
+		return "bar", nil
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	done := make(chan interface{})
+	go func() {
+		v, _ := g.Do("key", fn)
+		done <- v
+	}()
+
+	_, err := g.DoContext(ctx, "key", fn)
+	is.Equal(err, context.DeadlineExceeded)
+
+	// 取消等待不影响其他调用者拿到结果
+	close(release)
+	is.Equal(<-done, "bar")
+}
@@ -0,0 +1,159 @@
+package cache
+
+import (
+	"encoding/gob"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// Snapshotter 支持快照的缓存，lru、lfu、fifo、arc、twoq、tinylfu 以及 fast 中的缓存都实现了该接口，
+// 快照中的值通过 Options.Codec 序列化
+type Snapshotter interface {
+	// Snapshot 将所有未过期的 entry 以及淘汰顺序需要的信息写入 w
+	Snapshot(w io.Writer) error
+	// Restore 从 r 中读取快照并写入缓存，已过期的 entry 会被跳过
+	Restore(r io.Reader) error
+}
+
+// SnapshotEntry 快照中的一个 entry，按淘汰顺序从先到后排列
+type SnapshotEntry struct {
+	Key   interface{}
+	Value interface{}
+	// 过期时间，零值表示永不过期
+	ExpireAt time.Time
+	// lfu 中为访问频率；arc、twoq、tinylfu 中表示 entry 所在的队列，从 1 开始；其他缓存不使用
+	Weight int
+}
+
+// 版本 2 开始 key 也通过 Codec 序列化，以支持非 string 的 key
+const snapshotVersion = 2
+
+type snapshotHeader struct {
+	Version int
+	Count   int
+}
+
+type snapshotRecord struct {
+	Key      []byte
+	Value    []byte
+	ExpireAt int64
+	Weight   int
+}
+
+// WriteSnapshot 将 entries 写入 w，key 和值通过 codec 序列化
+func WriteSnapshot(w io.Writer, codec Codec, entries []SnapshotEntry) error {
+	enc := gob.NewEncoder(w)
+	if err := enc.Encode(snapshotHeader{Version: snapshotVersion, Count: len(entries)}); err != nil {
+		return err
+	}
+	for _, se := range entries {
+		key, err := codec.Marshal(se.Key)
+		if err != nil {
+			return fmt.Errorf("cache: marshal key %v: %w", se.Key, err)
+		}
+		value, err := codec.Marshal(se.Value)
+		if err != nil {
+			return fmt.Errorf("cache: marshal %v: %w", se.Key, err)
+		}
+		record := snapshotRecord{Key: key, Value: value, Weight: se.Weight}
+		if !se.ExpireAt.IsZero() {
+			record.ExpireAt = se.ExpireAt.UnixNano()
+		}
+		if err := enc.Encode(record); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ReadSnapshot 读取 WriteSnapshot 写入的 entries
+func ReadSnapshot(r io.Reader, codec Codec) ([]SnapshotEntry, error) {
+	dec := gob.NewDecoder(r)
+	var header snapshotHeader
+	if err := dec.Decode(&header); err != nil {
+		return nil, err
+	}
+	if header.Version != snapshotVersion {
+		return nil, fmt.Errorf("cache: unsupported snapshot version %d", header.Version)
+	}
+	if header.Count < 0 {
+		return nil, fmt.Errorf("cache: invalid snapshot entry count %d", header.Count)
+	}
+
+	// Count 来自文件，不能用来预先分配内存，文件损坏时会 panic 或者耗尽内存
+	var entries []SnapshotEntry
+	for i := 0; i < header.Count; i++ {
+		var record snapshotRecord
+		if err := dec.Decode(&record); err != nil {
+			return nil, err
+		}
+		key, err := codec.Unmarshal(record.Key)
+		if err != nil {
+			return nil, fmt.Errorf("cache: unmarshal key: %w", err)
+		}
+		value, err := codec.Unmarshal(record.Value)
+		if err != nil {
+			return nil, fmt.Errorf("cache: unmarshal %v: %w", key, err)
+		}
+		se := SnapshotEntry{Key: key, Value: value, Weight: record.Weight}
+		if record.ExpireAt != 0 {
+			se.ExpireAt = time.Unix(0, record.ExpireAt)
+		}
+		entries = append(entries, se)
+	}
+	return entries, nil
+}
+
+// Unpack 将 SnapshotEntry 中的 key 和值转换为 K 和 V，类型不匹配时返回错误。
+// 值为 nil 时返回 V 的零值
+func Unpack[K comparable, V any](se SnapshotEntry) (key K, value V, err error) {
+	key, ok := se.Key.(K)
+	if !ok {
+		return key, value, fmt.Errorf("cache: snapshot key %v is %T, not %T", se.Key, se.Key, key)
+	}
+	if se.Value == nil {
+		return key, value, nil
+	}
+	value, ok = se.Value.(V)
+	if !ok {
+		return key, value, fmt.Errorf("cache: snapshot value of %v is %T, not %T", se.Key, se.Value, value)
+	}
+	return key, value, nil
+}
+
+// SaveSnapshot 将快照写入 path，先写入同目录下的临时文件再重命名，不会留下写了一半的快照
+func SaveSnapshot(path string, s Snapshotter) error {
+	f, err := ioutil.TempFile(filepath.Dir(path), filepath.Base(path)+".tmp-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(f.Name())
+
+	if err := s.Snapshot(f); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Sync(); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Close(); err != nil {
+		return err
+	}
+	return os.Rename(f.Name(), path)
+}
+
+// LoadSnapshot 从 path 中恢复快照，文件不存在时返回的错误满足 os.IsNotExist
+func LoadSnapshot(path string, s Snapshotter) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	return s.Restore(f)
+}
@@ -0,0 +1,121 @@
+package tests
+
+import (
+	"math/rand"
+	"sort"
+	"strconv"
+	"testing"
+)
+
+// 缓存大约可以存放的 entry 个数，值为 int32；key 的长度不同，实际的个数会有少许差异
+const traceCacheEntries = 1000
+
+// trace 是一段访问序列
+type trace struct {
+	name string
+	keys []string
+}
+
+// zipfTrace 生成服从 Zipf 分布的访问序列，少量 key 占了大部分访问
+func zipfTrace(n int, s float64, keySpace uint64, seed int64) []string {
+	r := rand.New(rand.NewSource(seed))
+	z := rand.NewZipf(r, s, 1, keySpace-1)
+	keys := make([]string, n)
+	for i := range keys {
+		keys[i] = "z" + strconv.FormatUint(z.Uint64(), 10)
+	}
+	return keys
+}
+
+// scanTrace 在服从 Zipf 分布的热点访问中穿插只访问一次的 key，如后台任务遍历全表
+func scanTrace(n int, seed int64) []string {
+	r := rand.New(rand.NewSource(seed))
+	z := rand.NewZipf(r, 1.01, 1, traceCacheEntries*10)
+	keys := make([]string, n)
+	for i := range keys {
+		if r.Intn(2) == 0 {
+			keys[i] = "scan" + strconv.Itoa(i)
+			continue
+		}
+		keys[i] = "hot" + strconv.FormatUint(z.Uint64(), 10)
+	}
+	return keys
+}
+
+// loopTrace 循环访问比缓存稍大的 key 集合，是 LRU 的最坏情况
+func loopTrace(n int) []string {
+	keys := make([]string, n)
+	for i := range keys {
+		keys[i] = "loop" + strconv.Itoa(i%(traceCacheEntries*5/4))
+	}
+	return keys
+}
+
+func traces() []trace {
+	return []trace{
+		{"zipf", zipfTrace(200000, 1.01, 100000, 1)},
+		{"scan", scanTrace(200000, 2)},
+		{"loop", loopTrace(200000)},
+	}
+}
+
+// hitRatio 按照 Get 未命中再 Set 的方式回放访问序列，返回命中率
+func hitRatio(newCache newCacheFunc, keys []string) float64 {
+	c := newCache(traceCacheEntries*entryBytes(newCache, keys[0], int32(1)), nil)
+	hits := 0
+	for _, key := range keys {
+		if c.Get(key) != nil {
+			hits++
+			continue
+		}
+		c.Set(key, int32(1))
+	}
+	return float64(hits) / float64(len(keys))
+}
+
+func policyNames() []string {
+	names := make([]string, 0, len(policies))
+	for name := range policies {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+func TestHitRatio(t *testing.T) {
+	for _, tr := range traces() {
+		ratios := make(map[string]float64)
+		for _, name := range policyNames() {
+			ratios[name] = hitRatio(policies[name], tr.keys)
+			t.Logf("%-4s %-7s hit ratio %.2f%%", tr.name, name, ratios[name]*100)
+		}
+
+		// 计数器会老化的 TinyLFU 在 Zipf 分布下应该比 LFU 的命中率更高
+		if tr.name == "zipf" && ratios["tinylfu"] <= ratios["lfu"] {
+			t.Errorf("zipf: tinylfu hit ratio %.2f%% <= lfu %.2f%%", ratios["tinylfu"]*100, ratios["lfu"]*100)
+		}
+
+		// 一次性访问会冲刷 LRU，ARC 和 2Q 应该有更高的命中率
+		if tr.name == "scan" {
+			for _, name := range []string{"arc", "twoq"} {
+				if ratios[name] <= ratios["lru"] {
+					t.Errorf("%s: %s hit ratio %.2f%% <= lru %.2f%%", tr.name, name, ratios[name]*100, ratios["lru"]*100)
+				}
+			}
+		}
+	}
+}
+
+func BenchmarkHitRatio(b *testing.B) {
+	for _, tr := range traces() {
+		for _, name := range policyNames() {
+			b.Run(tr.name+"/"+name, func(b *testing.B) {
+				var ratio float64
+				for i := 0; i < b.N; i++ {
+					ratio = hitRatio(policies[name], tr.keys)
+				}
+				b.ReportMetric(ratio*100, "hit%")
+			})
+		}
+	}
+}
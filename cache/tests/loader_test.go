@@ -0,0 +1,143 @@
+package tests
+
+import (
+	"cache"
+	"cache/lru"
+	"cache/singleflight"
+	"context"
+	"errors"
+	"fmt"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/matryer/is"
+)
+
+type article struct {
+	ID    int
+	Title string
+}
+
+func (a *article) Len() int {
+	return 8 + len(a.Title)
+}
+
+func TestLoaderNegativeCache(t *testing.T) {
+	is := is.New(t)
+
+	errDBDown := errors.New("db down")
+	var calls int32
+	var down int32
+	db := map[string]*article{"1": {ID: 1, Title: "Go 语言编程之旅"}}
+	loader := cache.LoaderFunc(func(ctx context.Context, key string) (interface{}, error) {
+		atomic.AddInt32(&calls, 1)
+		if atomic.LoadInt32(&down) == 1 {
+			return nil, errDBDown
+		}
+		if a, ok := db[key]; ok {
+			return a, nil
+		}
+		return nil, fmt.Errorf("article %s: %w", key, cache.ErrNotFound)
+	})
+
+	clock := newFakeClock()
+	// 不存在的 key 由 TourCache 记录，按 TourCache 的时钟过期
+	tourCache := cache.NewTourCache(loader, lru.New(0, nil, cache.WithClock(clock.Now)),
+		cache.WithNegativeTTL(time.Second), cache.WithClock(clock.Now))
+
+	val, err := tourCache.GetContext(context.Background(), "1")
+	is.NoErr(err)
+	is.Equal(val.(*article).ID, 1)
+
+	// 不存在的 key 在 NegativeTTL 内只加载一次
+	for i := 0; i < 3; i++ {
+		val, err = tourCache.GetContext(context.Background(), "404")
+		is.True(errors.Is(err, cache.ErrNotFound))
+		is.Equal(val, nil)
+	}
+	is.Equal(tourCache.Get("404"), nil)
+	is.Equal(atomic.LoadInt32(&calls), int32(2))
+
+	clock.Add(time.Second)
+	_, err = tourCache.GetContext(context.Background(), "404")
+	is.True(errors.Is(err, cache.ErrNotFound))
+	is.Equal(atomic.LoadInt32(&calls), int32(3))
+
+	// 加载失败不会被缓存
+	atomic.StoreInt32(&down, 1)
+	for i := 0; i < 3; i++ {
+		_, err = tourCache.GetContext(context.Background(), "2")
+		is.Equal(err, errDBDown)
+	}
+	is.Equal(atomic.LoadInt32(&calls), int32(6))
+
+	atomic.StoreInt32(&down, 0)
+	db["2"] = &article{ID: 2, Title: "Go 并发编程"}
+	val, err = tourCache.GetContext(context.Background(), "2")
+	is.NoErr(err)
+	is.Equal(val.(*article).ID, 2)
+
+	// 已缓存的值不受加载失败的影响
+	atomic.StoreInt32(&down, 1)
+	val, err = tourCache.GetContext(context.Background(), "1")
+	is.NoErr(err)
+	is.Equal(val.(*article).ID, 1)
+}
+
+func TestLoaderMaxNotFound(t *testing.T) {
+	is := is.New(t)
+
+	var calls int32
+	loader := cache.LoaderFunc(func(ctx context.Context, key string) (interface{}, error) {
+		atomic.AddInt32(&calls, 1)
+		return nil, cache.ErrNotFound
+	})
+	tourCache := cache.NewTourCache(loader, lru.New(0, nil),
+		cache.WithNegativeTTL(time.Minute), cache.WithMaxNotFound(1))
+
+	tourCache.Get("404")
+	tourCache.Get("405")
+	is.Equal(atomic.LoadInt32(&calls), int32(2))
+
+	// 只保留最近的一个不存在的 key
+	tourCache.Get("405")
+	is.Equal(atomic.LoadInt32(&calls), int32(2))
+	tourCache.Get("404")
+	is.Equal(atomic.LoadInt32(&calls), int32(3))
+}
+
+func TestLoaderPanic(t *testing.T) {
+	is := is.New(t)
+
+	loader := cache.LoaderFunc(func(ctx context.Context, key string) (interface{}, error) {
+		panic("db driver bug")
+	})
+	tourCache := cache.NewTourCache(loader, lru.New(0, nil))
+
+	// loader panic 不会导致进程退出，调用者得到错误，结果不会被缓存
+	_, err := tourCache.GetContext(context.Background(), "1")
+	var pe *singleflight.PanicError
+	is.True(errors.As(err, &pe))
+	is.Equal(tourCache.Len(), 0)
+}
+
+func TestLoaderWithoutNegativeTTL(t *testing.T) {
+	is := is.New(t)
+
+	var calls int32
+	loader := cache.LoaderFunc(func(ctx context.Context, key string) (interface{}, error) {
+		atomic.AddInt32(&calls, 1)
+		return nil, nil
+	})
+	tourCache := cache.NewTourCache(loader, lru.New(0, nil))
+
+	for i := 0; i < 3; i++ {
+		_, err := tourCache.GetContext(context.Background(), "404")
+		is.Equal(err, cache.ErrNotFound)
+	}
+	is.Equal(atomic.LoadInt32(&calls), int32(3))
+
+	_, err := cache.NewTourCache(nil, lru.New(0, nil)).GetContext(context.Background(), "404")
+	is.Equal(err, cache.ErrNotFound)
+}
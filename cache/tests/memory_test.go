@@ -0,0 +1,101 @@
+package tests
+
+import (
+	"cache"
+	"runtime"
+	"strconv"
+	"testing"
+	"unsafe"
+
+	"github.com/matryer/is"
+)
+
+type post struct {
+	ID    int
+	Title string
+	Tags  []string
+	Meta  map[string]int
+	Next  *post
+}
+
+func TestSizeOf(t *testing.T) {
+	is := is.New(t)
+
+	is.Equal(cache.SizeOf(nil), 0)
+	is.Equal(cache.SizeOf(int64(1)), 8)
+	is.Equal(cache.SizeOf("hello"), int(unsafe.Sizeof(""))+5)
+	is.Equal(cache.SizeOf(make([]int32, 2, 4)), int(unsafe.Sizeof([]int32{}))+16)
+	is.Equal(cache.SizeOf([2]string{"a", "bc"}), int(unsafe.Sizeof([2]string{}))+3)
+
+	structSize := int(unsafe.Sizeof(post{}))
+	is.Equal(cache.SizeOf(post{}), structSize)
+	is.Equal(cache.SizeOf(&post{}), int(unsafe.Sizeof(uintptr(0)))+structSize)
+
+	// 字符串内容、slice 引用的数组和指针指向的结构体都要计算
+	a := post{ID: 1, Title: "gotour", Tags: []string{"go", "cache"}}
+	tagsSize := 2*int(unsafe.Sizeof("")) + len("go") + len("cache")
+	is.Equal(cache.SizeOf(a), structSize+len("gotour")+tagsSize)
+	a.Next = &post{Title: "next"}
+	is.Equal(cache.SizeOf(a), 2*structSize+len("gotour")+tagsSize+len("next"))
+
+	// 循环引用只计算一次
+	b := &post{Title: "loop"}
+	b.Next = b
+	is.Equal(cache.SizeOf(b), int(unsafe.Sizeof(b))+structSize+len("loop"))
+
+	// map 按装载因子估算，至少包括所有的 key 和值
+	m := map[string]int{"a": 1, "b": 2}
+	is.True(cache.SizeOf(m) > 2*int(unsafe.Sizeof("")+unsafe.Sizeof(0))+2)
+
+	// chan、func 等类型只计算自身的大小，不会 panic
+	is.Equal(cache.SizeOf(make(chan int)), int(unsafe.Sizeof(make(chan int))))
+	is.Equal(cache.SizeOf(func() {}), int(unsafe.Sizeof(func() {})))
+}
+
+func TestCalcLen(t *testing.T) {
+	is := is.New(t)
+
+	is.Equal(cache.CalcLen(int32(1)), 4)
+	is.Equal(cache.CalcLen("hello"), int(unsafe.Sizeof(""))+5)
+	is.Equal(cache.CalcLen(make([]byte, 1, 8)), int(unsafe.Sizeof([]byte{}))+8)
+	// 其他类型使用 SizeOf，不会 panic
+	a := post{Title: "gotour", Meta: map[string]int{"views": 1}}
+	is.Equal(cache.CalcLen(a), cache.SizeOf(a))
+
+	// key 越长，占用的字节数越多
+	is.True(cache.KeySize("k1") > 0)
+	is.Equal(cache.KeySize("key10")-cache.KeySize("k1"), 3)
+}
+
+// BenchmarkMemoryAccounting 比较缓存报告的字节数和实际增加的堆内存，
+// reported/heap 越接近 1 说明 maxBytes 越能反映真实的内存占用
+func BenchmarkMemoryAccounting(b *testing.B) {
+	const n = 10000
+	for _, name := range policyNames() {
+		b.Run(name, func(b *testing.B) {
+			var heap, reported int
+			for i := 0; i < b.N; i++ {
+				var before, after runtime.MemStats
+				runtime.GC()
+				runtime.ReadMemStats(&before)
+
+				m := cache.NewMetrics("", name)
+				c := cache.NewTourCache(nil, policies[name](0, nil), cache.WithMetrics(m))
+				for j := 0; j < n; j++ {
+					id := strconv.Itoa(j)
+					c.Set("post:"+id, &post{ID: j, Title: "title " + id, Tags: []string{"go", "cache"}})
+				}
+
+				runtime.GC()
+				runtime.ReadMemStats(&after)
+				heap = int(after.HeapAlloc - before.HeapAlloc)
+				reported = m.Snapshot().Bytes
+				runtime.KeepAlive(c)
+				c.Close()
+			}
+			b.ReportMetric(float64(heap)/n, "heap-B/entry")
+			b.ReportMetric(float64(reported)/n, "reported-B/entry")
+			b.ReportMetric(float64(reported)/float64(heap), "reported/heap")
+		})
+	}
+}
@@ -0,0 +1,139 @@
+package tests
+
+import (
+	"bytes"
+	"cache"
+	"cache/lru"
+	"context"
+	"encoding/json"
+	"errors"
+	"expvar"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/matryer/is"
+)
+
+func TestMetrics(t *testing.T) {
+	for name, newCache := range policies {
+		t.Run(name, func(t *testing.T) {
+			is := is.New(t)
+
+			clock := newFakeClock()
+			m := cache.NewMetrics("articles", name)
+			loader := cache.LoaderFunc(func(ctx context.Context, key string) (interface{}, error) {
+				switch key {
+				case "missing":
+					return nil, cache.ErrNotFound
+				case "broken":
+					return nil, errors.New("db down")
+				}
+				return int32(len(key)), nil
+			})
+			opts := []cache.Option{cache.WithMetrics(m), cache.WithClock(clock.Now)}
+			// 容量为 2 个 entry
+			tourCache := cache.NewTourCache(loader, newCache(2*entryBytes(newCache, "k1", int32(1)), nil, opts...), opts...)
+			defer tourCache.Close()
+
+			tourCache.Get("k1")
+			tourCache.Get("k1")
+			tourCache.Get("missing")
+			tourCache.Get("broken")
+			tourCache.SetWithTTL("k2", int32(2), time.Second)
+			tourCache.Del("k1")
+
+			s := m.Snapshot()
+			is.Equal(s.Name, "articles")
+			is.Equal(s.Policy, name)
+			is.Equal(s.Hits, uint64(1))
+			is.Equal(s.Misses, uint64(3))
+			is.Equal(s.Sets, uint64(2))
+			is.Equal(s.Loads, uint64(1))
+			is.Equal(s.LoadNotFound, uint64(1))
+			is.Equal(s.LoadErrors, uint64(1))
+			is.Equal(s.LoadLatency.Count, uint64(3))
+			is.Equal(s.Evictions["deleted"], uint64(1))
+			is.Equal(s.Entries, 1)
+			is.Equal(s.Bytes, entryBytes(newCache, "k2", int32(2)))
+
+			clock.Add(time.Second)
+			tourCache.Get("k2")
+			for i := 0; i < 5; i++ {
+				tourCache.Set(string(rune('a'+i)), int32(i))
+			}
+			s = m.Snapshot()
+			// fast 在读锁下不删除过期的 entry，k2 会在写入时因容量不足被淘汰
+			if name != "fast" {
+				is.Equal(s.Evictions["expired"], uint64(1))
+			}
+			is.True(s.Evictions["expired"]+s.Evictions["capacity"] >= 4)
+			is.True(s.Entries <= 2)
+		})
+	}
+}
+
+func TestHistogram(t *testing.T) {
+	is := is.New(t)
+
+	h := cache.NewHistogram([]float64{0.1, 1})
+	h.Observe(0.05)
+	h.Observe(0.1)
+	h.Observe(0.5)
+	h.Observe(3)
+
+	s := h.Snapshot()
+	is.Equal(s.Counts, []uint64{2, 3})
+	is.Equal(s.Count, uint64(4))
+	is.True(s.Sum > 3.649 && s.Sum < 3.651)
+}
+
+func TestMetricsExport(t *testing.T) {
+	is := is.New(t)
+
+	registry := cache.NewRegistry()
+	m := cache.NewMetrics(`a"b`, "lru")
+	registry.Register(m, cache.NewMetrics("tags", "arc"))
+
+	tourCache := cache.NewTourCache(cache.GetFunc(func(key string) interface{} {
+		return "val:" + key
+	}), lru.New(0, nil, cache.WithMetrics(m)), cache.WithMetrics(m))
+	defer tourCache.Close()
+	tourCache.Get("k1")
+	tourCache.Get("k1")
+
+	rec := httptest.NewRecorder()
+	registry.ServeHTTP(rec, httptest.NewRequest("GET", "/metrics", nil))
+	is.True(strings.HasPrefix(rec.Header().Get("Content-Type"), "text/plain"))
+	body := rec.Body.String()
+	for _, line := range []string{
+		"# TYPE tourcache_hits_total counter",
+		`tourcache_hits_total{cache="a\"b",policy="lru"} 1`,
+		`tourcache_misses_total{cache="a\"b",policy="lru"} 1`,
+		`tourcache_evictions_total{cache="a\"b",policy="lru",reason="capacity"} 0`,
+		`tourcache_loads_total{cache="a\"b",policy="lru",result="ok"} 1`,
+		"# TYPE tourcache_load_duration_seconds histogram",
+		`tourcache_load_duration_seconds_bucket{cache="a\"b",policy="lru",le="+Inf"} 1`,
+		`tourcache_load_duration_seconds_count{cache="a\"b",policy="lru"} 1`,
+		`tourcache_entries{cache="a\"b",policy="lru"} 1`,
+		`tourcache_bytes{cache="a\"b",policy="lru"} ` + strconv.Itoa(entryBytes(lru.New, "k1", "val:k1")),
+		`tourcache_entries{cache="tags",policy="arc"} 0`,
+	} {
+		is.True(strings.Contains(body, line+"\n")) // 缺少指标
+	}
+	// 没有关联缓存的 Metrics 不知道字节数
+	is.True(!strings.Contains(body, `tourcache_bytes{cache="tags"`))
+
+	registry.PublishExpvar("tourcache_test")
+	var vars map[string]cache.MetricsSnapshot
+	is.NoErr(json.Unmarshal([]byte(expvar.Get("tourcache_test").String()), &vars))
+	is.Equal(vars[`a"b`].Hits, uint64(1))
+	is.Equal(vars["tags"].Policy, "arc")
+
+	var buf bytes.Buffer
+	registry.Unregister("tags")
+	is.NoErr(registry.WritePrometheus(&buf))
+	is.True(!strings.Contains(buf.String(), `cache="tags"`))
+}
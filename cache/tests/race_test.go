@@ -0,0 +1,145 @@
+package tests
+
+import (
+	"bytes"
+	"cache"
+	"cache/fast"
+	"context"
+	"math/rand"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/matryer/is"
+)
+
+// 并发测试需要配合 go test -race 运行，才能发现数据竞争
+const (
+	raceGoroutines = 8
+	raceKeys       = 64
+)
+
+func raceOps() int {
+	if testing.Short() {
+		return 500
+	}
+	return 5000
+}
+
+// stress 启动 raceGoroutines 个 goroutine，每个执行 raceOps 次 op，key 在 raceKeys 个中随机选择
+func stress(op func(r *rand.Rand, key string)) {
+	var wg sync.WaitGroup
+	for i := 0; i < raceGoroutines; i++ {
+		wg.Add(1)
+		go func(seed int64) {
+			defer wg.Done()
+			r := rand.New(rand.NewSource(seed))
+			for j := 0; j < raceOps(); j++ {
+				op(r, "key"+strconv.Itoa(r.Intn(raceKeys)))
+			}
+		}(int64(i))
+	}
+	wg.Wait()
+}
+
+func TestRaceTourCache(t *testing.T) {
+	for name, newCache := range policies {
+		t.Run(name, func(t *testing.T) {
+			is := is.New(t)
+
+			loader := cache.LoaderFunc(func(ctx context.Context, key string) (interface{}, error) {
+				if key[len(key)-1] == '0' {
+					return nil, cache.ErrNotFound
+				}
+				return int32(len(key)), nil
+			})
+			tourCache := cache.NewTourCache(loader, newCache(raceKeys*2, nil),
+				cache.WithDefaultTTL(time.Millisecond),
+				cache.WithNegativeTTL(time.Millisecond),
+				cache.WithCleanupInterval(time.Millisecond))
+			defer tourCache.Close()
+
+			var gets int64
+			stress(func(r *rand.Rand, key string) {
+				switch n := r.Intn(10); {
+				case n < 6:
+					atomic.AddInt64(&gets, 1)
+					tourCache.Get(key)
+				case n < 8:
+					tourCache.SetWithTTL(key, int32(n), time.Duration(n)*time.Millisecond)
+				case n < 9:
+					tourCache.Del(key)
+				default:
+					tourCache.Stat()
+					// 快照比较慢，偶尔做一次
+					if r.Intn(50) == 0 {
+						tourCache.Snapshot(&bytes.Buffer{})
+					}
+				}
+			})
+
+			is.Equal(int64(tourCache.Stat().NGet), gets)
+		})
+	}
+}
+
+func TestRaceFastCache(t *testing.T) {
+	is := is.New(t)
+
+	const maxEntries = 4
+	c := fast.NewFastCache(maxEntries, 0, 8, nil, cache.WithCleanupInterval(time.Millisecond))
+	defer c.Close()
+
+	var gets uint64
+	stress(func(r *rand.Rand, key string) {
+		switch n := r.Intn(20); {
+		case n < 12:
+			atomic.AddUint64(&gets, 1)
+			c.Get(key)
+		case n < 16:
+			c.SetWithTTL(key, n, time.Duration(n)*time.Millisecond)
+		case n < 18:
+			c.Del(key)
+		case n < 19:
+			c.DelOldest()
+		default:
+			c.Len()
+			c.Stat()
+		}
+	})
+
+	is.True(c.Len() <= maxEntries*8)
+	stat := c.Stat()
+	is.Equal(stat.Hits+stat.Misses, gets)
+}
+
+func TestRaceArenaCache(t *testing.T) {
+	is := is.New(t)
+
+	// 容量很小，写入时会不断回绕淘汰
+	c := fast.NewArenaCache(4096, 4, nil, cache.WithCleanupInterval(time.Millisecond))
+	defer c.Close()
+
+	var gets uint64
+	stress(func(r *rand.Rand, key string) {
+		switch n := r.Intn(10); {
+		case n < 6:
+			atomic.AddUint64(&gets, 1)
+			if value := c.Get(key); value != nil && string(value) != key {
+				t.Errorf("Get(%q) = %q", key, value)
+			}
+		case n < 8:
+			c.SetWithTTL(key, []byte(key), time.Duration(n)*time.Millisecond)
+		case n < 9:
+			c.Del(key)
+		default:
+			c.Len()
+			c.Stat()
+		}
+	})
+
+	stat := c.Stat()
+	is.Equal(stat.Hits+stat.Misses, gets)
+}
@@ -0,0 +1,258 @@
+package tests
+
+import (
+	"bytes"
+	"cache"
+	"cache/fast"
+	"cache/lfu"
+	"cache/lru"
+	"encoding/gob"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strconv"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/matryer/is"
+)
+
+func snapshot(t *testing.T, c cache.Cache) *bytes.Buffer {
+	var buf bytes.Buffer
+	if err := c.(cache.Snapshotter).Snapshot(&buf); err != nil {
+		t.Fatal(err)
+	}
+	return &buf
+}
+
+func TestSnapshotRestore(t *testing.T) {
+	for name, newCache := range policies {
+		t.Run(name, func(t *testing.T) {
+			is := is.New(t)
+
+			clock := newFakeClock()
+			c := newCache(0, nil, cache.WithClock(clock.Now))
+			c.Set("k1", "v1")
+			c.SetWithTTL("k2", int32(2), time.Minute)
+			c.SetWithTTL("k3", []byte("v3"), time.Second)
+			buf := snapshot(t, c)
+
+			clock.Add(time.Second)
+			restored := newCache(0, nil, cache.WithClock(clock.Now))
+			is.NoErr(restored.(cache.Snapshotter).Restore(buf))
+			is.Equal(restored.Len(), 2)
+			is.Equal(restored.Get("k1"), "v1")
+			is.Equal(restored.Get("k2"), int32(2))
+			// 已过期的 entry 不会恢复
+			is.Equal(restored.Get("k3"), nil)
+
+			// 剩余的过期时间保持不变
+			clock.Add(time.Minute - time.Second)
+			is.Equal(restored.Get("k2"), nil)
+		})
+	}
+}
+
+func TestSnapshotRoundTrip(t *testing.T) {
+	for name, newCache := range policies {
+		t.Run(name, func(t *testing.T) {
+			is := is.New(t)
+
+			c := newCache(0, nil)
+			for i := 0; i < 10; i++ {
+				c.Set(strconv.Itoa(i), int32(i))
+			}
+			for i := 0; i < 10; i += 3 {
+				c.Get(strconv.Itoa(i))
+			}
+			want, err := cache.ReadSnapshot(snapshot(t, c), cache.GobCodec{})
+			is.NoErr(err)
+
+			// 恢复后再次保存的快照和原来的一致，包括顺序和所在的队列
+			restored := newCache(0, nil)
+			is.NoErr(restored.(cache.Snapshotter).Restore(snapshot(t, c)))
+			got, err := cache.ReadSnapshot(snapshot(t, restored), cache.GobCodec{})
+			is.NoErr(err)
+			is.Equal(got, want)
+		})
+	}
+}
+
+func TestSnapshotOrder(t *testing.T) {
+	is := is.New(t)
+
+	// lru：k1 被访问过，恢复后最久未使用的是 k2
+	c := lru.New(3*entryBytes(lru.New, "k1", int32(1)), nil)
+	c.Set("k1", int32(1))
+	c.Set("k2", int32(2))
+	c.Set("k3", int32(3))
+	c.Get("k1")
+
+	restored := lru.New(3*entryBytes(lru.New, "k1", int32(1)), nil)
+	is.NoErr(restored.(cache.Snapshotter).Restore(snapshot(t, c)))
+	restored.Set("k4", int32(4))
+	is.Equal(restored.Get("k2"), nil)
+	is.Equal(restored.Get("k1"), int32(1))
+
+	// lfu：访问频率在恢复后保持不变
+	c = lfu.New(3*entryBytes(lfu.New, "k1", int32(1)), nil)
+	c.Set("k1", int32(1))
+	c.Set("k2", int32(2))
+	c.Set("k3", int32(3))
+	for i := 0; i < 3; i++ {
+		c.Get("k1")
+		c.Get("k2")
+	}
+
+	restored = lfu.New(3*entryBytes(lfu.New, "k1", int32(1)), nil)
+	is.NoErr(restored.(cache.Snapshotter).Restore(snapshot(t, c)))
+	restored.Set("k4", int32(4))
+	is.Equal(restored.Get("k3"), nil)
+	restored.Set("k5", int32(5))
+	is.Equal(restored.Get("k4"), nil)
+	is.Equal(restored.Get("k1"), int32(1))
+	is.Equal(restored.Get("k2"), int32(2))
+
+	// fast：各个分片中的记录按最近访问的先后合并
+	newFast := func(maxBytes int) cache.Cache {
+		return fast.NewFastCache(0, maxBytes, 4, nil)
+	}
+	c = newFast(0)
+	for i := 0; i < 8; i++ {
+		c.Set(strconv.Itoa(i), int32(i))
+	}
+	c.Get("0")
+	entries, err := cache.ReadSnapshot(snapshot(t, c), cache.GobCodec{})
+	is.NoErr(err)
+	is.Equal(entries[0].Key, "1")
+	is.Equal(entries[len(entries)-1].Key, "0")
+}
+
+func TestSnapshotCorrupt(t *testing.T) {
+	is := is.New(t)
+
+	header := func(count int) *bytes.Buffer {
+		var buf bytes.Buffer
+		is.NoErr(gob.NewEncoder(&buf).Encode(struct{ Version, Count int }{2, count}))
+		return &buf
+	}
+
+	// 负数和过大的个数都只返回错误，不会 panic 或者预先分配内存
+	_, err := cache.ReadSnapshot(header(-1), cache.GobCodec{})
+	is.True(err != nil)
+	_, err = cache.ReadSnapshot(header(1<<62), cache.GobCodec{})
+	is.True(err != nil)
+}
+
+func TestArenaSnapshot(t *testing.T) {
+	is := is.New(t)
+
+	clock := newFakeClock()
+	c := fast.NewArenaCache(0, 4, nil, cache.WithClock(clock.Now))
+	is.NoErr(c.Set("k1", []byte("v1")))
+	is.NoErr(c.SetWithTTL("k2", []byte("v2"), time.Minute))
+	is.NoErr(c.SetWithTTL("k3", []byte("v3"), time.Second))
+	c.Del("k1")
+	var buf bytes.Buffer
+	is.NoErr(c.Snapshot(&buf))
+
+	clock.Add(time.Second)
+	restored := fast.NewArenaCache(0, 4, nil, cache.WithClock(clock.Now))
+	is.NoErr(restored.Restore(&buf))
+	is.Equal(restored.Len(), 1)
+	is.Equal(restored.Get("k2"), []byte("v2"))
+
+	clock.Add(time.Minute)
+	is.Equal(restored.Get("k2"), nil)
+}
+
+// jsonCodec 用于测试自定义的序列化方式，值统一反序列化为 string
+type jsonCodec struct {
+	calls int32
+}
+
+func (c *jsonCodec) Marshal(v interface{}) ([]byte, error) {
+	atomic.AddInt32(&c.calls, 1)
+	return json.Marshal(v)
+}
+
+func (c *jsonCodec) Unmarshal(data []byte) (interface{}, error) {
+	atomic.AddInt32(&c.calls, 1)
+	var s string
+	err := json.Unmarshal(data, &s)
+	return s, err
+}
+
+func TestSnapshotCodec(t *testing.T) {
+	is := is.New(t)
+
+	codec := &jsonCodec{}
+	c := lru.New(0, nil, cache.WithCodec(codec))
+	c.Set("k1", "v1")
+
+	restored := lru.New(0, nil, cache.WithCodec(codec))
+	is.NoErr(restored.(cache.Snapshotter).Restore(snapshot(t, c)))
+	is.Equal(restored.Get("k1"), "v1")
+	// key 和值都通过 codec 序列化
+	is.Equal(atomic.LoadInt32(&codec.calls), int32(4))
+}
+
+func TestTourCacheSnapshotFile(t *testing.T) {
+	is := is.New(t)
+
+	path := filepath.Join(t.TempDir(), "cache.snapshot")
+	var loads int32
+	getter := cache.GetFunc(func(key string) interface{} {
+		atomic.AddInt32(&loads, 1)
+		if key == "missing" {
+			return nil
+		}
+		return "val-" + key
+	})
+
+	tourCache := cache.NewTourCache(getter, lru.New(0, nil), cache.WithSnapshot(path, time.Millisecond),
+		cache.WithNegativeTTL(time.Minute))
+	is.Equal(tourCache.Get("key1"), "val-key1")
+	tourCache.Set("key2", "val2")
+	// 缓存的不存在的 key 不会写入快照
+	is.Equal(tourCache.Get("missing"), nil)
+
+	// 后台定期写入快照
+	deadline := time.Now().Add(time.Second)
+	for {
+		if _, err := os.Stat(path); err == nil || time.Now().After(deadline) {
+			break
+		}
+		time.Sleep(time.Millisecond)
+	}
+	tourCache.Close()
+	_, err := os.Stat(path)
+	is.NoErr(err)
+
+	// 重启后从快照恢复，不需要再调用 getter
+	tourCache = cache.NewTourCache(getter, lru.New(0, nil), cache.WithSnapshot(path, 0))
+	defer tourCache.Close()
+	is.Equal(tourCache.Get("key1"), "val-key1")
+	is.Equal(tourCache.Get("key2"), "val2")
+	is.Equal(atomic.LoadInt32(&loads), int32(2))
+
+	// 临时文件已经被重命名或删除
+	files, err := filepath.Glob(filepath.Join(filepath.Dir(path), "*"))
+	is.NoErr(err)
+	is.Equal(len(files), 1)
+}
+
+func TestSnapshotUnsupported(t *testing.T) {
+	is := is.New(t)
+
+	tourCache := cache.NewTourCache(nil, fakeCache{})
+	defer tourCache.Close()
+	var buf bytes.Buffer
+	is.Equal(tourCache.Snapshot(&buf), cache.ErrSnapshotUnsupported)
+}
+
+// fakeCache 没有实现 cache.Snapshotter
+type fakeCache struct {
+	cache.Cache
+}
@@ -3,8 +3,10 @@ package tests
 import (
 	"cache"
 	"cache/lru"
+	"context"
 	"log"
 	"sync"
+	"sync/atomic"
 	"testing"
 
 	"github.com/matryer/is"
@@ -48,3 +50,89 @@ func TestTourCacheGet(t *testing.T) {
 	is.Equal(tourCache.Stat().NGet, 10)
 	is.Equal(tourCache.Stat().NHit, 4)
 }
+
+func TestTourCacheDel(t *testing.T) {
+	is := is.New(t)
+
+	// 容量为 2 个 entry，Del 检查 key 是否存在时不能调整淘汰顺序
+	c := lru.New(2*entryBytes(lru.New, "k1", int32(1)), nil)
+	tourCache := cache.NewTourCache(nil, c)
+	tourCache.Set("k1", int32(1))
+	tourCache.Set("k2", int32(2))
+	is.True(!tourCache.Del("k3"))
+	is.Equal(tourCache.Len(), 2)
+	is.True(c.Contains("k1"))
+
+	is.True(tourCache.Del("k1"))
+	is.True(!tourCache.Del("k1"))
+	is.Equal(tourCache.Len(), 1)
+}
+
+func TestTourCacheCoalesce(t *testing.T) {
+	is := is.New(t)
+
+	started := make(chan struct{})
+	release := make(chan struct{})
+	var calls int32
+	getter := cache.GetFunc(func(key string) interface{} {
+		if atomic.AddInt32(&calls, 1) == 1 {
+			close(started)
+		}
+		<-release
+		return "val:" + key
+	})
+	tourCache := cache.NewTourCache(getter, lru.New(0, nil))
+
+	// 加载完成前到达的调用者等待同一次加载，之后到达的直接命中缓存，都只会调用一次 getter
+	const n = 100
+	vals := make(chan interface{}, n)
+	for i := 0; i < n; i++ {
+		go func() {
+			vals <- tourCache.Get("key")
+		}()
+	}
+	<-started
+	close(release)
+	for i := 0; i < n; i++ {
+		is.Equal(<-vals, "val:key")
+	}
+
+	is.Equal(atomic.LoadInt32(&calls), int32(1))
+	is.Equal(tourCache.Get("key"), "val:key")
+	is.Equal(atomic.LoadInt32(&calls), int32(1))
+}
+
+func TestTourCacheGetContext(t *testing.T) {
+	is := is.New(t)
+
+	started := make(chan struct{})
+	release := make(chan struct{})
+	var once sync.Once
+	getter := cache.GetFunc(func(key string) interface{} {
+		once.Do(func() { close(started) })
+		<-release
+		return "val:" + key
+	})
+	tourCache := cache.NewTourCache(getter, lru.New(0, nil))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	errs := make(chan error, 10)
+	for i := 0; i < cap(errs); i++ {
+		go func() {
+			_, err := tourCache.GetContext(ctx, "key")
+			errs <- err
+		}()
+	}
+	// 加载开始后取消，加载完成前所有调用者都只能因为取消返回
+	<-started
+	cancel()
+	for i := 0; i < cap(errs); i++ {
+		is.Equal(<-errs, context.Canceled)
+	}
+
+	// 加载在取消后继续完成并写入缓存
+	close(release)
+	val, err := tourCache.GetContext(context.Background(), "key")
+	is.NoErr(err)
+	is.Equal(val, "val:key")
+}
@@ -0,0 +1,198 @@
+package tests
+
+import (
+	"cache"
+	"cache/arc"
+	"cache/fast"
+	"cache/fifo"
+	"cache/lfu"
+	"cache/lru"
+	"cache/tinylfu"
+	"cache/twoq"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/matryer/is"
+)
+
+// fakeClock 可以手动拨动的时钟
+type fakeClock struct {
+	mu  sync.Mutex
+	now time.Time
+}
+
+func newFakeClock() *fakeClock {
+	return &fakeClock{now: time.Unix(1600000000, 0)}
+}
+
+func (c *fakeClock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.now
+}
+
+func (c *fakeClock) Add(d time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.now = c.now.Add(d)
+}
+
+// evictions 记录 onEvicted 的调用
+type evictions struct {
+	mu      sync.Mutex
+	reasons map[string]cache.EvictReason
+}
+
+func newEvictions() *evictions {
+	return &evictions{reasons: make(map[string]cache.EvictReason)}
+}
+
+func (e *evictions) onEvicted(key string, value interface{}, reason cache.EvictReason) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.reasons[key] = reason
+}
+
+func (e *evictions) reason(key string) cache.EvictReason {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	return e.reasons[key]
+}
+
+type newCacheFunc func(maxBytes int, onEvicted cache.OnEvicted, opts ...cache.Option) cache.Cache
+
+var policies = map[string]newCacheFunc{
+	"lru":     lru.New,
+	"lfu":     lfu.New,
+	"fifo":    fifo.New,
+	"arc":     arc.New,
+	"twoq":    twoq.New,
+	"tinylfu": tinylfu.New,
+	"fast": func(maxBytes int, onEvicted cache.OnEvicted, opts ...cache.Option) cache.Cache {
+		return fast.NewFastCache(0, maxBytes, 1, onEvicted, opts...)
+	},
+}
+
+// entryBytes 返回 newCache 创建的缓存中一个 entry 占用的字节数，包括 key 和 entry 本身的开销，用于按 entry 个数设置容量
+func entryBytes(newCache newCacheFunc, key string, value interface{}) int {
+	m := cache.NewMetrics("", "")
+	tourCache := cache.NewTourCache(nil, newCache(0, nil), cache.WithMetrics(m))
+	defer tourCache.Close()
+	tourCache.Set(key, value)
+	return m.Snapshot().Bytes
+}
+
+func TestTTL(t *testing.T) {
+	for name, newCache := range policies {
+		t.Run(name, func(t *testing.T) {
+			is := is.New(t)
+
+			clock := newFakeClock()
+			ev := newEvictions()
+			c := newCache(0, ev.onEvicted, cache.WithDefaultTTL(time.Minute), cache.WithClock(clock.Now))
+
+			c.Set("default", int32(1))
+			c.SetWithTTL("short", int32(2), time.Second)
+			c.SetWithTTL("forever", int32(3), 0)
+
+			clock.Add(time.Second)
+			is.Equal(c.Get("short"), nil)
+			is.Equal(c.Get("default"), int32(1))
+
+			clock.Add(time.Minute)
+			is.Equal(c.Get("default"), nil)
+			is.Equal(c.Get("forever"), int32(3))
+
+			// 过期的 entry 在 Get 或 DeleteExpired 时移除
+			c.DeleteExpired()
+			is.Equal(c.Len(), 1)
+			is.Equal(ev.reason("short"), cache.EvictExpired)
+			is.Equal(ev.reason("default"), cache.EvictExpired)
+
+			// 重新写入会刷新过期时间
+			c.SetWithTTL("forever", int32(4), time.Second)
+			clock.Add(time.Second)
+			is.Equal(c.Get("forever"), nil)
+		})
+	}
+}
+
+func TestContains(t *testing.T) {
+	for name, newCache := range policies {
+		t.Run(name, func(t *testing.T) {
+			is := is.New(t)
+
+			clock := newFakeClock()
+			ev := newEvictions()
+			c := newCache(0, ev.onEvicted, cache.WithClock(clock.Now))
+			c.Set("k1", int32(1))
+			c.SetWithTTL("k2", int32(2), time.Second)
+
+			is.True(c.Contains("k1"))
+			is.True(c.Contains("k2"))
+			is.True(!c.Contains("k3"))
+
+			// 已过期的 entry 视为不存在，但不会被删除
+			clock.Add(time.Second)
+			is.True(!c.Contains("k2"))
+			is.Equal(c.Len(), 2)
+			is.Equal(ev.reason("k2"), cache.EvictReason(0))
+		})
+	}
+}
+
+func TestEvictReason(t *testing.T) {
+	for name, newCache := range policies {
+		t.Run(name, func(t *testing.T) {
+			is := is.New(t)
+
+			ev := newEvictions()
+			c := newCache(2*entryBytes(newCache, "k1", int32(1)), ev.onEvicted)
+			c.Set("k1", int32(1))
+			c.Set("k2", int32(2))
+			c.Del("k2")
+			c.Set("k3", int32(3))
+			c.Set("k4", int32(4))
+
+			victim := "k1"
+			if name == "tinylfu" {
+				// 访问频率相同时 TinyLFU 拒绝新写入的 entry
+				victim = "k4"
+			}
+			is.Equal(ev.reason("k2"), cache.EvictDeleted)
+			is.Equal(ev.reason(victim), cache.EvictCapacity)
+			is.Equal(cache.EvictCapacity.String(), "capacity")
+		})
+	}
+}
+
+func TestJanitor(t *testing.T) {
+	is := is.New(t)
+
+	clock := newFakeClock()
+	ev := newEvictions()
+	tourCache := cache.NewTourCache(nil, lru.New(0, ev.onEvicted, cache.WithClock(clock.Now)),
+		cache.WithCleanupInterval(time.Millisecond))
+	defer tourCache.Close()
+
+	tourCache.SetWithTTL("k1", "v1", time.Second)
+	clock.Add(time.Second)
+
+	deadline := time.Now().Add(time.Second)
+	for ev.reason("k1") == 0 && time.Now().Before(deadline) {
+		time.Sleep(time.Millisecond)
+	}
+	is.Equal(ev.reason("k1"), cache.EvictExpired)
+
+	fastCache := fast.NewFastCache(0, 0, 4, ev.onEvicted, cache.WithClock(clock.Now), cache.WithCleanupInterval(time.Millisecond))
+	defer fastCache.Close()
+
+	fastCache.SetWithTTL("k2", "v2", time.Second)
+	clock.Add(time.Second)
+	for ev.reason("k2") == 0 && time.Now().Before(deadline.Add(time.Second)) {
+		time.Sleep(time.Millisecond)
+	}
+	is.Equal(ev.reason("k2"), cache.EvictExpired)
+	is.Equal(fastCache.Len(), 0)
+}
@@ -0,0 +1,135 @@
+package tests
+
+import (
+	"bytes"
+	"cache"
+	"cache/fifo"
+	"cache/lfu"
+	"cache/lru"
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+
+	"github.com/matryer/is"
+)
+
+type point struct {
+	X, Y int
+}
+
+type newTypedCacheFunc func(maxBytes int, sizer cache.Sizer[*point], onEvicted cache.TypedOnEvicted[int, *point]) cache.TypedCache[int, *point]
+
+var typedPolicies = map[string]newTypedCacheFunc{
+	"lru": func(maxBytes int, sizer cache.Sizer[*point], onEvicted cache.TypedOnEvicted[int, *point]) cache.TypedCache[int, *point] {
+		return lru.NewCache(maxBytes, sizer, onEvicted)
+	},
+	"lfu": func(maxBytes int, sizer cache.Sizer[*point], onEvicted cache.TypedOnEvicted[int, *point]) cache.TypedCache[int, *point] {
+		return lfu.NewCache(maxBytes, sizer, onEvicted)
+	},
+	"fifo": func(maxBytes int, sizer cache.Sizer[*point], onEvicted cache.TypedOnEvicted[int, *point]) cache.TypedCache[int, *point] {
+		return fifo.NewCache(maxBytes, sizer, onEvicted)
+	},
+}
+
+func pointSize(p *point) int {
+	return 16
+}
+
+// typedEntryBytes 返回 newCache 创建的缓存中保存 key 和 value 的一个 entry 占用的字节数，包括 key 和 entry 本身的开销
+func typedEntryBytes(newCache newTypedCacheFunc, key int, value *point) int {
+	c := newCache(0, pointSize, nil)
+	c.Set(key, value)
+	return c.(cache.MemoryUsage).UsedBytes()
+}
+
+func TestTypedCache(t *testing.T) {
+	for name, newCache := range typedPolicies {
+		t.Run(name, func(t *testing.T) {
+			is := is.New(t)
+
+			var evicted []int
+			c := newCache(2*typedEntryBytes(newCache, 1, nil), pointSize, func(key int, value *point, reason cache.EvictReason) {
+				evicted = append(evicted, key)
+			})
+
+			// 可以存放 nil，并且和未命中区分开
+			c.Set(1, nil)
+			v, ok := c.Get(1)
+			is.True(ok)
+			is.Equal(v, nil)
+			_, ok = c.Get(2)
+			is.True(!ok)
+
+			c.Set(2, &point{1, 2})
+			v, ok = c.Get(2)
+			is.True(ok)
+			is.Equal(*v, point{1, 2})
+
+			// 按 Sizer 计算容量，第三个 entry 会淘汰一个
+			c.Set(3, &point{3, 4})
+			is.Equal(c.Len(), 2)
+			is.Equal(len(evicted), 1)
+		})
+	}
+}
+
+func TestTypedSnapshot(t *testing.T) {
+	is := is.New(t)
+
+	c := lru.NewCache[int, string](0, func(s string) int { return len(s) }, nil)
+	c.Set(1, "one")
+	c.Set(2, "two")
+	c.Get(1)
+
+	var buf bytes.Buffer
+	is.NoErr(c.Snapshot(&buf))
+	data := buf.Bytes()
+
+	// 容量刚好可以存放 2 个 entry
+	restored := lru.NewCache[int, string](c.UsedBytes(), func(s string) int { return len(s) }, nil)
+	is.NoErr(restored.Restore(bytes.NewReader(data)))
+	restored.Set(3, "333")
+	// 2 最久未使用
+	_, ok := restored.Get(2)
+	is.True(!ok)
+	v, ok := restored.Get(1)
+	is.True(ok)
+	is.Equal(v, "one")
+
+	// key 的类型不匹配
+	mismatched := lru.NewCache[string, string](0, nil, nil)
+	is.True(mismatched.Restore(bytes.NewReader(data)) != nil)
+}
+
+func TestTypedTourCache(t *testing.T) {
+	is := is.New(t)
+
+	var calls int32
+	loader := cache.TypedLoaderFunc[int, *point](func(ctx context.Context, key int) (*point, error) {
+		atomic.AddInt32(&calls, 1)
+		if key < 0 {
+			return nil, cache.ErrNotFound
+		}
+		return &point{key, key}, nil
+	})
+	tourCache := cache.NewTypedTourCache[int, *point](loader, lru.NewCache[int, *point](0, pointSize, nil))
+	defer tourCache.Close()
+
+	v, ok := tourCache.Get(1)
+	is.True(ok)
+	is.Equal(*v, point{1, 1})
+	v, ok = tourCache.Get(1)
+	is.True(ok)
+	is.Equal(*v, point{1, 1})
+	is.Equal(atomic.LoadInt32(&calls), int32(1))
+
+	_, err := tourCache.GetContext(context.Background(), -1)
+	is.True(errors.Is(err, cache.ErrNotFound))
+
+	tourCache.Set(2, nil)
+	v, ok = tourCache.Get(2)
+	is.True(ok)
+	is.Equal(v, nil)
+	is.Equal(atomic.LoadInt32(&calls), int32(2))
+}
@@ -0,0 +1,161 @@
+package tinylfu
+
+// cmDepth 是 count-min sketch 的行数，估计值取各行计数的最小值
+const cmDepth = 4
+
+// cmSketch 是一个 4 位计数器的 count-min sketch，用于估计 key 的访问频率。
+// 计数器最大为 15，每一个 uint64 存放 16 个计数器。
+type cmSketch struct {
+	rows [cmDepth][]uint64
+	// 每行计数器个数减一，计数器个数是 2 的幂
+	mask uint64
+}
+
+func newCmSketch(counters int) *cmSketch {
+	counters = nextPowerOfTwo(counters)
+	s := &cmSketch{mask: uint64(counters - 1)}
+	for i := range s.rows {
+		// 最少一个 uint64
+		s.rows[i] = make([]uint64, (counters+15)/16)
+	}
+	return s
+}
+
+// increment 将 hash 对应的计数器加一，已达到 15 的不再增加
+func (s *cmSketch) increment(hash uint64) {
+	for i := range s.rows {
+		idx := s.index(hash, i)
+		word, shift := idx/16, (idx%16)*4
+		if (s.rows[i][word]>>shift)&0xf < 15 {
+			s.rows[i][word] += 1 << shift
+		}
+	}
+}
+
+// estimate 返回 hash 对应的访问频率估计值
+func (s *cmSketch) estimate(hash uint64) int {
+	min := uint64(15)
+	for i := range s.rows {
+		idx := s.index(hash, i)
+		if v := (s.rows[i][idx/16] >> ((idx % 16) * 4)) & 0xf; v < min {
+			min = v
+		}
+	}
+	return int(min)
+}
+
+// reset 将所有计数器减半，让过去的热点数据逐渐老化
+func (s *cmSketch) reset() {
+	for _, row := range s.rows {
+		for j := range row {
+			row[j] = (row[j] >> 1) & 0x7777777777777777
+		}
+	}
+}
+
+// index 使用 double hashing 计算第 i 行的计数器下标
+func (s *cmSketch) index(hash uint64, i int) uint64 {
+	h1, h2 := hash&0xffffffff, hash>>32
+	return (h1 + uint64(i)*h2) & s.mask
+}
+
+// doorkeeper 是一个布隆过滤器，只出现过一次的 key 记录在这里，不占用 sketch 的计数器
+type doorkeeper struct {
+	bits []uint64
+	mask uint64
+}
+
+// doorkeeper 使用的哈希函数个数
+const doorkeeperHashes = 3
+
+func newDoorkeeper(bits int) *doorkeeper {
+	bits = nextPowerOfTwo(bits)
+	return &doorkeeper{
+		bits: make([]uint64, (bits+63)/64),
+		mask: uint64(bits - 1),
+	}
+}
+
+// allow 记录 hash，返回之前是否已经存在
+func (d *doorkeeper) allow(hash uint64) bool {
+	h1, h2 := hash&0xffffffff, hash>>32
+	exists := true
+	for i := uint64(0); i < doorkeeperHashes; i++ {
+		idx := (h1 + i*h2) & d.mask
+		if d.bits[idx/64]&(1<<(idx%64)) == 0 {
+			exists = false
+			d.bits[idx/64] |= 1 << (idx % 64)
+		}
+	}
+	return exists
+}
+
+func (d *doorkeeper) contains(hash uint64) bool {
+	h1, h2 := hash&0xffffffff, hash>>32
+	for i := uint64(0); i < doorkeeperHashes; i++ {
+		idx := (h1 + i*h2) & d.mask
+		if d.bits[idx/64]&(1<<(idx%64)) == 0 {
+			return false
+		}
+	}
+	return true
+}
+
+func (d *doorkeeper) reset() {
+	for i := range d.bits {
+		d.bits[i] = 0
+	}
+}
+
+// frequency 组合 doorkeeper 和 count-min sketch 估计 key 最近的访问频率。
+// 每记录 sampleSize 次访问，所有计数器减半并清空 doorkeeper。
+type frequency struct {
+	sketch     *cmSketch
+	doorkeeper *doorkeeper
+	sampleSize int
+	additions  int
+}
+
+// 每个 key 在 sketch 中的样本数
+const samplesPerKey = 10
+
+// newFrequency 创建能够较准确估计 capacity 个 key 的访问频率的 frequency
+func newFrequency(capacity int) *frequency {
+	if capacity < 16 {
+		capacity = 16
+	}
+	return &frequency{
+		sketch:     newCmSketch(capacity),
+		doorkeeper: newDoorkeeper(capacity * 8),
+		sampleSize: capacity * samplesPerKey,
+	}
+}
+
+// record 记录一次访问，第一次出现的 key 只记录到 doorkeeper
+func (f *frequency) record(hash uint64) {
+	f.additions++
+	if f.doorkeeper.allow(hash) {
+		f.sketch.increment(hash)
+	}
+	if f.additions >= f.sampleSize {
+		f.sketch.reset()
+		f.doorkeeper.reset()
+		f.additions /= 2
+	}
+}
+
+func (f *frequency) estimate(hash uint64) int {
+	n := f.sketch.estimate(hash)
+	if f.doorkeeper.contains(hash) {
+		n++
+	}
+	return n
+}
+
+func nextPowerOfTwo(n int) int {
+	p := 1
+	for p < n {
+		p <<= 1
+	}
+	return p
+}
@@ -0,0 +1,60 @@
+package tinylfu
+
+import (
+	"strconv"
+	"testing"
+
+	"github.com/matryer/is"
+)
+
+func TestSketch(t *testing.T) {
+	is := is.New(t)
+
+	s := newCmSketch(64)
+	h := hash("k1")
+	is.Equal(s.estimate(h), 0)
+	for i := 0; i < 5; i++ {
+		s.increment(h)
+	}
+	is.Equal(s.estimate(h), 5)
+
+	// 计数器最大为 15
+	for i := 0; i < 20; i++ {
+		s.increment(h)
+	}
+	is.Equal(s.estimate(h), 15)
+
+	s.reset()
+	is.Equal(s.estimate(h), 7)
+}
+
+func TestDoorkeeper(t *testing.T) {
+	is := is.New(t)
+
+	d := newDoorkeeper(1024)
+	h := hash("k1")
+	is.True(!d.contains(h))
+	is.True(!d.allow(h))
+	is.True(d.contains(h))
+	is.True(d.allow(h))
+
+	d.reset()
+	is.True(!d.contains(h))
+}
+
+func TestFrequencyAging(t *testing.T) {
+	is := is.New(t)
+
+	f := newFrequency(16)
+	hot := hash("hot")
+	for i := 0; i < 10; i++ {
+		f.record(hot)
+	}
+	is.Equal(f.estimate(hot), 10)
+
+	// 记录 sampleSize 次访问后计数器减半，doorkeeper 被清空
+	for i := 0; i < f.sampleSize; i++ {
+		f.record(hash("cold" + strconv.Itoa(i)))
+	}
+	is.True(f.estimate(hot) <= 5)
+}
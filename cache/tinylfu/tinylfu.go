@@ -0,0 +1,330 @@
+package tinylfu
+
+import (
+	"cache"
+	"cache/fast"
+	"container/list"
+	"io"
+	"time"
+	"unsafe"
+)
+
+const (
+	// 默认 window 占容量的比例
+	defaultWindowRatio = 0.01
+	// 默认 protected 占 main 的比例
+	defaultProtectedRatio = 0.8
+	// 根据容量估算 entry 个数时假设的值的平均大小，单位字节
+	defaultValueBytes = 64
+	// 没有容量限制且没有设置 ExpectedEntries 时 sketch 对应的 entry 个数
+	defaultExpectedEntries = 4096
+)
+
+// tinyLFU 是一个 W-TinyLFU cache。它不是并发安全的。
+//
+// 新加入的 entry 先放入一个小的 LRU 队列 window，从 window 淘汰的 entry 作为候选者，
+// 和 main 区域中将被淘汰的 entry 比较最近的访问频率，频率更高的留下。
+// main 是一个分段 LRU：probation 中的 entry 再次被访问时升级到 protected，
+// protected 超出上限时最久未使用的降级回 probation。
+// 访问频率由 count-min sketch 估计，计数器会定期减半，过去的热点数据会逐渐老化。
+// 每次访问只需要 O(1) 的时间。容量按字节计算。
+type tinyLFU struct {
+	// 缓存最大的容量，单位字节
+	maxBytes int
+	// 当一个 entry 从缓存中移除时调用该回调函数，默认为 nil
+	onEvicted cache.OnEvicted
+
+	opts cache.Options
+
+	// window 和 protected 的大小上限，单位字节
+	windowBytes, protectedBytes int
+
+	window, probation, protected *segment
+	cache                        map[string]*list.Element
+
+	freq *frequency
+}
+
+type entry struct {
+	key   string
+	value interface{}
+	// 过期时间，零值表示永不过期
+	expireAt time.Time
+	// entry 占用的字节数，包括 key 和 entryOverhead
+	size int
+	hash uint64
+	seg  *segment
+}
+
+// entryOverhead 每个 entry 除了 key 和值以外的开销：entry 结构体和链表节点
+const entryOverhead = int(unsafe.Sizeof(entry{})) + cache.ListElementSize
+
+// entrySize 计算一个 entry 占用的字节数，包括 key、值以及 entryOverhead
+func entrySize(key string, value interface{}) int {
+	return cache.CalcLen(value) + cache.KeySize(key) + entryOverhead
+}
+
+// segment 是一个带字节数统计的链表，Front 为最久未使用的 entry
+type segment struct {
+	ll    *list.List
+	bytes int
+}
+
+func newSegment() *segment {
+	return &segment{ll: list.New()}
+}
+
+func (s *segment) pushBack(et *entry) *list.Element {
+	et.seg = s
+	s.bytes += et.size
+	return s.ll.PushBack(et)
+}
+
+func (s *segment) remove(e *list.Element) *entry {
+	et := s.ll.Remove(e).(*entry)
+	s.bytes -= et.size
+	return et
+}
+
+// New 创建一个新的 Cache，如果 maxBytes 是 0，表示没有容量限制。
+// sketch 只在创建时分配一次，大小由 cache.WithExpectedEntries 指定，没有指定时根据 maxBytes 估算
+func New(maxBytes int, onEvicted cache.OnEvicted, opts ...cache.Option) cache.Cache {
+	windowBytes := int(float64(maxBytes) * defaultWindowRatio)
+	o := cache.NewOptions(opts...)
+	expected := o.ExpectedEntries
+	if expected <= 0 {
+		expected = expectedEntries(maxBytes)
+	}
+	return &tinyLFU{
+		maxBytes:       maxBytes,
+		onEvicted:      cache.ObserveEvicted(o.Metrics, onEvicted),
+		opts:           o,
+		windowBytes:    windowBytes,
+		protectedBytes: int(float64(maxBytes-windowBytes) * defaultProtectedRatio),
+		window:         newSegment(),
+		probation:      newSegment(),
+		protected:      newSegment(),
+		cache:          make(map[string]*list.Element),
+		freq:           newFrequency(expected),
+	}
+}
+
+// Set 往 Cache 增加一个元素，过期时间为默认的 TTL
+func (t *tinyLFU) Set(key string, value interface{}) {
+	t.SetWithTTL(key, value, t.opts.DefaultTTL)
+}
+
+// SetWithTTL 同 Set，ttl 小于等于 0 表示永不过期。
+// 已存在的 entry 原地更新并视为一次访问；新的 entry 放入 window，
+// 从 window 淘汰的 entry 需要和 main 中的 entry 比较访问频率才能进入 main
+func (t *tinyLFU) SetWithTTL(key string, value interface{}, ttl time.Duration) {
+	expireAt := t.opts.ExpireAt(ttl)
+	if e, ok := t.cache[key]; ok {
+		et := e.Value.(*entry)
+		size := entrySize(key, value)
+		et.seg.bytes += size - et.size
+		et.size = size
+		et.value = value
+		et.expireAt = expireAt
+		t.freq.record(et.hash)
+		t.onAccess(e)
+	} else {
+		et := &entry{key: key, value: value, expireAt: expireAt, size: entrySize(key, value), hash: hash(key)}
+		t.freq.record(et.hash)
+		t.cache[key] = t.window.pushBack(et)
+
+		for t.maxBytes > 0 && t.window.bytes > t.windowBytes {
+			t.admit(t.window.ll.Front())
+		}
+	}
+
+	for t.maxBytes > 0 && t.UsedBytes() > t.maxBytes {
+		t.DelOldest()
+	}
+}
+
+// Get 从 cache 中获取 key 对应的值，nil 表示 key 不存在或已过期
+func (t *tinyLFU) Get(key string) interface{} {
+	e, ok := t.cache[key]
+	if !ok {
+		return nil
+	}
+
+	et := e.Value.(*entry)
+	if t.opts.Expired(et.expireAt) {
+		t.removeElement(e, cache.EvictExpired)
+		return nil
+	}
+
+	t.freq.record(et.hash)
+	t.onAccess(e)
+	return et.value
+}
+
+// Contains 判断 key 是否存在且未过期，不会记录访问频率，也不会调整所在的队列
+func (t *tinyLFU) Contains(key string) bool {
+	e, ok := t.cache[key]
+	return ok && !t.opts.Expired(e.Value.(*entry).expireAt)
+}
+
+// Del 从 cache 中删除 key 对应的元素
+func (t *tinyLFU) Del(key string) {
+	if e, ok := t.cache[key]; ok {
+		t.removeElement(e, cache.EvictDeleted)
+	}
+}
+
+// DelOldest 淘汰一条记录，依次选择 probation、window、protected 中最久未使用的
+func (t *tinyLFU) DelOldest() {
+	for _, seg := range []*segment{t.probation, t.window, t.protected} {
+		if e := seg.ll.Front(); e != nil {
+			t.removeElement(e, cache.EvictCapacity)
+			return
+		}
+	}
+}
+
+// DeleteExpired 删除所有已过期的记录，返回删除的个数
+func (t *tinyLFU) DeleteExpired() int {
+	n := 0
+	for _, seg := range []*segment{t.window, t.probation, t.protected} {
+		for e := seg.ll.Front(); e != nil; {
+			next := e.Next()
+			if t.opts.Expired(e.Value.(*entry).expireAt) {
+				t.removeElement(e, cache.EvictExpired)
+				n++
+			}
+			e = next
+		}
+	}
+
+	return n
+}
+
+// Len 返回当前 cache 中的记录数
+func (t *tinyLFU) Len() int {
+	return len(t.cache)
+}
+
+// onAccess 调整被访问的 entry 的位置：probation 中的升级到 protected，其他的移到尾部
+func (t *tinyLFU) onAccess(e *list.Element) {
+	et := e.Value.(*entry)
+	if et.seg != t.probation || t.maxBytes == 0 {
+		et.seg.ll.MoveToBack(e)
+		return
+	}
+
+	t.probation.remove(e)
+	t.cache[et.key] = t.protected.pushBack(et)
+	for t.protected.bytes > t.protectedBytes && t.protected.ll.Len() > 1 {
+		demoted := t.protected.remove(t.protected.ll.Front())
+		t.cache[demoted.key] = t.probation.pushBack(demoted)
+	}
+}
+
+// admit 将 window 中的 e 移入 main。空间不足时和 main 中将被淘汰的 entry 比较访问频率，
+// 候选者频率更高时淘汰对方，否则淘汰候选者
+func (t *tinyLFU) admit(e *list.Element) {
+	candidate := t.window.remove(e)
+	for t.UsedBytes()+candidate.size > t.maxBytes {
+		victim := t.probation.ll.Front()
+		if victim == nil {
+			victim = t.protected.ll.Front()
+		}
+		if victim == nil {
+			break
+		}
+
+		if t.freq.estimate(candidate.hash) <= t.freq.estimate(victim.Value.(*entry).hash) {
+			delete(t.cache, candidate.key)
+			if t.onEvicted != nil {
+				t.onEvicted(candidate.key, candidate.value, cache.EvictCapacity)
+			}
+			return
+		}
+		t.removeElement(victim, cache.EvictCapacity)
+	}
+
+	t.cache[candidate.key] = t.probation.pushBack(candidate)
+}
+
+// expectedEntries 根据容量估算最多存放的 entry 个数，假设每个值占用 defaultValueBytes
+func expectedEntries(maxBytes int) int {
+	if maxBytes <= 0 {
+		return defaultExpectedEntries
+	}
+	return maxBytes / (defaultValueBytes + entryOverhead)
+}
+
+// Snapshot 依次保存 window、probation、protected 中所有未过期的记录，Weight 为 1、2、3 分别表示所在的队列；
+// sketch 中的访问频率不会保存
+func (t *tinyLFU) Snapshot(w io.Writer) error {
+	entries := make([]cache.SnapshotEntry, 0, t.Len())
+	for weight, seg := range []*segment{t.window, t.probation, t.protected} {
+		for e := seg.ll.Front(); e != nil; e = e.Next() {
+			et := e.Value.(*entry)
+			if !t.opts.Expired(et.expireAt) {
+				entries = append(entries, cache.SnapshotEntry{Key: et.key, Value: et.value, ExpireAt: et.expireAt, Weight: weight + 1})
+			}
+		}
+	}
+	return cache.WriteSnapshot(w, t.opts.Codec, entries)
+}
+
+// Restore 按快照中的顺序将记录放回原来的队列，每条记录计为一次访问，容量不足时淘汰最久未使用的
+func (t *tinyLFU) Restore(r io.Reader) error {
+	entries, err := cache.ReadSnapshot(r, t.opts.Codec)
+	if err != nil {
+		return err
+	}
+	segs := []*segment{t.window, t.probation, t.protected}
+	for _, se := range entries {
+		key, value, err := cache.Unpack[string, interface{}](se)
+		if err != nil {
+			return err
+		}
+		if t.opts.Expired(se.ExpireAt) {
+			continue
+		}
+		if e, ok := t.cache[key]; ok {
+			e.Value.(*entry).seg.remove(e)
+		}
+		seg := t.window
+		if se.Weight >= 1 && se.Weight <= len(segs) {
+			seg = segs[se.Weight-1]
+		}
+		et := &entry{key: key, value: value, expireAt: se.ExpireAt, size: entrySize(key, value), hash: hash(key)}
+		t.freq.record(et.hash)
+		t.cache[key] = seg.pushBack(et)
+		for t.maxBytes > 0 && t.UsedBytes() > t.maxBytes {
+			t.DelOldest()
+		}
+	}
+	return nil
+}
+
+// UsedBytes 实现 cache.MemoryUsage
+func (t *tinyLFU) UsedBytes() int {
+	return t.window.bytes + t.probation.bytes + t.protected.bytes
+}
+
+func (t *tinyLFU) removeElement(e *list.Element, reason cache.EvictReason) {
+	et := e.Value.(*entry)
+	et.seg.remove(e)
+	delete(t.cache, et.key)
+
+	if t.onEvicted != nil {
+		t.onEvicted(et.key, et.value, reason)
+	}
+}
+
+// hash 计算 key 的哈希值，FNV-1a 的高低位分布不够均匀，用 murmur3 的 fmix64 打散
+func hash(key string) uint64 {
+	h := fast.Sum64(key)
+	h ^= h >> 33
+	h *= 0xff51afd7ed558ccd
+	h ^= h >> 33
+	h *= 0xc4ceb9fe1a85ec53
+	h ^= h >> 33
+	return h
+}
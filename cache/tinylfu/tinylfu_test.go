@@ -0,0 +1,106 @@
+package tinylfu
+
+import (
+	"cache"
+	"strconv"
+	"testing"
+
+	"github.com/matryer/is"
+)
+
+// entryBytes 值为 int32、key 长度为 2 的 entry 占用的字节数
+var entryBytes = entrySize("k1", int32(0))
+
+func TestSet(t *testing.T) {
+	is := is.New(t)
+
+	cache := New(0, nil)
+	cache.DelOldest()
+	cache.Set("k1", 1)
+	v := cache.Get("k1")
+	is.Equal(v, 1)
+
+	cache.Del("k1")
+	is.Equal(0, cache.Len())
+
+	cache.Set("k2", int32(2))
+	cache.Set("k2", int32(3))
+	is.Equal(cache.Get("k2"), int32(3))
+	is.Equal(1, cache.Len())
+}
+
+func TestOnEvicted(t *testing.T) {
+	is := is.New(t)
+
+	keys := make([]string, 0, 8)
+	onEvicted := func(key string, value interface{}, reason cache.EvictReason) {
+		keys = append(keys, key)
+	}
+	cache := New(2*entryBytes, onEvicted)
+
+	cache.Set("k1", int32(1))
+	cache.Set("k2", int32(2))
+	// k3 的访问频率不高于 k1，不会被接纳
+	cache.Set("k3", int32(3))
+	is.Equal(cache.Get("k3"), nil)
+
+	// k4 多次访问后频率高于 k1，淘汰 k1
+	cache.Set("k4", int32(4))
+	cache.Set("k4", int32(4))
+	cache.Set("k4", int32(4))
+
+	expected := []string{"k3", "k4", "k1"}
+
+	is.Equal(expected, keys)
+	is.Equal(2, cache.Len())
+	is.Equal(cache.Get("k4"), int32(4))
+}
+
+func TestSketchSize(t *testing.T) {
+	is := is.New(t)
+
+	// sketch 只在创建时分配一次，entry 增多时之前记录的频率不会丢失
+	c := New(0, nil).(*tinyLFU)
+	freq := c.freq
+	c.Set("hot", int32(1))
+	for i := 0; i < 4; i++ {
+		c.Get("hot")
+	}
+	for i := 0; i < 100; i++ {
+		c.Set("k"+strconv.Itoa(i), int32(i))
+	}
+	is.True(c.freq == freq)
+	is.True(c.freq.estimate(hash("hot")) >= 5)
+
+	is.Equal(New(0, nil, cache.WithExpectedEntries(1000)).(*tinyLFU).freq.sampleSize, 1000*samplesPerKey)
+	is.Equal(New(100*(defaultValueBytes+entryOverhead), nil).(*tinyLFU).freq.sampleSize, 100*samplesPerKey)
+}
+
+func TestAdmission(t *testing.T) {
+	is := is.New(t)
+
+	c := New(100*entryBytes, nil)
+	hot := make([]string, 50)
+	for i := range hot {
+		hot[i] = "hot" + strconv.Itoa(i)
+	}
+	for i := 0; i < 5; i++ {
+		for _, key := range hot {
+			if c.Get(key) == nil {
+				c.Set(key, int32(1))
+			}
+		}
+	}
+
+	// 只访问一次的 key 频率低，不会挤掉热点数据
+	for i := 0; i < 1000; i++ {
+		c.Set("cold"+strconv.Itoa(i), int32(1))
+	}
+	n := 0
+	for _, key := range hot {
+		if c.Get(key) != nil {
+			n++
+		}
+	}
+	is.True(n >= len(hot)*9/10)
+}
@@ -1,5 +1,18 @@
 package cache
 
+import (
+	"cache/singleflight"
+	"context"
+	"errors"
+	"io"
+	"log"
+	"os"
+	"time"
+)
+
+// ErrNotFound 表示要加载的 key 不存在，Loader 返回该错误时，结果会按 NegativeTTL 缓存
+var ErrNotFound = errors.New("cache: key not found")
+
 type Getter interface {
 	Get(key string) interface{}
 }
@@ -10,43 +23,288 @@ func (f GetFunc) Get(key string) interface{} {
 	return f(key)
 }
 
-type TourCache struct {
-	mainCache *safeCache
-	getter    Getter
+// Loader 带 context 和错误返回的加载器，用于区分 key 不存在（ErrNotFound）和加载失败
+type Loader interface {
+	Load(ctx context.Context, key string) (interface{}, error)
 }
 
-func NewTourCache(getter Getter, cache Cache) *TourCache {
-	return &TourCache{
-		mainCache: newSafeCache(cache),
-		getter:    getter,
+type LoaderFunc func(ctx context.Context, key string) (interface{}, error)
+
+func (f LoaderFunc) Load(ctx context.Context, key string) (interface{}, error) {
+	return f(ctx, key)
+}
+
+// Get 实现 Getter，出错时返回 nil
+func (f LoaderFunc) Get(key string) interface{} {
+	val, _ := f(context.Background(), key)
+	return val
+}
+
+// getterLoader 将 Getter 适配为 Loader，返回 nil 视为 ErrNotFound
+type getterLoader struct {
+	getter Getter
+}
+
+func (g getterLoader) Load(ctx context.Context, key string) (interface{}, error) {
+	if val := g.getter.Get(key); val != nil {
+		return val, nil
 	}
+	return nil, ErrNotFound
 }
 
-func (t *TourCache) Get(key string) interface{} {
-	val := t.mainCache.get(key)
-	if val != nil {
-		return val
+// nilNotFound 将 Loader 返回的 nil 视为 ErrNotFound
+type nilNotFound struct {
+	loader Loader
+}
+
+func (n nilNotFound) Load(ctx context.Context, key string) (interface{}, error) {
+	val, err := n.loader.Load(ctx, key)
+	if err == nil && val == nil {
+		err = ErrNotFound
+	}
+	return val, err
+}
+
+// TypedLoader 泛型版本的 Loader
+type TypedLoader[K comparable, V any] interface {
+	Load(ctx context.Context, key K) (V, error)
+}
+
+type TypedLoaderFunc[K comparable, V any] func(ctx context.Context, key K) (V, error)
+
+func (f TypedLoaderFunc[K, V]) Load(ctx context.Context, key K) (V, error) {
+	return f(ctx, key)
+}
+
+// TypedTourCache 泛型版本的 TourCache
+type TypedTourCache[K comparable, V any] struct {
+	mainCache *safeCache[K, V]
+	loader    TypedLoader[K, V]
+	// 合并同一个 key 的并发加载，避免缓存失效时大量请求同时打到 loader
+	loadGroup   singleflight.TypedGroup[K, V]
+	negativeTTL time.Duration
+	metrics     *Metrics
+
+	// 快照文件的路径，为空表示不使用快照
+	snapshotPath string
+
+	stopJanitor, stopSnapshot func()
+}
+
+// NewTypedTourCache 创建一个并发安全的 TypedTourCache，loader 为 nil 时只能通过 Set 写入；
+// opts 中的 CleanupInterval 用于启动后台清理过期 entry，NegativeTTL、MaxNotFound 用于缓存不存在的 key，
+// SnapshotPath 不为空时从快照文件恢复，避免重启后大量请求同时打到 loader，
+// 并按 SnapshotInterval 定期写入快照，cache 需要实现 Snapshotter；Metrics 不为 nil 时记录命中、写入、加载等指标
+func NewTypedTourCache[K comparable, V any](loader TypedLoader[K, V], cache TypedCache[K, V], opts ...Option) *TypedTourCache[K, V] {
+	o := NewOptions(opts...)
+	t := &TypedTourCache[K, V]{
+		mainCache:    newSafeCache(cache, o),
+		metrics:      o.Metrics,
+		loader:       loader,
+		negativeTTL:  o.NegativeTTL,
+		snapshotPath: o.SnapshotPath,
+		stopSnapshot: func() {},
+	}
+	t.stopJanitor = StartJanitor(o.CleanupInterval, t.mainCache.deleteExpired)
+	if _, ok := t.mainCache.usedBytes(); ok {
+		t.metrics.observe(t.mainCache.len, func() int {
+			n, _ := t.mainCache.usedBytes()
+			return n
+		})
+	} else {
+		t.metrics.observe(t.mainCache.len, nil)
 	}
 
-	if t.getter != nil {
-		val = t.getter.Get(key)
-		if val == nil {
-			return nil
+	if t.snapshotPath != "" {
+		if err := LoadSnapshot(t.snapshotPath, t); err != nil && !os.IsNotExist(err) {
+			log.Printf("[TourCache] restore snapshot %s: %v", t.snapshotPath, err)
 		}
-		t.mainCache.set(key, val)
-		return val
+		t.stopSnapshot = StartJanitor(o.SnapshotInterval, t.saveSnapshot)
+	}
+
+	return t
+}
+
+// Get 从缓存中获取 key 对应的值，未命中时通过 loader 加载并写入缓存，
+// 同一个 key 的并发未命中只会调用一次 loader；不存在或加载失败时 ok 为 false
+func (t *TypedTourCache[K, V]) Get(key K) (value V, ok bool) {
+	value, err := t.GetContext(context.Background(), key)
+	return value, err == nil
+}
+
+// GetContext 同 Get，key 不存在时返回 ErrNotFound，加载失败时返回 Loader 的错误，该错误不会被缓存；
+// ctx 取消时等待加载的调用者立即返回 ctx.Err()，加载本身会继续完成并写入缓存
+func (t *TypedTourCache[K, V]) GetContext(ctx context.Context, key K) (V, error) {
+	if val, ok, err := t.mainCache.get(key); ok {
+		return val, err
 	}
 
+	if t.loader == nil {
+		var zero V
+		return zero, ErrNotFound
+	}
+
+	loadCtx := detach(ctx)
+	return t.loadGroup.DoContext(ctx, key, func() (V, error) {
+		// 可能在等待的过程中已经被其他调用者加载
+		if val, ok, err := t.mainCache.peek(key); ok {
+			return val, err
+		}
+
+		start := time.Now()
+		val, err := t.loader.Load(loadCtx, key)
+		t.metrics.loaded(time.Since(start), err)
+		switch {
+		case err == nil:
+			t.mainCache.set(key, val)
+		case errors.Is(err, ErrNotFound):
+			if t.negativeTTL > 0 {
+				t.mainCache.setNotFound(key, t.negativeTTL)
+			}
+		}
+		return val, err
+	})
+}
+
+// detachedContext 保留 ctx 中的值，但不会被取消，加载由多个调用者共享，不能因为其中一个取消而中断
+type detachedContext struct {
+	context.Context
+}
+
+func detach(ctx context.Context) context.Context {
+	return detachedContext{ctx}
+}
+
+func (detachedContext) Deadline() (time.Time, bool) {
+	return time.Time{}, false
+}
+
+func (detachedContext) Done() <-chan struct{} {
+	return nil
+}
+
+func (detachedContext) Err() error {
 	return nil
 }
 
+func (t *TypedTourCache[K, V]) Set(key K, val V) {
+	t.mainCache.set(key, val)
+}
+
+// SetWithTTL 写入 entry 并指定过期时间，ttl 小于等于 0 表示永不过期
+func (t *TypedTourCache[K, V]) SetWithTTL(key K, val V, ttl time.Duration) {
+	t.mainCache.setWithTTL(key, val, ttl)
+}
+
+// Del 删除 key 对应的 entry，包括缓存的不存在的 key；返回删除前 key 是否存在且未过期
+func (t *TypedTourCache[K, V]) Del(key K) bool {
+	return t.mainCache.del(key)
+}
+
+// Len 返回缓存中的 entry 个数，包括已过期但还未删除的 entry，不包括缓存的不存在的 key
+func (t *TypedTourCache[K, V]) Len() int {
+	return t.mainCache.len()
+}
+
+// Snapshot 实现 Snapshotter，写入快照期间会阻塞其他操作；缓存的不存在的 key 不会写入
+func (t *TypedTourCache[K, V]) Snapshot(w io.Writer) error {
+	return t.mainCache.snapshot(w)
+}
+
+// Restore 实现 Snapshotter
+func (t *TypedTourCache[K, V]) Restore(r io.Reader) error {
+	return t.mainCache.restore(r)
+}
+
+func (t *TypedTourCache[K, V]) saveSnapshot() {
+	if err := SaveSnapshot(t.snapshotPath, t); err != nil {
+		log.Printf("[TourCache] save snapshot %s: %v", t.snapshotPath, err)
+	}
+}
+
+// Close 停止后台清理和定期快照，设置了 SnapshotPath 时写入最后一次快照
+func (t *TypedTourCache[K, V]) Close() {
+	t.stopJanitor()
+	t.stopSnapshot()
+	if t.snapshotPath != "" {
+		t.saveSnapshot()
+	}
+}
+
+func (t *TypedTourCache[K, V]) Stat() *Stat {
+	return t.mainCache.stat()
+}
+
+// TourCache 是 key 为 string、值为 interface{} 的 TypedTourCache，值为 nil 表示不存在
+type TourCache struct {
+	typed *TypedTourCache[string, interface{}]
+}
+
+// NewTourCache 创建一个并发安全的 TourCache，getter 同时实现了 Loader（如 LoaderFunc）时使用 Load 加载；
+// opts 同 NewTypedTourCache
+func NewTourCache(getter Getter, cache Cache, opts ...Option) *TourCache {
+	var loader TypedLoader[string, interface{}]
+	if l, ok := getter.(Loader); ok {
+		loader = nilNotFound{l}
+	} else if getter != nil {
+		loader = getterLoader{getter}
+	}
+
+	return &TourCache{typed: NewTypedTourCache(loader, Typed(cache), opts...)}
+}
+
+// Get 从缓存中获取 key 对应的值，未命中时通过 getter 加载并写入缓存，
+// 同一个 key 的并发未命中只会调用一次 getter；不存在或加载失败时返回 nil
+func (t *TourCache) Get(key string) interface{} {
+	val, _ := t.GetContext(context.Background(), key)
+	return val
+}
+
+// GetContext 同 TypedTourCache.GetContext
+func (t *TourCache) GetContext(ctx context.Context, key string) (interface{}, error) {
+	return t.typed.GetContext(ctx, key)
+}
+
 func (t *TourCache) Set(key string, val interface{}) {
 	if val == nil {
 		return
 	}
-	t.mainCache.set(key, val)
+	t.typed.Set(key, val)
+}
+
+// SetWithTTL 写入 entry 并指定过期时间，ttl 小于等于 0 表示永不过期
+func (t *TourCache) SetWithTTL(key string, val interface{}, ttl time.Duration) {
+	if val == nil {
+		return
+	}
+	t.typed.SetWithTTL(key, val, ttl)
+}
+
+// Del 同 TypedTourCache.Del
+func (t *TourCache) Del(key string) bool {
+	return t.typed.Del(key)
+}
+
+// Len 同 TypedTourCache.Len
+func (t *TourCache) Len() int {
+	return t.typed.Len()
+}
+
+// Snapshot 实现 Snapshotter
+func (t *TourCache) Snapshot(w io.Writer) error {
+	return t.typed.Snapshot(w)
+}
+
+// Restore 实现 Snapshotter
+func (t *TourCache) Restore(r io.Reader) error {
+	return t.typed.Restore(r)
+}
+
+// Close 停止后台清理和定期快照，设置了 SnapshotPath 时写入最后一次快照
+func (t *TourCache) Close() {
+	t.typed.Close()
 }
 
 func (t *TourCache) Stat() *Stat {
-	return t.mainCache.stat()
+	return t.typed.Stat()
 }
@@ -0,0 +1,286 @@
+package twoq
+
+import (
+	"cache"
+	"container/list"
+	"io"
+	"time"
+	"unsafe"
+)
+
+const (
+	// 默认 a1in 占容量的比例
+	defaultInRatio = 0.25
+	// 默认 a1out 记录的 key 对应的值占容量的比例
+	defaultOutRatio = 0.5
+)
+
+// twoq 是一个 2Q cache。它不是并发安全的。
+//
+// 新加入的 entry 先放入 FIFO 队列 a1in，从 a1in 淘汰时只在 a1out 中记录 key；
+// 如果之后再次写入的 key 还在 a1out 中，说明它不是一次性访问，放入 LRU 队列 am。
+// 一次性的扫描只会冲刷 a1in，不会影响 am 中的热点数据。容量按字节计算。
+type twoq struct {
+	// 缓存最大的容量，单位字节
+	maxBytes int
+	// 当一个 entry 从缓存中移除时调用该回调函数，默认为 nil
+	onEvicted cache.OnEvicted
+
+	opts cache.Options
+
+	// a1in 和 a1out 的大小上限，单位字节
+	inBytes, outBytes int
+
+	a1in, a1out, am *segment
+	cache           map[string]*list.Element
+}
+
+type entry struct {
+	key   string
+	value interface{}
+	// 过期时间，零值表示永不过期
+	expireAt time.Time
+	// entry 占用的字节数，包括 key 和 entryOverhead，淘汰到 a1out 之后仍然保留
+	size int
+	seg  *segment
+}
+
+// entryOverhead 每个 entry 除了 key 和值以外的开销：entry 结构体和链表节点
+const entryOverhead = int(unsafe.Sizeof(entry{})) + cache.ListElementSize
+
+// entrySize 计算一个 entry 占用的字节数，包括 key、值以及 entryOverhead
+func entrySize(key string, value interface{}) int {
+	return cache.CalcLen(value) + cache.KeySize(key) + entryOverhead
+}
+
+// segment 是一个带字节数统计的链表，Front 为最旧的 entry
+type segment struct {
+	ll    *list.List
+	bytes int
+	// key 和 entryOverhead 占用的字节数，a1out 不保存值，实际只占用这部分内存
+	keyBytes int
+}
+
+func newSegment() *segment {
+	return &segment{ll: list.New()}
+}
+
+func (s *segment) pushBack(et *entry) *list.Element {
+	et.seg = s
+	s.bytes += et.size
+	s.keyBytes += cache.KeySize(et.key) + entryOverhead
+	return s.ll.PushBack(et)
+}
+
+func (s *segment) remove(e *list.Element) *entry {
+	et := s.ll.Remove(e).(*entry)
+	s.bytes -= et.size
+	s.keyBytes -= cache.KeySize(et.key) + entryOverhead
+	return et
+}
+
+// New 创建一个新的 Cache，如果 maxBytes 是 0，表示没有容量限制
+func New(maxBytes int, onEvicted cache.OnEvicted, opts ...cache.Option) cache.Cache {
+	o := cache.NewOptions(opts...)
+	return &twoq{
+		maxBytes:  maxBytes,
+		onEvicted: cache.ObserveEvicted(o.Metrics, onEvicted),
+		opts:      o,
+		inBytes:   int(float64(maxBytes) * defaultInRatio),
+		outBytes:  int(float64(maxBytes) * defaultOutRatio),
+		a1in:      newSegment(),
+		a1out:     newSegment(),
+		am:        newSegment(),
+		cache:     make(map[string]*list.Element),
+	}
+}
+
+// Set 往 Cache 增加一个元素，过期时间为默认的 TTL
+func (q *twoq) Set(key string, value interface{}) {
+	q.SetWithTTL(key, value, q.opts.DefaultTTL)
+}
+
+// SetWithTTL 同 Set，ttl 小于等于 0 表示永不过期。
+// 已存在的 entry 原地更新，a1in 中的位置不变，am 中的移到尾部；在 a1out 中的 key 放入 am；否则放入 a1in
+func (q *twoq) SetWithTTL(key string, value interface{}, ttl time.Duration) {
+	et := &entry{key: key, value: value, expireAt: q.opts.ExpireAt(ttl), size: entrySize(key, value)}
+
+	if e, ok := q.cache[key]; !ok {
+		q.cache[key] = q.a1in.pushBack(et)
+	} else if old := e.Value.(*entry); old.seg == q.a1out {
+		q.a1out.remove(e)
+		q.cache[key] = q.am.pushBack(et)
+	} else {
+		old.seg.bytes += et.size - old.size
+		old.value, old.expireAt, old.size = et.value, et.expireAt, et.size
+		if old.seg == q.am {
+			q.am.ll.MoveToBack(e)
+		}
+	}
+
+	for q.maxBytes > 0 && q.a1in.bytes+q.am.bytes > q.maxBytes {
+		q.DelOldest()
+	}
+}
+
+// Get 从 cache 中获取 key 对应的值，nil 表示 key 不存在或已过期，am 中命中的 entry 移到尾部
+func (q *twoq) Get(key string) interface{} {
+	e, ok := q.cache[key]
+	if !ok {
+		return nil
+	}
+
+	et := e.Value.(*entry)
+	if et.seg == q.a1out {
+		return nil
+	}
+	if q.opts.Expired(et.expireAt) {
+		q.removeElement(e, cache.EvictExpired)
+		return nil
+	}
+
+	if et.seg == q.am {
+		q.am.ll.MoveToBack(e)
+	}
+	return et.value
+}
+
+// Contains 判断 key 是否存在且未过期，不会调整 am 中的顺序；a1out 中的 key 视为不存在
+func (q *twoq) Contains(key string) bool {
+	e, ok := q.cache[key]
+	if !ok {
+		return false
+	}
+	et := e.Value.(*entry)
+	return et.seg != q.a1out && !q.opts.Expired(et.expireAt)
+}
+
+// Del 从 cache 中删除 key 对应的元素
+func (q *twoq) Del(key string) {
+	e, ok := q.cache[key]
+	if !ok {
+		return
+	}
+
+	et := e.Value.(*entry)
+	if et.seg == q.a1out {
+		q.a1out.remove(e)
+		delete(q.cache, key)
+		return
+	}
+	q.removeElement(e, cache.EvictDeleted)
+}
+
+// DelOldest 淘汰一条记录：a1in 超过上限或 am 为空时淘汰 a1in 中最旧的，并记录到 a1out；否则淘汰 am 中最久未使用的
+func (q *twoq) DelOldest() {
+	if q.a1in.ll.Len() > 0 && (q.a1in.bytes > q.inBytes || q.am.ll.Len() == 0) {
+		e := q.a1in.ll.Front()
+		et := q.a1in.remove(e)
+		value := et.value
+		et.value = nil
+		q.cache[et.key] = q.a1out.pushBack(et)
+		q.trimOut()
+
+		if q.onEvicted != nil {
+			q.onEvicted(et.key, value, cache.EvictCapacity)
+		}
+		return
+	}
+
+	if e := q.am.ll.Front(); e != nil {
+		q.removeElement(e, cache.EvictCapacity)
+	}
+}
+
+// DeleteExpired 删除所有已过期的记录，返回删除的个数
+func (q *twoq) DeleteExpired() int {
+	n := 0
+	for _, seg := range []*segment{q.a1in, q.am} {
+		for e := seg.ll.Front(); e != nil; {
+			next := e.Next()
+			if q.opts.Expired(e.Value.(*entry).expireAt) {
+				q.removeElement(e, cache.EvictExpired)
+				n++
+			}
+			e = next
+		}
+	}
+
+	return n
+}
+
+// Len 返回当前 cache 中的记录数，不包括 a1out 中的 key
+func (q *twoq) Len() int {
+	return q.a1in.ll.Len() + q.am.ll.Len()
+}
+
+// UsedBytes 实现 cache.MemoryUsage，包括 a1out 中的 key 和 entryOverhead
+func (q *twoq) UsedBytes() int {
+	return q.a1in.bytes + q.am.bytes + q.a1out.keyBytes
+}
+
+// trimOut 限制 a1out 的大小：不超过 outBytes；没有容量限制时（只有 DelOldest 会淘汰），
+// 按当前记录数的 defaultOutRatio 限制条数
+func (q *twoq) trimOut() {
+	for q.a1out.ll.Len() > 0 {
+		if q.maxBytes > 0 && q.a1out.bytes <= q.outBytes ||
+			q.maxBytes <= 0 && float64(q.a1out.ll.Len()) <= float64(q.Len())*defaultOutRatio {
+			return
+		}
+		ghost := q.a1out.remove(q.a1out.ll.Front())
+		delete(q.cache, ghost.key)
+	}
+}
+
+// Snapshot 依次保存 a1in、am 中所有未过期的记录，Weight 为 1 表示在 a1in 中，为 2 表示在 am 中；a1out 不会保存
+func (q *twoq) Snapshot(w io.Writer) error {
+	entries := make([]cache.SnapshotEntry, 0, q.Len())
+	for weight, seg := range []*segment{q.a1in, q.am} {
+		for e := seg.ll.Front(); e != nil; e = e.Next() {
+			et := e.Value.(*entry)
+			if !q.opts.Expired(et.expireAt) {
+				entries = append(entries, cache.SnapshotEntry{Key: et.key, Value: et.value, ExpireAt: et.expireAt, Weight: weight + 1})
+			}
+		}
+	}
+	return cache.WriteSnapshot(w, q.opts.Codec, entries)
+}
+
+// Restore 按快照中的顺序将记录放回 a1in、am，容量不足时按 2Q 的规则淘汰
+func (q *twoq) Restore(r io.Reader) error {
+	entries, err := cache.ReadSnapshot(r, q.opts.Codec)
+	if err != nil {
+		return err
+	}
+	for _, se := range entries {
+		key, value, err := cache.Unpack[string, interface{}](se)
+		if err != nil {
+			return err
+		}
+		if q.opts.Expired(se.ExpireAt) {
+			continue
+		}
+		if e, ok := q.cache[key]; ok {
+			e.Value.(*entry).seg.remove(e)
+		}
+		seg := q.a1in
+		if se.Weight > 1 {
+			seg = q.am
+		}
+		q.cache[key] = seg.pushBack(&entry{key: key, value: value, expireAt: se.ExpireAt, size: entrySize(key, value)})
+		for q.maxBytes > 0 && q.a1in.bytes+q.am.bytes > q.maxBytes {
+			q.DelOldest()
+		}
+	}
+	return nil
+}
+
+func (q *twoq) removeElement(e *list.Element, reason cache.EvictReason) {
+	et := e.Value.(*entry)
+	et.seg.remove(e)
+	delete(q.cache, et.key)
+
+	if q.onEvicted != nil {
+		q.onEvicted(et.key, et.value, reason)
+	}
+}
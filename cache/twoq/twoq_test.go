@@ -0,0 +1,118 @@
+package twoq
+
+import (
+	"cache"
+	"strconv"
+	"testing"
+
+	"github.com/matryer/is"
+)
+
+// entryBytes 值为 int32、key 长度为 2 的 entry 占用的字节数
+var entryBytes = entrySize("k1", int32(0))
+
+func TestSet(t *testing.T) {
+	is := is.New(t)
+
+	cache := New(2*entryBytes, nil)
+	cache.DelOldest()
+	cache.Set("k1", int32(1))
+	cache.Set("k2", int32(2))
+
+	v := cache.Get("k1")
+	is.Equal(v, int32(1))
+
+	// a1in 是 FIFO，Get 不会改变淘汰顺序
+	cache.Set("k3", int32(3))
+	is.Equal(cache.Get("k1"), nil)
+	is.Equal(cache.Get("k2"), int32(2))
+
+	is.Equal(2, cache.Len())
+	cache.Del("k2")
+	is.Equal(1, cache.Len())
+}
+
+func TestUpdate(t *testing.T) {
+	is := is.New(t)
+
+	q := New(2*entryBytes, nil).(*twoq)
+	q.Set("k1", int32(1))
+	q.Set("k2", int32(2))
+
+	// 更新 a1in 中的 k1 不会改变它的位置，仍然先被淘汰
+	q.Set("k1", int32(10))
+	is.Equal(q.Get("k1"), int32(10))
+	q.Set("k3", int32(3))
+	is.Equal(q.Get("k1"), nil)
+	is.Equal(q.Get("k2"), int32(2))
+	// a1out 只占用 key 和 entryOverhead 的内存
+	is.Equal(q.UsedBytes(), 2*entryBytes+cache.KeySize("k1")+entryOverhead)
+}
+
+func TestOutLimit(t *testing.T) {
+	is := is.New(t)
+
+	// 没有容量限制时，a1out 按记录数的比例限制条数，不会每次都被清空
+	q := New(0, nil).(*twoq)
+	for i := 0; i < 10; i++ {
+		q.Set("k"+strconv.Itoa(i), int32(i))
+	}
+	for i := 0; i < 4; i++ {
+		q.DelOldest()
+	}
+	is.Equal(q.Len(), 6)
+	is.Equal(q.a1out.ll.Len(), 3)
+
+	// k0 最旧，已经从 a1out 中移除；k3 仍然在 a1out 中，再次写入时放入 am
+	q.Set("k0", int32(0))
+	q.Set("k3", int32(3))
+	is.Equal(q.am.ll.Len(), 1)
+	is.Equal(q.Get("k3"), int32(3))
+}
+
+func TestOnEvicted(t *testing.T) {
+	is := is.New(t)
+
+	keys := make([]string, 0, 8)
+	onEvicted := func(key string, value interface{}, reason cache.EvictReason) {
+		keys = append(keys, key)
+	}
+	cache := New(2*entryBytes, onEvicted)
+
+	cache.Set("k1", int32(1))
+	cache.Set("k2", int32(2))
+	cache.Set("k3", int32(3))
+	// k1 在 a1out 中，再次写入时放入 am
+	cache.Set("k1", int32(1))
+	cache.Set("k4", int32(4))
+
+	expected := []string{"k1", "k2", "k3"}
+
+	is.Equal(expected, keys)
+	is.Equal(2, cache.Len())
+	is.Equal(cache.Get("k1"), int32(1))
+}
+
+func TestScanResistance(t *testing.T) {
+	is := is.New(t)
+
+	c := New(10*entryBytes, nil)
+	hot := []string{"h1", "h2", "h3", "h4", "h5"}
+	for _, key := range hot {
+		c.Set(key, int32(1))
+	}
+	// 热点数据被淘汰到 a1out 后再次写入，进入 am
+	for i := 0; i < 10; i++ {
+		c.Set(string(rune('a'+i)), int32(1))
+	}
+	for _, key := range hot {
+		c.Set(key, int32(1))
+	}
+
+	for i := 0; i < 100; i++ {
+		c.Set(string(rune('a'+i%26))+string(rune('a'+i/26)), int32(1))
+	}
+	for _, key := range hot {
+		is.Equal(c.Get(key), int32(1))
+	}
+}
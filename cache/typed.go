@@ -0,0 +1,113 @@
+package cache
+
+import (
+	"io"
+	"time"
+)
+
+// Sizer 计算值占用的字节数
+type Sizer[V any] func(value V) int
+
+// SizerOrDefault 返回 sizer，为 nil 时返回使用 CalcLen 计算的 Sizer
+func SizerOrDefault[V any](sizer Sizer[V]) Sizer[V] {
+	if sizer != nil {
+		return sizer
+	}
+	return func(value V) int {
+		return CalcLen(value)
+	}
+}
+
+// TypedOnEvicted 泛型版本的 OnEvicted
+type TypedOnEvicted[K comparable, V any] func(key K, value V, reason EvictReason)
+
+// TypedCache 泛型版本的 Cache，Get 通过第二个返回值区分未命中和零值
+type TypedCache[K comparable, V any] interface {
+	Set(key K, value V)
+	// SetWithTTL 写入 entry 并指定过期时间，ttl 小于等于 0 表示永不过期
+	SetWithTTL(key K, value V, ttl time.Duration)
+	// Get 返回 key 对应的值，不存在或已过期时 ok 为 false
+	Get(key K) (value V, ok bool)
+	// Contains 判断 key 是否存在且未过期，不会调整淘汰顺序、访问频率，也不会删除过期的 entry
+	Contains(key K) bool
+	Del(key K)
+	DelOldest()
+	// DeleteExpired 删除所有已过期的 entry，返回删除的个数
+	DeleteExpired() int
+	Len() int
+}
+
+// Untyped 将 TypedCache 适配为 Cache，未命中时 Get 返回 nil；
+// c 实现了 Snapshotter 时，返回的 Cache 同样支持快照
+func Untyped(c TypedCache[string, interface{}]) Cache {
+	return untyped{c}
+}
+
+type untyped struct {
+	TypedCache[string, interface{}]
+}
+
+func (u untyped) Get(key string) interface{} {
+	value, _ := u.TypedCache.Get(key)
+	return value
+}
+
+func (u untyped) Snapshot(w io.Writer) error {
+	s, ok := u.TypedCache.(Snapshotter)
+	if !ok {
+		return ErrSnapshotUnsupported
+	}
+	return s.Snapshot(w)
+}
+
+func (u untyped) Restore(r io.Reader) error {
+	s, ok := u.TypedCache.(Snapshotter)
+	if !ok {
+		return ErrSnapshotUnsupported
+	}
+	return s.Restore(r)
+}
+
+// Typed 将 Cache 适配为 TypedCache，值为 nil 视为未命中
+func Typed(c Cache) TypedCache[string, interface{}] {
+	if u, ok := c.(untyped); ok {
+		return u.TypedCache
+	}
+	return typed{c}
+}
+
+type typed struct {
+	Cache
+}
+
+func (t typed) Get(key string) (interface{}, bool) {
+	value := t.Cache.Get(key)
+	return value, value != nil
+}
+
+func (t typed) Snapshot(w io.Writer) error {
+	s, ok := t.Cache.(Snapshotter)
+	if !ok {
+		return ErrSnapshotUnsupported
+	}
+	return s.Snapshot(w)
+}
+
+func (t typed) Restore(r io.Reader) error {
+	s, ok := t.Cache.(Snapshotter)
+	if !ok {
+		return ErrSnapshotUnsupported
+	}
+	return s.Restore(r)
+}
+
+// unwrap 返回 Untyped、Typed 包装之前的缓存
+func unwrap(c interface{}) interface{} {
+	switch w := c.(type) {
+	case untyped:
+		return w.TypedCache
+	case typed:
+		return w.Cache
+	}
+	return c
+}
@@ -0,0 +1,65 @@
+package cmd
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"strings"
+
+	"tour/internal/treediff"
+
+	"github.com/spf13/cobra"
+)
+
+var diffIgnore []string
+var diffQuiet bool
+
+var diffCmd = &cobra.Command{
+	Use:   "diff <a> <b>",
+	Short: "结构化比较两个 JSON/YAML 文件",
+	Long: strings.Join([]string{
+		"将两个 JSON 或 YAML 文件解析为树后逐个路径比较，格式根据扩展名或内容判断，文件名为 - 时读取标准输入。",
+		"输出格式：",
+		"  + App.NewKey: \"value\"       b 中新增的路径",
+		"  - App.OldKey: \"value\"       b 中删除的路径",
+		"  ~ App.MaxPageSize: 100 -> 200  值发生变化的路径",
+		"--ignore 支持 glob：* 匹配一段 key 或下标，** 可跨越多段，如 Server.*、**.Password。",
+		"没有差异时退出码为 0，有差异时为 1，出错时为 2。",
+	}, "\n"),
+	Args: cobra.ExactArgs(2),
+	Run: func(cmd *cobra.Command, args []string) {
+		a, err := loadTree(args[0])
+		if err != nil {
+			log.Printf("loadTree %s err: %v", args[0], err)
+			os.Exit(2)
+		}
+		b, err := loadTree(args[1])
+		if err != nil {
+			log.Printf("loadTree %s err: %v", args[1], err)
+			os.Exit(2)
+		}
+
+		changes := treediff.Compare(a, b, diffIgnore)
+		if !diffQuiet {
+			for _, c := range changes {
+				fmt.Println(c)
+			}
+		}
+		if len(changes) > 0 {
+			os.Exit(1)
+		}
+	},
+}
+
+func loadTree(filename string) (interface{}, error) {
+	data, err := readInput(filename)
+	if err != nil {
+		return nil, err
+	}
+	return treediff.Parse(data, treediff.DetectFormat(filename, data))
+}
+
+func init() {
+	diffCmd.Flags().StringSliceVarP(&diffIgnore, "ignore", "i", nil, "忽略的路径 glob，可指定多次或以逗号分隔")
+	diffCmd.Flags().BoolVarP(&diffQuiet, "quiet", "q", false, "不输出差异，只通过退出码表示是否有差异")
+}
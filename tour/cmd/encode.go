@@ -0,0 +1,240 @@
+package cmd
+
+import (
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"tour/internal/encode"
+	"tour/internal/shell"
+
+	"github.com/spf13/cobra"
+)
+
+var encodeStr string
+var decode bool
+var base64Variant string
+var hashAlgo string
+var hashKey string
+var hashFormat string
+var secret string
+var nickname string
+var uid int
+
+var encodeCmd = &cobra.Command{
+	Use:   "encode",
+	Short: "编码、解码和摘要",
+	Long:  "编码、解码和摘要，输入来自 --str 参数，未指定时读取标准输入",
+	Run:   func(cmd *cobra.Command, args []string) {},
+}
+
+var base64Cmd = &cobra.Command{
+	Use:   "base64",
+	Short: "base64 编码/解码",
+	Long:  "base64 编码/解码，--variant 支持 std、url、rawstd、rawurl",
+	Run: func(cmd *cobra.Command, args []string) {
+		s := mustReadEncodeInput()
+		if decode {
+			data, err := encode.Base64Decode(base64Variant, s)
+			if err != nil {
+				log.Fatalf("encode.Base64Decode err: %v", err)
+			}
+			fmt.Println(string(data))
+			return
+		}
+
+		content, err := encode.Base64Encode(base64Variant, []byte(s))
+		if err != nil {
+			log.Fatalf("encode.Base64Encode err: %v", err)
+		}
+		fmt.Println(content)
+	},
+}
+
+var hexCmd = &cobra.Command{
+	Use:   "hex",
+	Short: "十六进制编码/解码",
+	Long:  "十六进制编码/解码",
+	Run: func(cmd *cobra.Command, args []string) {
+		s := mustReadEncodeInput()
+		if decode {
+			data, err := encode.HexDecode(s)
+			if err != nil {
+				log.Fatalf("encode.HexDecode err: %v", err)
+			}
+			fmt.Println(string(data))
+			return
+		}
+		fmt.Println(encode.HexEncode([]byte(s)))
+	},
+}
+
+var urlCmd = &cobra.Command{
+	Use:   "url",
+	Short: "URL 查询参数编码/解码",
+	Long:  "URL 查询参数编码/解码",
+	Run: func(cmd *cobra.Command, args []string) {
+		s := mustReadEncodeInput()
+		if decode {
+			content, err := encode.URLDecode(s)
+			if err != nil {
+				log.Fatalf("encode.URLDecode err: %v", err)
+			}
+			fmt.Println(content)
+			return
+		}
+		fmt.Println(encode.URLEncode(s))
+	},
+}
+
+var htmlCmd = &cobra.Command{
+	Use:   "html",
+	Short: "HTML 实体编码/解码",
+	Long:  "HTML 实体编码/解码",
+	Run: func(cmd *cobra.Command, args []string) {
+		s := mustReadEncodeInput()
+		if decode {
+			fmt.Println(encode.HTMLDecode(s))
+			return
+		}
+		fmt.Println(encode.HTMLEncode(s))
+	},
+}
+
+var hashCmd = &cobra.Command{
+	Use:   "hash",
+	Short: "计算摘要",
+	Long: strings.Join([]string{
+		"计算摘要，--algo 支持 md5、sha1、sha256、sha384、sha512，指定 --key 时计算 HMAC。",
+		"md5 的结果与 blog-service 的 util.EncodeMD5 一致。",
+	}, "\n"),
+	Run: func(cmd *cobra.Command, args []string) {
+		sum, err := encode.Sum(hashAlgo, []byte(mustReadEncodeInput()), []byte(hashKey))
+		if err != nil {
+			log.Fatalf("encode.Sum err: %v", err)
+		}
+
+		switch hashFormat {
+		case "hex":
+			fmt.Println(encode.HexEncode(sum))
+		case "base64":
+			content, _ := encode.Base64Encode(encode.Base64Std, sum)
+			fmt.Println(content)
+		default:
+			log.Fatalf("不支持的输出格式: %s", hashFormat)
+		}
+	},
+}
+
+var jwtCmd = &cobra.Command{
+	Use:   "jwt",
+	Short: "解析 JWT",
+	Long:  "解析 JWT，输出 header 和 claims，检查 exp；指定 --secret 时验证 HS256/HS384/HS512 签名",
+	Run: func(cmd *cobra.Command, args []string) {
+		token, err := encode.ParseJWT(mustReadEncodeInput())
+		if err != nil {
+			log.Fatalf("encode.ParseJWT err: %v", err)
+		}
+
+		fmt.Printf("Header:\n%s\n", encode.Pretty(token.Header))
+		fmt.Printf("Claims:\n%s\n", encode.Pretty(token.Claims))
+
+		for _, name := range []string{"iat", "nbf", "exp"} {
+			if t, ok := token.TimeClaim(name); ok {
+				fmt.Printf("%s: %s\n", name, t.Format("2006-01-02 15:04:05"))
+			}
+		}
+		left, err := token.CheckExpiration(time.Now())
+		switch {
+		case err != nil:
+			fmt.Println("Expiration: 未设置 exp")
+		case left <= 0:
+			fmt.Printf("Expiration: 已过期 %s\n", -left.Round(time.Second))
+		default:
+			fmt.Printf("Expiration: 剩余 %s\n", left.Round(time.Second))
+		}
+
+		if secret == "" {
+			fmt.Println("Signature: 未指定 --secret，跳过验证")
+			return
+		}
+		if err := token.Verify([]byte(secret)); err != nil {
+			log.Fatalf("Signature: %v", err)
+		}
+		fmt.Println("Signature: 验证通过")
+	},
+}
+
+var chatroomTokenCmd = &cobra.Command{
+	Use:   "chatroom",
+	Short: "解析或生成 chatroom 的 token",
+	Long: strings.Join([]string{
+		"解析 chatroom 的 token（base64 的 HMAC-SHA256 + uid<n>），指定 --nickname 和 --secret 时验证签名。",
+		"指定 --uid 时不读取输入，使用 --nickname 和 --secret 生成与 chatroom 相同的 token。",
+	}, "\n"),
+	Run: func(cmd *cobra.Command, args []string) {
+		if uid > 0 {
+			if secret == "" {
+				log.Fatalf("生成 token 需要指定 --secret")
+			}
+			fmt.Println(encode.GenChatroomToken(uid, nickname, secret))
+			return
+		}
+
+		token := mustReadEncodeInput()
+		mac, uid, err := encode.ChatroomToken(token)
+		if err != nil {
+			log.Fatalf("encode.ChatroomToken err: %v", err)
+		}
+		fmt.Printf("UID: %d\nMAC: %s\n", uid, encode.HexEncode(mac))
+
+		if secret == "" {
+			fmt.Println("Signature: 未指定 --secret，跳过验证")
+			return
+		}
+		if _, err := encode.VerifyChatroomToken(token, nickname, secret); err != nil {
+			log.Fatalf("Signature: %v", err)
+		}
+		fmt.Println("Signature: 验证通过")
+	},
+}
+
+// mustReadEncodeInput 返回 --str 参数，未指定时读取标准输入并去掉结尾的换行
+func mustReadEncodeInput() string {
+	if encodeStr != "" {
+		return encodeStr
+	}
+
+	data, err := readInput("-")
+	if err != nil {
+		log.Fatalf("readInput err: %v", err)
+	}
+	return strings.TrimRight(string(data), "\r\n")
+}
+
+func init() {
+	encodeCmd.AddCommand(base64Cmd)
+	encodeCmd.AddCommand(hexCmd)
+	encodeCmd.AddCommand(urlCmd)
+	encodeCmd.AddCommand(htmlCmd)
+	encodeCmd.AddCommand(hashCmd)
+	encodeCmd.AddCommand(jwtCmd)
+	encodeCmd.AddCommand(chatroomTokenCmd)
+
+	encodeCmd.PersistentFlags().StringVarP(&encodeStr, "str", "s", "", "请输入内容，未指定时读取标准输入")
+	for _, c := range []*cobra.Command{base64Cmd, hexCmd, urlCmd, htmlCmd} {
+		c.Flags().BoolVarP(&decode, "decode", "d", false, "解码")
+	}
+	base64Cmd.Flags().StringVarP(&base64Variant, "variant", "", encode.Base64Std, "编码方式：std、url、rawstd、rawurl")
+	hashCmd.Flags().StringVarP(&hashAlgo, "algo", "a", encode.AlgoMD5, "摘要算法：md5、sha1、sha256、sha384、sha512")
+	hashCmd.Flags().StringVarP(&hashKey, "key", "k", "", "HMAC 的密钥，指定时计算 HMAC")
+	hashCmd.Flags().StringVarP(&hashFormat, "format", "f", "hex", "输出格式：hex、base64")
+	shell.MarkSticky(base64Cmd.Flags(), "variant", encode.Base64Std, encode.Base64URL, encode.Base64RawStd, encode.Base64RawURL)
+	shell.MarkSticky(hashCmd.Flags(), "algo", encode.AlgoMD5, encode.AlgoSHA1, encode.AlgoSHA256, encode.AlgoSHA384, encode.AlgoSHA512)
+	shell.MarkSticky(hashCmd.Flags(), "format", "hex", "base64")
+	jwtCmd.Flags().StringVarP(&secret, "secret", "", "", "JWT 的签名密钥，对应 blog-service 配置中的 JWT.Secret")
+	chatroomTokenCmd.Flags().StringVarP(&secret, "secret", "", "", "token 密钥，对应 chatroom 配置中的 token-secret")
+	chatroomTokenCmd.Flags().StringVarP(&nickname, "nickname", "", "", "用户昵称")
+	chatroomTokenCmd.Flags().IntVarP(&uid, "uid", "", 0, "用户 ID，指定时生成 token")
+}
@@ -0,0 +1,82 @@
+package cmd
+
+import (
+	"fmt"
+	"log"
+	"strings"
+
+	"tour/internal/resourcegen"
+
+	"github.com/spf13/cobra"
+)
+
+var resourceFields string
+var resourceDesc string
+var resourceDir string
+var resourceModule string
+var resourcePrefix string
+
+var genCmd = &cobra.Command{
+	Use:   "gen",
+	Short: "代码生成",
+	Long:  "代码生成",
+	Run:   func(cmd *cobra.Command, args []string) {},
+}
+
+var genResourceCmd = &cobra.Command{
+	Use:   "resource <Name>",
+	Short: "为 blog-service 生成一个新的资源",
+	Long: strings.Join([]string{
+		"参照 Tag 的实现，为 blog-service 生成以下文件（已存在的文件不会被覆盖）：",
+		"  internal/model/<name>.go             模型及 Count/Get/List/Create/Update/Delete",
+		"  internal/dao/<name>.go               dao 方法",
+		"  internal/service/<name>.go           请求结构体（含 binding 校验）及 service 方法",
+		"  internal/routers/api/v1/<name>.go    带 swagger 注释的 handler",
+		"  pkg/errcode/<name>_code.go           错误码",
+		"生成后需要将输出的路由代码添加到 routers.NewRouter 中。",
+		"字段类型支持 string、bool、int、int8~int64、uint、uint8~uint64、float32、float64，如：",
+		"  tour gen resource Comment --fields \"content:string,article_id:uint32\"",
+	}, "\n"),
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		r := &resourcegen.Resource{
+			Name:        args[0],
+			Fields:      resourceFields,
+			Desc:        resourceDesc,
+			TablePrefix: resourcePrefix,
+			Module:      resourceModule,
+			Dir:         resourceDir,
+		}
+		if r.Module == "" {
+			r.Module = resourcegen.ModulePath(r.Dir)
+		}
+		if r.Module == "" {
+			r.Module = "blog-service"
+		}
+
+		result, err := r.Generate()
+		if err != nil {
+			log.Fatalf("resource.Generate err: %v", err)
+		}
+
+		for _, path := range result.Created {
+			log.Printf("创建 %s", path)
+		}
+		for _, path := range result.Skipped {
+			log.Printf("已存在，跳过 %s", path)
+		}
+		log.Printf("请将以下代码添加到 routers.NewRouter 中:")
+		for _, line := range result.RouterLines {
+			fmt.Println(line)
+		}
+	},
+}
+
+func init() {
+	genCmd.AddCommand(genResourceCmd)
+	genResourceCmd.Flags().StringVarP(&resourceFields, "fields", "f", "", "字段定义，格式为 name:type，多个字段以逗号分隔")
+	genResourceCmd.Flags().StringVarP(&resourceDesc, "desc", "", "", "资源的中文描述，用于 swagger 注释和错误信息，默认为资源名")
+	genResourceCmd.Flags().StringVarP(&resourceDir, "dir", "d", ".", "blog-service 项目的根目录")
+	genResourceCmd.Flags().StringVarP(&resourceModule, "module", "", "", "blog-service 的 module 名，默认读取 go.mod")
+	genResourceCmd.Flags().StringVarP(&resourcePrefix, "prefix", "", "blog_", "表名前缀")
+}
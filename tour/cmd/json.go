@@ -0,0 +1,67 @@
+package cmd
+
+import (
+	"log"
+	"os"
+	"strings"
+
+	"tour/internal/json2struct"
+
+	"github.com/spf13/cobra"
+)
+
+var jsonStr string
+var structName string
+
+var jsonCmd = &cobra.Command{
+	Use:   "json",
+	Short: "json 转换和处理",
+	Long:  "json 转换和处理",
+	Run:   func(cmd *cobra.Command, args []string) {},
+}
+
+var json2structCmd = &cobra.Command{
+	Use:   "struct [file...]",
+	Short: "json 转换",
+	Long: strings.Join([]string{
+		"根据一个或多个 JSON 样本推断并生成 Go 结构体，样本来源依次为：",
+		"--str 参数、命令行指定的文件、标准输入。",
+		"多个样本会合并字段：缺失的字段标记为 omitempty，出现过 null 的字段使用指针，",
+		"整数和浮点数混合时使用 float64。",
+	}, "\n"),
+	Run: func(cmd *cobra.Command, args []string) {
+		parser := json2struct.NewParser()
+		switch {
+		case jsonStr != "":
+			if err := parser.ParseBytes([]byte(jsonStr)); err != nil {
+				log.Fatalf("parser.ParseBytes err: %v", err)
+			}
+		case len(args) > 0:
+			for _, filename := range args {
+				data, err := readInput(filename)
+				if err != nil {
+					log.Fatalf("readInput err: %v", err)
+				}
+				if err := parser.ParseBytes(data); err != nil {
+					log.Fatalf("parser.ParseBytes %s err: %v", filename, err)
+				}
+			}
+		default:
+			if err := parser.Parse(os.Stdin); err != nil {
+				log.Fatalf("parser.Parse err: %v", err)
+			}
+		}
+
+		err := parser.Generate(os.Stdout, structName, packageName)
+		if err != nil {
+			log.Fatalf("parser.Generate err: %v", err)
+		}
+	},
+}
+
+func init() {
+	jsonCmd.AddCommand(json2structCmd)
+	json2structCmd.Flags().StringVarP(&jsonStr, "str", "s", "", "请输入 JSON 字符串")
+	json2structCmd.Flags().StringVarP(&structName, "name", "n", "Root", "根结构体的名称")
+	json2structCmd.Flags().StringVarP(&packageName, "package", "p", "", "生成代码的包名，为空时不输出 package 声明")
+}
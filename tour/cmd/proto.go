@@ -0,0 +1,64 @@
+package cmd
+
+import (
+	"log"
+	"path/filepath"
+	"strings"
+
+	"tour/internal/grpcgen"
+
+	"github.com/spf13/cobra"
+)
+
+var scaffoldOut string
+var scaffoldModule string
+
+var protoCmd = &cobra.Command{
+	Use:   "proto",
+	Short: "proto 文件处理",
+	Long:  "proto 文件处理",
+	Run:   func(cmd *cobra.Command, args []string) {},
+}
+
+var protoScaffoldCmd = &cobra.Command{
+	Use:   "scaffold <file.proto>",
+	Short: "根据 service 定义生成 gRPC 服务端和客户端",
+	Long: strings.Join([]string{
+		"解析 .proto 文件中的 service 定义，生成以下文件（已存在的文件不会被覆盖）：",
+		"  go.mod",
+		"  proto/<file.proto>  proto 文件的副本",
+		"  server/server.go    每个 RPC 对应一个方法桩，区分一元、客户端流、服务端流和双向流",
+		"  client/client.go    可直接运行的客户端，依次调用所有 RPC",
+		"生成后在输出目录下执行提示的 protoc 命令生成 pb 代码即可运行。",
+	}, "\n"),
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		name := strings.TrimSuffix(filepath.Base(args[0]), filepath.Ext(args[0]))
+		s := &grpcgen.Scaffold{Module: scaffoldModule, OutDir: scaffoldOut}
+		if s.Module == "" {
+			s.Module = name
+		}
+		if s.OutDir == "" {
+			s.OutDir = name
+		}
+
+		result, err := s.Generate(args[0])
+		if err != nil {
+			log.Fatalf("scaffold.Generate err: %v", err)
+		}
+
+		for _, path := range result.Created {
+			log.Printf("创建 %s", path)
+		}
+		for _, path := range result.Skipped {
+			log.Printf("已存在，跳过 %s", path)
+		}
+		log.Printf("请在 %s 目录下执行: %s", s.OutDir, result.ProtocCmd)
+	},
+}
+
+func init() {
+	protoCmd.AddCommand(protoScaffoldCmd)
+	protoScaffoldCmd.Flags().StringVarP(&scaffoldOut, "out", "o", "", "输出目录，默认为 proto 文件名")
+	protoScaffoldCmd.Flags().StringVarP(&scaffoldModule, "module", "", "", "生成项目的 module 名，默认为 proto 文件名")
+}
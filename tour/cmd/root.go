@@ -14,4 +14,12 @@ func Execute() error {
 
 func init() {
 	rootCmd.AddCommand(wordCmd)
+	rootCmd.AddCommand(timeCmd)
+	rootCmd.AddCommand(sqlCmd)
+	rootCmd.AddCommand(jsonCmd)
+	rootCmd.AddCommand(protoCmd)
+	rootCmd.AddCommand(encodeCmd)
+	rootCmd.AddCommand(genCmd)
+	rootCmd.AddCommand(diffCmd)
+	rootCmd.AddCommand(shellCmd)
 }
@@ -0,0 +1,104 @@
+package cmd
+
+import (
+	"errors"
+	"io"
+	"log"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"tour/internal/shell"
+
+	"github.com/chzyer/readline"
+	"github.com/spf13/cobra"
+)
+
+var shellCmd = &cobra.Command{
+	Use:   "shell",
+	Short: "进入交互模式",
+	Long: strings.Join([]string{
+		"进入交互模式，可以直接输入子命令，如 word -s hello_world -m camel。",
+		"支持行编辑、历史记录（保存在 ~/.tour_history）以及子命令、flag 和部分 flag 取值的 Tab 补全。",
+		"word 的 --mode、time 的 --zone、encode 的 --variant、--algo 等模式类 flag 会按命令记住上次的取值，",
+		"下次执行同一命令且未指定时自动沿用。",
+		"每条命令在独立的子进程中执行，出错不会退出交互模式；子命令无法读取标准输入，请通过参数传入内容。",
+		"输入 exit、quit 或 Ctrl-D 退出。",
+	}, "\n"),
+	Args: cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		exe, err := os.Executable()
+		if err != nil {
+			log.Fatalf("os.Executable err: %v", err)
+		}
+
+		rl, err := readline.NewEx(&readline.Config{
+			Prompt:          "tour> ",
+			HistoryFile:     historyFile(),
+			AutoComplete:    &shell.Completer{Root: rootCmd, Builtins: []string{"exit", "quit"}},
+			InterruptPrompt: "^C",
+			EOFPrompt:       "exit",
+		})
+		if err != nil {
+			log.Fatalf("readline.NewEx err: %v", err)
+		}
+		defer rl.Close()
+
+		sticky := shell.NewSticky()
+		for {
+			line, err := rl.Readline()
+			if err == readline.ErrInterrupt {
+				continue
+			}
+			if err == io.EOF {
+				return
+			}
+			if err != nil {
+				log.Fatalf("rl.Readline err: %v", err)
+			}
+
+			args, err := shell.Split(line)
+			if err != nil {
+				log.Printf("shell.Split err: %v", err)
+				continue
+			}
+			if len(args) == 0 {
+				continue
+			}
+			switch args[0] {
+			case "exit", "quit":
+				return
+			case "shell":
+				log.Printf("已经在交互模式中")
+				continue
+			}
+
+			args, added := sticky.Apply(rootCmd, args)
+			if len(added) > 0 {
+				log.Printf("沿用上次的参数: %s", strings.Join(added, " "))
+			}
+			runSubProcess(exe, args)
+		}
+	},
+}
+
+// runSubProcess 在子进程中执行子命令，子命令中的 log.Fatalf、os.Exit 不会退出交互模式
+func runSubProcess(exe string, args []string) {
+	c := exec.Command(exe, args...)
+	c.Stdout = os.Stdout
+	c.Stderr = os.Stderr
+	err := c.Run()
+	var exitErr *exec.ExitError
+	if err != nil && !errors.As(err, &exitErr) {
+		log.Printf("exec.Command err: %v", err)
+	}
+}
+
+func historyFile() string {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(home, ".tour_history")
+}
@@ -0,0 +1,81 @@
+package cmd
+
+import (
+	"io/ioutil"
+	"log"
+	"os"
+
+	"tour/internal/shell"
+	"tour/internal/sql2struct"
+
+	"github.com/spf13/cobra"
+)
+
+var sqlFile string
+var dbType string
+var tableName string
+var tablePrefix string
+var packageName string
+
+var sqlCmd = &cobra.Command{
+	Use:   "sql",
+	Short: "sql 转换和处理",
+	Long:  "sql 转换和处理",
+	Run:   func(cmd *cobra.Command, args []string) {},
+}
+
+var sql2structCmd = &cobra.Command{
+	Use:   "struct",
+	Short: "sql 转换",
+	Long:  "读取 CREATE TABLE 语句（文件或标准输入），生成带 json/gorm 标签的 Go 结构体",
+	Run: func(cmd *cobra.Command, args []string) {
+		ddl, err := readInput(sqlFile)
+		if err != nil {
+			log.Fatalf("readInput err: %v", err)
+		}
+
+		tables, err := sql2struct.ParseDDL(string(ddl))
+		if err != nil {
+			log.Fatalf("sql2struct.ParseDDL err: %v", err)
+		}
+		if tableName != "" {
+			tables = filterTables(tables, tableName)
+		}
+		if len(tables) == 0 {
+			log.Fatalf("没有找到可以转换的 CREATE TABLE 语句")
+		}
+
+		template := sql2struct.NewStructTemplate(dbType, tablePrefix)
+		err = template.Generate(os.Stdout, packageName, tables)
+		if err != nil {
+			log.Fatalf("template.Generate err: %v", err)
+		}
+	},
+}
+
+// readInput 读取文件内容，文件名为空或 - 时读取标准输入
+func readInput(filename string) ([]byte, error) {
+	if filename == "" || filename == "-" {
+		return ioutil.ReadAll(os.Stdin)
+	}
+	return ioutil.ReadFile(filename)
+}
+
+func filterTables(tables []*sql2struct.Table, name string) []*sql2struct.Table {
+	for _, table := range tables {
+		if table.Name == name {
+			return []*sql2struct.Table{table}
+		}
+	}
+	return nil
+}
+
+func init() {
+	sqlCmd.AddCommand(sql2structCmd)
+	sql2structCmd.Flags().StringVarP(&sqlFile, "file", "f", "", "DDL 文件路径，为空或 - 时读取标准输入")
+	sql2structCmd.Flags().StringVarP(&dbType, "type", "", sql2struct.TypeMySQL, "数据库类型，支持 mysql、sqlite")
+	shell.SetValues(sql2structCmd.Flags(), "type", sql2struct.TypeMySQL, sql2struct.TypeSQLite)
+	sql2structCmd.Flags().StringVarP(&tableName, "table", "t", "", "只转换指定的表")
+	sql2structCmd.Flags().StringVarP(&tablePrefix, "prefix", "", "", "生成结构体名时去掉的表名前缀，如 blog_")
+	sql2structCmd.Flags().StringVarP(&packageName, "package", "p", "", "生成代码的包名，为空时不输出 package 声明")
+}
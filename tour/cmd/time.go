@@ -0,0 +1,98 @@
+package cmd
+
+import (
+	"log"
+	"strings"
+	"time"
+
+	"tour/internal/shell"
+	"tour/internal/timer"
+
+	"github.com/spf13/cobra"
+)
+
+var calculateTime string
+var duration string
+var zone string
+
+var timeCmd = &cobra.Command{
+	Use:   "time",
+	Short: "时间格式处理",
+	Long:  "时间格式处理",
+	Run:   func(cmd *cobra.Command, args []string) {},
+}
+
+var nowTimeCmd = &cobra.Command{
+	Use:   "now",
+	Short: "获取当前时间",
+	Long:  "获取当前时间，按多种格式和时间戳输出",
+	Run: func(cmd *cobra.Command, args []string) {
+		location := mustLoadLocation(zone)
+		printTime(timer.GetNowTime(location), "")
+	},
+}
+
+var calculateTimeCmd = &cobra.Command{
+	Use:   "calc",
+	Short: "计算所需时间",
+	Long:  "计算所需时间",
+	Run: func(cmd *cobra.Command, args []string) {
+		location := mustLoadLocation(zone)
+
+		var currentTimer time.Time
+		var layout string
+		if calculateTime == "" {
+			currentTimer = timer.GetNowTime(location)
+		} else {
+			var err error
+			currentTimer, layout, err = timer.ParseTime(calculateTime, location)
+			if err != nil {
+				log.Fatalf("timer.ParseTime err: %v", err)
+			}
+		}
+
+		t, err := timer.GetCalculateTime(currentTimer, duration)
+		if err != nil {
+			log.Fatalf("timer.GetCalculateTime err: %v", err)
+		}
+
+		printTime(t, layout)
+	},
+}
+
+func mustLoadLocation(name string) *time.Location {
+	location, err := timer.LoadLocation(name)
+	if err != nil {
+		log.Fatalf("timer.LoadLocation err: %v", err)
+	}
+	return location
+}
+
+// printTime 输出时间，layout 不为空时只按该格式输出
+func printTime(t time.Time, layout string) {
+	if layout != "" {
+		log.Printf("输出结果: %s, %d", t.Format(layout), t.Unix())
+		return
+	}
+
+	for _, layout := range timer.Layouts {
+		log.Printf("%-36s %s", layout, t.Format(layout))
+	}
+	log.Printf("%-36s %d", "Unix", t.Unix())
+	log.Printf("%-36s %d", "UnixMilli", t.UnixNano()/int64(time.Millisecond))
+	log.Printf("%-36s %d", "UnixNano", t.UnixNano())
+}
+
+func init() {
+	timeCmd.AddCommand(nowTimeCmd)
+	timeCmd.AddCommand(calculateTimeCmd)
+
+	timeCmd.PersistentFlags().StringVarP(&zone, "zone", "z", "", "IANA 时区，如 Asia/Shanghai、UTC，默认为本地时区")
+	shell.MarkSticky(timeCmd.PersistentFlags(), "zone", "Local", "UTC", "Asia/Shanghai")
+	calculateTimeCmd.Flags().StringVarP(&calculateTime, "calculate", "c", "", "需要计算的时间，有效单位为时间戳或已格式化后的时间，默认为当前时间")
+	calculateTimeCmd.Flags().StringVarP(&duration, "duration", "d", "0", strings.Join([]string{
+		"持续时间，可带 +/- 符号，",
+		`有效时间单位为 "ns", "us" (or "µs"), "ms", "s", "m", "h", "d"，如 +2h30m、-7d，`,
+		"默认为 0，只转换时间格式",
+	}, ""))
+}
@@ -1,9 +1,18 @@
 package cmd
 
 import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
 	"log"
+	"os"
+	"sort"
+	"strconv"
 	"strings"
 
+	"tour/internal/shell"
 	"tour/internal/word"
 
 	"github.com/spf13/cobra"
@@ -15,45 +24,207 @@ const (
 	ModeUnderscoreToUpperCamelCase            // 下划线转大写驼峰
 	ModeUnderscoreToLowerCamelCase            // 下划线转小写驼峰
 	ModeCamelCaseToUnderscore                 // 驼峰转下划线
+	ModeKebabCase                             // 转中划线
+	ModeScreamingSnakeCase                    // 转全大写下划线
+	ModeDotCase                               // 转点分隔
+	ModeTitleCase                             // 转空格分隔的首字母大写
+	ModeGoName                                // 转可导出的 Go 命名
 )
 
+// modeNames 模式名到模式的映射，--mode 同时支持模式名和数字
+var modeNames = map[string]int{
+	"upper":     ModeUpper,
+	"lower":     ModeLower,
+	"pascal":    ModeUnderscoreToUpperCamelCase,
+	"camel":     ModeUnderscoreToLowerCamelCase,
+	"snake":     ModeCamelCaseToUnderscore,
+	"kebab":     ModeKebabCase,
+	"screaming": ModeScreamingSnakeCase,
+	"dot":       ModeDotCase,
+	"title":     ModeTitleCase,
+	"go":        ModeGoName,
+}
+
 var str string
-var mode int8
+var mode string
+var initialisms []string
+var inPlace bool
+var dryRun bool
+var match string
 var desc = strings.Join([]string{
-	"该子命令支持各种单词格式转换，模式如下：",
-	"1：全部转大写",
-	"2：全部转小写",
-	"3：下划线转大写驼峰",
-	"4：下划线转小写驼峰",
-	"5：驼峰转下划线",
+	"该子命令支持各种单词格式转换，模式如下（可使用数字或名称）：",
+	"1 / upper：全部转大写",
+	"2 / lower：全部转小写",
+	"3 / pascal：转大写驼峰",
+	"4 / camel：转小写驼峰",
+	"5 / snake：转下划线",
+	"6 / kebab：转中划线",
+	"7 / screaming：转全大写下划线",
+	"8 / dot：转点分隔",
+	"9 / title：转空格分隔的首字母大写",
+	"10 / go：转可导出的 Go 命名（保留 ID、URL、HTTP、JSON 等缩写词）",
+	"",
+	"未指定 --str 时逐行转换命令行指定的文件或标准输入，结果输出到标准输出。",
+	"使用 --in-place 时将文件中匹配 --match 正则的标识符转换后写回，",
+	"正则含有分组时只转换分组内容，如 --match 'json:\"(\\w+)\"'；加上 --dry-run 只输出 diff。",
 }, "\n")
 
 var wordCmd = &cobra.Command{
-	Use:   "word",
+	Use:   "word [file...]",
 	Short: "单词格式转换",
 	Long:  desc,
 	Run: func(cmd *cobra.Command, args []string) {
-		var content string
-		switch mode {
-		case ModeUpper:
-			content = word.ToUpper(str)
-		case ModeLower:
-			content = word.ToLower(str)
-		case ModeUnderscoreToUpperCamelCase:
-			content = word.UnderscoreToUpperCamelCase(str)
-		case ModeUnderscoreToLowerCamelCase:
-			content = word.UnderscoreToLowerCamelCase(str)
-		case ModeCamelCaseToUnderscore:
-			content = word.CamelCaseToUnderscore(str)
-		default:
+		word.AddInitialisms(initialisms...)
+
+		convert := converter(parseMode(mode))
+		if convert == nil {
 			log.Fatalf("暂不支持该转换模式，请执行 help word 查看帮助文档")
 		}
 
-		log.Printf("输出结果: %s\n", content)
+		switch {
+		case inPlace:
+			if err := rewriteFiles(args, convert); err != nil {
+				log.Fatalf("rewriteFiles err: %v", err)
+			}
+		case str != "":
+			fmt.Println(convert(str))
+		case len(args) > 0:
+			for _, filename := range args {
+				if err := convertLines(filename, convert); err != nil {
+					log.Fatalf("convertLines err: %v", err)
+				}
+			}
+		default:
+			if err := convertLines("-", convert); err != nil {
+				log.Fatalf("convertLines err: %v", err)
+			}
+		}
 	},
 }
 
+// converter 返回模式对应的转换函数，不支持的模式返回 nil
+func converter(mode int) func(string) string {
+	switch mode {
+	case ModeUpper:
+		return word.ToUpper
+	case ModeLower:
+		return word.ToLower
+	case ModeUnderscoreToUpperCamelCase:
+		return word.UnderscoreToUpperCamelCase
+	case ModeUnderscoreToLowerCamelCase:
+		return word.UnderscoreToLowerCamelCase
+	case ModeCamelCaseToUnderscore:
+		return word.CamelCaseToUnderscore
+	case ModeKebabCase:
+		return word.ToKebabCase
+	case ModeScreamingSnakeCase:
+		return word.ToScreamingSnakeCase
+	case ModeDotCase:
+		return word.ToDotCase
+	case ModeTitleCase:
+		return word.ToTitleCase
+	case ModeGoName:
+		return word.ToGoName
+	}
+	return nil
+}
+
+// convertLines 逐行转换文件内容并输出到标准输出，filename 为 - 时读取标准输入
+func convertLines(filename string, convert func(string) string) error {
+	var r io.Reader = os.Stdin
+	if filename != "-" {
+		f, err := os.Open(filename)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		r = f
+	}
+
+	w := bufio.NewWriter(os.Stdout)
+	defer w.Flush()
+
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		fmt.Fprintln(w, convert(strings.TrimSpace(scanner.Text())))
+	}
+	return scanner.Err()
+}
+
+// rewriteFiles 将文件中匹配 --match 的标识符按模式转换后写回，--dry-run 时只输出 diff
+func rewriteFiles(filenames []string, convert func(string) string) error {
+	if match == "" {
+		return errors.New("--in-place 模式需要通过 --match 指定要转换的标识符")
+	}
+	if len(filenames) == 0 {
+		return errors.New("--in-place 模式需要指定文件")
+	}
+
+	rewriter, err := word.NewRewriter(match, convert)
+	if err != nil {
+		return err
+	}
+
+	for _, filename := range filenames {
+		info, err := os.Stat(filename)
+		if err != nil {
+			return err
+		}
+		if info.IsDir() {
+			return fmt.Errorf("%s 是目录", filename)
+		}
+
+		src, err := ioutil.ReadFile(filename)
+		if err != nil {
+			return err
+		}
+
+		dst, changes := rewriter.Rewrite(src)
+		if len(changes) == 0 {
+			continue
+		}
+		if dryRun {
+			if err := word.Diff(os.Stdout, filename, changes); err != nil {
+				return err
+			}
+			continue
+		}
+
+		if err := ioutil.WriteFile(filename, dst, info.Mode()); err != nil {
+			return err
+		}
+		log.Printf("%s: 修改了 %d 行", filename, len(changes))
+	}
+
+	return nil
+}
+
+// parseMode 解析 --mode 参数，无法识别时返回 0
+func parseMode(s string) int {
+	s = strings.ToLower(strings.TrimSpace(s))
+	if m, err := strconv.Atoi(s); err == nil {
+		return m
+	}
+	return modeNames[s]
+}
+
+// modeNameList 返回排好序的模式名，用于交互模式的补全
+func modeNameList() []string {
+	names := make([]string, 0, len(modeNames))
+	for name := range modeNames {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
 func init() {
 	wordCmd.Flags().StringVarP(&str, "str", "s", "", "请输入单词内容")
-	wordCmd.Flags().Int8VarP(&mode, "mode", "m", 0, "请输入单词转换的模式")
+	wordCmd.Flags().StringVarP(&mode, "mode", "m", "", "请输入单词转换的模式，数字或名称")
+	wordCmd.Flags().BoolVar(&inPlace, "in-place", false, "将文件中匹配 --match 的标识符转换后写回")
+	wordCmd.Flags().BoolVar(&dryRun, "dry-run", false, "与 --in-place 一起使用，只输出 diff，不修改文件")
+	wordCmd.Flags().StringVar(&match, "match", "", "--in-place 模式下要转换的标识符的正则")
+	wordCmd.Flags().StringSliceVar(&initialisms, "initialisms", nil, "额外的缩写词，逗号分隔，全小写的视为全大写，用于 go 模式和单词切分")
+	shell.MarkSticky(wordCmd.Flags(), "mode", modeNameList()...)
 }
@@ -2,4 +2,10 @@ module tour
 
 go 1.15
 
-require github.com/spf13/cobra v1.2.1
+require (
+	github.com/chzyer/readline v1.5.1
+	github.com/matryer/is v1.4.0
+	github.com/spf13/cobra v1.2.1
+	github.com/spf13/pflag v1.0.5
+	gopkg.in/yaml.v3 v3.0.1
+)
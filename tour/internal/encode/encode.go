@@ -0,0 +1,76 @@
+package encode
+
+import (
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"html"
+	"net/url"
+)
+
+// base64 的几种编码方式
+const (
+	Base64Std    = "std"    // 标准编码，带 = 填充
+	Base64URL    = "url"    // URL 安全编码，带 = 填充
+	Base64RawStd = "rawstd" // 标准编码，不带填充
+	Base64RawURL = "rawurl" // URL 安全编码，不带填充，JWT 使用该编码
+)
+
+func base64Encoding(variant string) (*base64.Encoding, error) {
+	switch variant {
+	case Base64Std, "":
+		return base64.StdEncoding, nil
+	case Base64URL:
+		return base64.URLEncoding, nil
+	case Base64RawStd:
+		return base64.RawStdEncoding, nil
+	case Base64RawURL:
+		return base64.RawURLEncoding, nil
+	}
+	return nil, fmt.Errorf("不支持的 base64 编码方式: %s", variant)
+}
+
+// Base64Encode 按 variant 指定的方式进行 base64 编码
+func Base64Encode(variant string, data []byte) (string, error) {
+	enc, err := base64Encoding(variant)
+	if err != nil {
+		return "", err
+	}
+	return enc.EncodeToString(data), nil
+}
+
+// Base64Decode 按 variant 指定的方式进行 base64 解码
+func Base64Decode(variant string, s string) ([]byte, error) {
+	enc, err := base64Encoding(variant)
+	if err != nil {
+		return nil, err
+	}
+	return enc.DecodeString(s)
+}
+
+func HexEncode(data []byte) string {
+	return hex.EncodeToString(data)
+}
+
+func HexDecode(s string) ([]byte, error) {
+	return hex.DecodeString(s)
+}
+
+// URLEncode 按 URL 查询参数的规则转义，空格转为 +
+func URLEncode(s string) string {
+	return url.QueryEscape(s)
+}
+
+func URLDecode(s string) (string, error) {
+	return url.QueryUnescape(s)
+}
+
+// HTMLEncode 转义 < > & ' " 五个字符
+func HTMLEncode(s string) string {
+	return html.EscapeString(s)
+}
+
+// HTMLDecode 还原所有 HTML 实体，如 &lt;、&#39;、&copy;
+func HTMLDecode(s string) string {
+	return html.UnescapeString(s)
+}
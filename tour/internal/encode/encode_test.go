@@ -0,0 +1,88 @@
+package encode
+
+import (
+	"crypto/md5"
+	"encoding/hex"
+	"testing"
+
+	"github.com/matryer/is"
+)
+
+func TestBase64(t *testing.T) {
+	is := is.New(t)
+
+	cases := []struct {
+		variant, want string
+	}{
+		{Base64Std, "aGk/Pg=="},
+		{Base64URL, "aGk_Pg=="},
+		{Base64RawStd, "aGk/Pg"},
+		{Base64RawURL, "aGk_Pg"},
+	}
+	for _, c := range cases {
+		s, err := Base64Encode(c.variant, []byte("hi?>"))
+		is.NoErr(err)
+		is.Equal(s, c.want)
+
+		data, err := Base64Decode(c.variant, c.want)
+		is.NoErr(err)
+		is.Equal(string(data), "hi?>")
+	}
+
+	_, err := Base64Encode("unknown", nil)
+	is.True(err != nil)
+}
+
+func TestCodec(t *testing.T) {
+	is := is.New(t)
+
+	is.Equal(HexEncode([]byte("go")), "676f")
+	data, err := HexDecode("676f")
+	is.NoErr(err)
+	is.Equal(string(data), "go")
+
+	is.Equal(URLEncode("a b&c=中"), "a+b%26c%3D%E4%B8%AD")
+	s, err := URLDecode("a+b%26c%3D%E4%B8%AD")
+	is.NoErr(err)
+	is.Equal(s, "a b&c=中")
+
+	is.Equal(HTMLEncode(`<a href="x">`), "&lt;a href=&#34;x&#34;&gt;")
+	is.Equal(HTMLDecode("&lt;b&gt;&copy;&#39;"), "<b>©'")
+}
+
+// encodeMD5 同 blog-service 中的 util.EncodeMD5
+func encodeMD5(value string) string {
+	m := md5.New()
+	m.Write([]byte(value))
+	return hex.EncodeToString(m.Sum(nil))
+}
+
+func TestSum(t *testing.T) {
+	is := is.New(t)
+
+	sum, err := Sum(AlgoMD5, []byte("wylu"), nil)
+	is.NoErr(err)
+	is.Equal(HexEncode(sum), encodeMD5("wylu"))
+
+	sum, err = Sum(AlgoSHA256, []byte("The quick brown fox jumps over the lazy dog"), []byte("key"))
+	is.NoErr(err)
+	is.Equal(HexEncode(sum), "f7bc83f430538424b13298e6aa6fb143ef4d59a14946175997479dbc2d1a3cd8")
+
+	_, err = Sum("crc32", nil, nil)
+	is.True(err != nil)
+}
+
+func TestChatroomToken(t *testing.T) {
+	is := is.New(t)
+
+	token := GenChatroomToken(3, "wylu", "secret")
+	uid, err := VerifyChatroomToken(token, "wylu", "secret")
+	is.NoErr(err)
+	is.Equal(uid, 3)
+
+	_, err = VerifyChatroomToken(token, "other", "secret")
+	is.Equal(err, ErrSignatureInvalid)
+
+	_, _, err = ChatroomToken("no-uid")
+	is.True(err != nil)
+}
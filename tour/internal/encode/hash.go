@@ -0,0 +1,52 @@
+package encode
+
+import (
+	"crypto/hmac"
+	"crypto/md5"
+	"crypto/sha1"
+	"crypto/sha256"
+	"crypto/sha512"
+	"fmt"
+	"hash"
+)
+
+const (
+	AlgoMD5    = "md5"
+	AlgoSHA1   = "sha1"
+	AlgoSHA256 = "sha256"
+	AlgoSHA384 = "sha384"
+	AlgoSHA512 = "sha512"
+)
+
+func newHash(algo string) (func() hash.Hash, error) {
+	switch algo {
+	case AlgoMD5:
+		return md5.New, nil
+	case AlgoSHA1:
+		return sha1.New, nil
+	case AlgoSHA256:
+		return sha256.New, nil
+	case AlgoSHA384:
+		return sha512.New384, nil
+	case AlgoSHA512:
+		return sha512.New, nil
+	}
+	return nil, fmt.Errorf("不支持的摘要算法: %s", algo)
+}
+
+// Sum 计算 data 的摘要，key 不为空时计算 HMAC；md5 的十六进制结果与 blog-service 的 util.EncodeMD5 一致
+func Sum(algo string, data, key []byte) ([]byte, error) {
+	h, err := newHash(algo)
+	if err != nil {
+		return nil, err
+	}
+
+	var m hash.Hash
+	if len(key) > 0 {
+		m = hmac.New(h, key)
+	} else {
+		m = h()
+	}
+	m.Write(data)
+	return m.Sum(nil), nil
+}
@@ -0,0 +1,123 @@
+package encode
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+var (
+	ErrSignatureInvalid = errors.New("签名验证失败")
+	ErrNoExpiration     = errors.New("claims 中没有 exp")
+)
+
+// hmacAlgos JWT 中 HMAC 签名算法与摘要算法的对应关系
+var hmacAlgos = map[string]string{
+	"HS256": AlgoSHA256,
+	"HS384": AlgoSHA384,
+	"HS512": AlgoSHA512,
+}
+
+// JWT 解析后的 JSON Web Token，不依赖 jwt-go，只用于调试
+type JWT struct {
+	Header    map[string]interface{}
+	Claims    map[string]interface{}
+	Signature []byte
+
+	// 签名的输入，即 header.claims 两段原文
+	signingInput string
+}
+
+// ParseJWT 解码 token 的 header 和 claims，不做签名验证
+func ParseJWT(token string) (*JWT, error) {
+	parts := strings.Split(strings.TrimSpace(token), ".")
+	if len(parts) != 3 {
+		return nil, errors.New("无效的 JWT: 应由 . 分隔为三段")
+	}
+
+	t := &JWT{signingInput: parts[0] + "." + parts[1]}
+	if err := decodeSegment(parts[0], &t.Header); err != nil {
+		return nil, fmt.Errorf("无效的 JWT header: %v", err)
+	}
+	if err := decodeSegment(parts[1], &t.Claims); err != nil {
+		return nil, fmt.Errorf("无效的 JWT claims: %v", err)
+	}
+
+	sig, err := base64.RawURLEncoding.DecodeString(strings.TrimRight(parts[2], "="))
+	if err != nil {
+		return nil, fmt.Errorf("无效的 JWT 签名: %v", err)
+	}
+	t.Signature = sig
+
+	return t, nil
+}
+
+func decodeSegment(seg string, v interface{}) error {
+	data, err := base64.RawURLEncoding.DecodeString(strings.TrimRight(seg, "="))
+	if err != nil {
+		return err
+	}
+
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	return decoder.Decode(v)
+}
+
+// Alg 返回 header 中的签名算法
+func (t *JWT) Alg() string {
+	alg, _ := t.Header["alg"].(string)
+	return alg
+}
+
+// TimeClaim 返回 exp、iat、nbf 等时间类型的 claim
+func (t *JWT) TimeClaim(name string) (time.Time, bool) {
+	n, ok := t.Claims[name].(json.Number)
+	if !ok {
+		return time.Time{}, false
+	}
+	sec, err := n.Float64()
+	if err != nil {
+		return time.Time{}, false
+	}
+	return time.Unix(int64(sec), 0), true
+}
+
+// CheckExpiration 检查 exp 是否早于 now，返回剩余的有效时间（过期时为负数）
+func (t *JWT) CheckExpiration(now time.Time) (time.Duration, error) {
+	exp, ok := t.TimeClaim("exp")
+	if !ok {
+		return 0, ErrNoExpiration
+	}
+	return exp.Sub(now), nil
+}
+
+// Verify 使用 secret 验证 HMAC 签名，目前只支持 HS256、HS384、HS512
+func (t *JWT) Verify(secret []byte) error {
+	algo, ok := hmacAlgos[t.Alg()]
+	if !ok {
+		return fmt.Errorf("不支持验证 %q 签名", t.Alg())
+	}
+
+	expected, err := Sum(algo, []byte(t.signingInput), secret)
+	if err != nil {
+		return err
+	}
+	if !hmac.Equal(expected, t.Signature) {
+		return ErrSignatureInvalid
+	}
+	return nil
+}
+
+// Pretty 返回缩进格式化后的 JSON
+func Pretty(v interface{}) string {
+	data, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return fmt.Sprintf("%v", v)
+	}
+	return string(data)
+}
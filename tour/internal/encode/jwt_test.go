@@ -0,0 +1,57 @@
+package encode
+
+import (
+	"testing"
+	"time"
+
+	"github.com/matryer/is"
+)
+
+// 来自 jwt.io 的示例 token，密钥为 your-256-bit-secret
+const sampleJWT = "eyJhbGciOiJIUzI1NiIsInR5cCI6IkpXVCJ9." +
+	"eyJzdWIiOiIxMjM0NTY3ODkwIiwibmFtZSI6IkpvaG4gRG9lIiwiaWF0IjoxNTE2MjM5MDIyfQ." +
+	"SflKxwRJSMeKKF2QT4fwpMeJf36POk6yJV_adQssw5c"
+
+func TestParseJWT(t *testing.T) {
+	is := is.New(t)
+
+	token, err := ParseJWT(sampleJWT)
+	is.NoErr(err)
+	is.Equal(token.Alg(), "HS256")
+	is.Equal(token.Claims["name"], "John Doe")
+
+	iat, ok := token.TimeClaim("iat")
+	is.True(ok)
+	is.Equal(iat.Unix(), int64(1516239022))
+
+	_, err = token.CheckExpiration(time.Now())
+	is.Equal(err, ErrNoExpiration)
+
+	is.NoErr(token.Verify([]byte("your-256-bit-secret")))
+	is.Equal(token.Verify([]byte("bad")), ErrSignatureInvalid)
+
+	_, err = ParseJWT("a.b")
+	is.True(err != nil)
+}
+
+func TestJWTExpiration(t *testing.T) {
+	is := is.New(t)
+
+	header, _ := Base64Encode(Base64RawURL, []byte(`{"alg":"HS256","typ":"JWT"}`))
+	claims, _ := Base64Encode(Base64RawURL, []byte(`{"app_key":"x","exp":1633060800,"iss":"blog-service"}`))
+	sig, err := Sum(AlgoSHA256, []byte(header+"."+claims), []byte("eddycjy"))
+	is.NoErr(err)
+	signature, _ := Base64Encode(Base64RawURL, sig)
+
+	token, err := ParseJWT(header + "." + claims + "." + signature)
+	is.NoErr(err)
+	is.NoErr(token.Verify([]byte("eddycjy")))
+
+	left, err := token.CheckExpiration(time.Unix(1633060800-60, 0))
+	is.NoErr(err)
+	is.Equal(left, time.Minute)
+
+	left, err = token.CheckExpiration(time.Unix(1633060800+60, 0))
+	is.NoErr(err)
+	is.True(left < 0)
+}
@@ -0,0 +1,56 @@
+package encode
+
+import (
+	"crypto/hmac"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// ChatroomToken 拆分 chatroom 的 token，格式为 base64(HMAC-SHA256) + "uid" + uid，
+// 见 chatroom/logic/user.go 中的 genToken
+func ChatroomToken(token string) (mac []byte, uid int, err error) {
+	pos := strings.LastIndex(token, "uid")
+	if pos < 0 {
+		return nil, 0, fmt.Errorf("无效的 token: 缺少 uid")
+	}
+
+	mac, err = Base64Decode(Base64Std, token[:pos])
+	if err != nil {
+		return nil, 0, fmt.Errorf("无效的 token: %v", err)
+	}
+	uid, err = strconv.Atoi(token[pos+3:])
+	if err != nil {
+		return nil, 0, fmt.Errorf("无效的 token uid: %v", err)
+	}
+
+	return mac, uid, nil
+}
+
+// VerifyChatroomToken 使用昵称和 token-secret 验证 chatroom 的 token，返回其中的 uid
+func VerifyChatroomToken(token, nickname, secret string) (int, error) {
+	mac, uid, err := ChatroomToken(token)
+	if err != nil {
+		return 0, err
+	}
+
+	expected, err := Sum(AlgoSHA256, chatroomMessage(uid, nickname, secret), []byte(secret))
+	if err != nil {
+		return 0, err
+	}
+	if !hmac.Equal(mac, expected) {
+		return uid, ErrSignatureInvalid
+	}
+	return uid, nil
+}
+
+// GenChatroomToken 与 chatroom 的 genToken 生成相同的 token
+func GenChatroomToken(uid int, nickname, secret string) string {
+	mac, _ := Sum(AlgoSHA256, chatroomMessage(uid, nickname, secret), []byte(secret))
+	token, _ := Base64Encode(Base64Std, mac)
+	return fmt.Sprintf("%suid%d", token, uid)
+}
+
+func chatroomMessage(uid int, nickname, secret string) []byte {
+	return []byte(fmt.Sprintf("%s%s%d", nickname, secret, uid))
+}
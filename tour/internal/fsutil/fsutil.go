@@ -0,0 +1,25 @@
+package fsutil
+
+import (
+	"os"
+	"path/filepath"
+)
+
+// WriteNew 只在文件不存在时写入，返回是否创建了文件，已存在的文件不会被覆盖
+func WriteNew(path string, content []byte) (bool, error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return false, err
+	}
+
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0644)
+	if os.IsExist(err) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	defer f.Close()
+
+	_, err = f.Write(content)
+	return err == nil, err
+}
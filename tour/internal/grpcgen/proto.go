@@ -0,0 +1,344 @@
+package grpcgen
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+)
+
+// StreamKind RPC 的流模式
+type StreamKind int
+
+const (
+	Unary           StreamKind = iota // 一元 RPC
+	ServerStreaming                   // 服务端流式 RPC
+	ClientStreaming                   // 客户端流式 RPC
+	Bidirectional                     // 双向流式 RPC
+)
+
+func (k StreamKind) String() string {
+	switch k {
+	case ServerStreaming:
+		return "server-streaming"
+	case ClientStreaming:
+		return "client-streaming"
+	case Bidirectional:
+		return "bidirectional"
+	}
+	return "unary"
+}
+
+// File 从 .proto 文件中解析出的定义，只包含生成代码所需的部分
+type File struct {
+	Syntax    string
+	Package   string
+	GoPackage string
+	Messages  []string
+	Services  []*Service
+}
+
+type Service struct {
+	Name    string
+	Methods []*Method
+}
+
+type Method struct {
+	Name          string
+	InputType     string
+	OutputType    string
+	ClientStreams bool
+	ServerStreams bool
+}
+
+// Kind 返回方法的流模式
+func (m *Method) Kind() StreamKind {
+	switch {
+	case m.ClientStreams && m.ServerStreams:
+		return Bidirectional
+	case m.ClientStreams:
+		return ClientStreaming
+	case m.ServerStreams:
+		return ServerStreaming
+	}
+	return Unary
+}
+
+type protoParser struct {
+	tokens []string
+	pos    int
+	err    error
+}
+
+// ParseProto 解析 .proto 文件内容中的 package、option go_package、message 和 service
+func ParseProto(src string) (*File, error) {
+	tokens, err := tokenizeProto(src)
+	if err != nil {
+		return nil, err
+	}
+
+	p := &protoParser{tokens: tokens}
+	f := &File{Syntax: "proto2"}
+	for !p.eof() {
+		switch t := p.next(); t {
+		case "syntax":
+			p.expect("=")
+			f.Syntax = unquote(p.next())
+			p.expect(";")
+		case "package":
+			f.Package = p.next()
+			p.expect(";")
+		case "option":
+			stmt := p.statement()
+			if len(stmt) == 3 && stmt[0] == "go_package" && stmt[1] == "=" {
+				f.GoPackage = unquote(stmt[2])
+			}
+		case "message":
+			f.Messages = append(f.Messages, p.next())
+			p.skipBlock()
+		case "service":
+			s, err := p.parseService()
+			if err != nil {
+				return nil, err
+			}
+			f.Services = append(f.Services, s)
+		case "enum", "extend":
+			p.next()
+			p.skipBlock()
+		case ";":
+		default:
+			// import 等其它语句直接跳过
+			p.skipStatement()
+		}
+		if p.err != nil {
+			return nil, p.err
+		}
+	}
+
+	return f, nil
+}
+
+func (p *protoParser) parseService() (*Service, error) {
+	s := &Service{Name: p.next()}
+	p.expect("{")
+	for p.err == nil && !p.eof() {
+		switch t := p.next(); t {
+		case "}":
+			return s, nil
+		case "rpc":
+			m := &Method{Name: p.next()}
+			p.expect("(")
+			if p.peek() == "stream" {
+				p.next()
+				m.ClientStreams = true
+			}
+			m.InputType = p.next()
+			p.expect(")")
+			p.expect("returns")
+			p.expect("(")
+			if p.peek() == "stream" {
+				p.next()
+				m.ServerStreams = true
+			}
+			m.OutputType = p.next()
+			p.expect(")")
+			// rpc 定义以 ; 结尾，或者带有 { option ... } 块
+			if p.peek() == "{" {
+				p.skipBlock()
+			}
+			if p.peek() == ";" {
+				p.next()
+			}
+			s.Methods = append(s.Methods, m)
+		case ";":
+		default:
+			// 服务级别的 option
+			p.skipStatement()
+		}
+	}
+	if p.err != nil {
+		return nil, p.err
+	}
+
+	return nil, fmt.Errorf("service %s 缺少 }", s.Name)
+}
+
+func (p *protoParser) eof() bool {
+	return p.pos >= len(p.tokens)
+}
+
+func (p *protoParser) peek() string {
+	if p.eof() {
+		return ""
+	}
+	return p.tokens[p.pos]
+}
+
+func (p *protoParser) next() string {
+	t := p.peek()
+	p.pos++
+	return t
+}
+
+func (p *protoParser) expect(want string) {
+	if p.err != nil {
+		return
+	}
+	if t := p.next(); t != want {
+		p.err = fmt.Errorf("期望 %q，实际为 %q", want, t)
+	}
+}
+
+// skipBlock 跳过一个 { ... } 块，支持嵌套
+func (p *protoParser) skipBlock() {
+	p.expect("{")
+	depth := 1
+	for p.err == nil && depth > 0 {
+		if p.eof() {
+			p.err = fmt.Errorf("缺少 }")
+			return
+		}
+		switch p.next() {
+		case "{":
+			depth++
+		case "}":
+			depth--
+		}
+	}
+}
+
+// statement 读取一条以 ; 结尾的语句，返回不含 ; 的 token，语句中的 { ... } 块会被整体跳过
+func (p *protoParser) statement() []string {
+	var tokens []string
+	for p.err == nil && !p.eof() {
+		switch p.peek() {
+		case ";":
+			p.next()
+			return tokens
+		case "{":
+			p.skipBlock()
+			return tokens
+		}
+		tokens = append(tokens, p.next())
+	}
+	return tokens
+}
+
+func (p *protoParser) skipStatement() {
+	p.statement()
+}
+
+// tokenizeProto 将 .proto 内容拆分为 token，跳过注释；字符串保留引号
+func tokenizeProto(src string) ([]string, error) {
+	var tokens []string
+	rs := []rune(src)
+	for i := 0; i < len(rs); {
+		r := rs[i]
+		switch {
+		case unicode.IsSpace(r):
+			i++
+		case r == '/' && i+1 < len(rs) && rs[i+1] == '/':
+			for i < len(rs) && rs[i] != '\n' {
+				i++
+			}
+		case r == '/' && i+1 < len(rs) && rs[i+1] == '*':
+			j := i + 2
+			for j+1 < len(rs) && !(rs[j] == '*' && rs[j+1] == '/') {
+				j++
+			}
+			if j+1 >= len(rs) {
+				return nil, fmt.Errorf("未闭合的注释")
+			}
+			i = j + 2
+		case r == '"' || r == '\'':
+			j := i + 1
+			for j < len(rs) && rs[j] != r {
+				if rs[j] == '\\' {
+					j++
+				}
+				j++
+			}
+			if j >= len(rs) {
+				return nil, fmt.Errorf("未闭合的字符串")
+			}
+			tokens = append(tokens, string(rs[i:j+1]))
+			i = j + 1
+		case unicode.IsLetter(r) || unicode.IsDigit(r) || r == '_' || r == '.':
+			j := i
+			for j < len(rs) && (unicode.IsLetter(rs[j]) || unicode.IsDigit(rs[j]) || rs[j] == '_' || rs[j] == '.') {
+				j++
+			}
+			tokens = append(tokens, string(rs[i:j]))
+			i = j
+		default:
+			tokens = append(tokens, string(r))
+			i++
+		}
+	}
+
+	return tokens, nil
+}
+
+func unquote(s string) string {
+	if len(s) >= 2 && (s[0] == '"' || s[0] == '\'') && s[len(s)-1] == s[0] {
+		return s[1 : len(s)-1]
+	}
+	return s
+}
+
+// CamelCase 与 protoc-gen-go 的命名规则一致：去掉下划线并将其后的小写字母大写，
+// 如 say_hello -> SayHello，_my_field -> XMyField
+func CamelCase(s string) string {
+	if s == "" {
+		return ""
+	}
+
+	t := make([]byte, 0, 32)
+	i := 0
+	if s[0] == '_' {
+		t = append(t, 'X')
+		i++
+	}
+	for ; i < len(s); i++ {
+		c := s[i]
+		if c == '_' && i+1 < len(s) && isASCIILower(s[i+1]) {
+			continue
+		}
+		if isASCIIDigit(c) {
+			t = append(t, c)
+			continue
+		}
+		if isASCIILower(c) {
+			c ^= ' '
+		}
+		t = append(t, c)
+		for i+1 < len(s) && isASCIILower(s[i+1]) {
+			i++
+			t = append(t, s[i])
+		}
+	}
+
+	return string(t)
+}
+
+// GoType 返回消息类型在生成代码中的 Go 类型名，pkg 为 .proto 的 package，
+// 如 proto.HelloRequest -> HelloRequest，Outer.Inner -> Outer_Inner
+func GoType(pkg, typ string) string {
+	typ = strings.TrimPrefix(typ, ".")
+	if pkg != "" {
+		typ = strings.TrimPrefix(typ, pkg+".")
+	}
+
+	parts := strings.Split(typ, ".")
+	for i, part := range parts {
+		parts[i] = CamelCase(part)
+	}
+	return strings.Join(parts, "_")
+}
+
+func isASCIILower(c byte) bool {
+	return 'a' <= c && c <= 'z'
+}
+
+func isASCIIDigit(c byte) bool {
+	return '0' <= c && c <= '9'
+}
@@ -0,0 +1,54 @@
+package grpcgen
+
+import (
+	"io/ioutil"
+	"testing"
+
+	"github.com/matryer/is"
+)
+
+func TestParseProto(t *testing.T) {
+	is := is.New(t)
+
+	src, err := ioutil.ReadFile("testdata/greeter.proto")
+	is.NoErr(err)
+
+	f, err := ParseProto(string(src))
+	is.NoErr(err)
+	is.Equal(f.Syntax, "proto3")
+	is.Equal(f.Package, "proto")
+	is.Equal(f.GoPackage, "allkinds/proto")
+	is.Equal(f.Messages, []string{"HelloRequest", "HelloReply"})
+	is.Equal(len(f.Services), 1)
+
+	s := f.Services[0]
+	is.Equal(s.Name, "Greeter")
+	is.Equal(len(s.Methods), 4)
+
+	kinds := []StreamKind{Unary, ServerStreaming, ClientStreaming, Bidirectional}
+	for i, m := range s.Methods {
+		is.Equal(m.Kind(), kinds[i])
+	}
+	is.Equal(s.Methods[3].Name, "say_route")
+	is.Equal(s.Methods[3].InputType, "proto.HelloRequest")
+}
+
+func TestParseProtoInvalid(t *testing.T) {
+	is := is.New(t)
+
+	_, err := ParseProto(`service Greeter { rpc SayHello (HelloRequest) HelloReply; }`)
+	is.True(err != nil)
+
+	_, err = ParseProto(`message HelloRequest { string name = 1;`)
+	is.True(err != nil)
+}
+
+func TestCamelCase(t *testing.T) {
+	is := is.New(t)
+
+	is.Equal(CamelCase("say_hello"), "SayHello")
+	is.Equal(CamelCase("SayHTTP"), "SayHTTP")
+	is.Equal(CamelCase("_my_field"), "XMyField")
+	is.Equal(GoType("proto", "proto.HelloRequest"), "HelloRequest")
+	is.Equal(GoType("proto", "Outer.inner_msg"), "Outer_InnerMsg")
+}
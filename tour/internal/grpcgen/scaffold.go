@@ -0,0 +1,394 @@
+package grpcgen
+
+import (
+	"bytes"
+	"fmt"
+	"go/format"
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+	"text/template"
+	"unicode"
+
+	"tour/internal/fsutil"
+)
+
+const goModTpl = `module {{.Module}}
+
+go 1.15
+
+require (
+	github.com/golang/protobuf v1.4.3
+	google.golang.org/grpc v1.40.0
+)
+`
+
+const serverTpl = `package main
+
+import (
+{{- if .HasKind "unary"}}
+	"context"
+{{- end}}
+	"flag"
+{{- if or (.HasKind "client-streaming") (.HasKind "bidirectional")}}
+	"io"
+{{- end}}
+	"log"
+	"net"
+
+	pb "{{.Module}}/proto"
+
+	"google.golang.org/grpc"
+)
+
+var (
+	host string
+	port string
+)
+
+func init() {
+	flag.StringVar(&host, "host", "localhost", "监听地址")
+	flag.StringVar(&port, "port", "8000", "监听端口")
+	flag.Parse()
+}
+{{range $s := .Services}}
+type {{$s.Name}}Server struct{}
+{{range $s.Methods}}
+{{- if eq .Kind "unary"}}
+// {{.Name}} 一元 RPC
+func (s *{{$s.Name}}Server) {{.Name}}(ctx context.Context, req *pb.{{.Input}}) (*pb.{{.Output}}, error) {
+	log.Printf("{{.Name}} receive: %v", req)
+	// TODO: 实现业务逻辑
+	return &pb.{{.Output}}{}, nil
+}
+{{- else if eq .Kind "server-streaming"}}
+// {{.Name}} 服务端流式 RPC
+func (s *{{$s.Name}}Server) {{.Name}}(req *pb.{{.Input}}, stream pb.{{$s.Name}}_{{.Name}}Server) error {
+	log.Printf("{{.Name}} receive: %v", req)
+	// TODO: 实现业务逻辑，按需多次调用 stream.Send
+	return stream.Send(&pb.{{.Output}}{})
+}
+{{- else if eq .Kind "client-streaming"}}
+// {{.Name}} 客户端流式 RPC
+func (s *{{$s.Name}}Server) {{.Name}}(stream pb.{{$s.Name}}_{{.Name}}Server) error {
+	for {
+		req, err := stream.Recv()
+		if err == io.EOF {
+			// TODO: 实现业务逻辑
+			return stream.SendAndClose(&pb.{{.Output}}{})
+		}
+		if err != nil {
+			return err
+		}
+
+		log.Printf("{{.Name}} receive: %v", req)
+	}
+}
+{{- else}}
+// {{.Name}} 双向流式 RPC
+func (s *{{$s.Name}}Server) {{.Name}}(stream pb.{{$s.Name}}_{{.Name}}Server) error {
+	for {
+		req, err := stream.Recv()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		log.Printf("{{.Name}} receive: %v", req)
+		// TODO: 实现业务逻辑
+		if err := stream.Send(&pb.{{.Output}}{}); err != nil {
+			return err
+		}
+	}
+}
+{{- end}}
+{{end}}
+{{- end}}
+func main() {
+	server := grpc.NewServer()
+{{- range .Services}}
+	pb.Register{{.Name}}Server(server, &{{.Name}}Server{})
+{{- end}}
+
+	lis, err := net.Listen("tcp", host+":"+port)
+	if err != nil {
+		log.Fatalln(err)
+	}
+	log.Printf("Server Listen: %v:%v", host, port)
+	if err := server.Serve(lis); err != nil {
+		log.Fatalln(err)
+	}
+}
+`
+
+const clientTpl = `package main
+
+import (
+	"context"
+	"flag"
+{{- if .HasKind "server-streaming"}}
+	"io"
+{{- end}}
+	"log"
+
+	pb "{{.Module}}/proto"
+
+	"google.golang.org/grpc"
+)
+
+var (
+	host string
+	port string
+)
+
+func init() {
+	flag.StringVar(&host, "host", "localhost", "服务端地址")
+	flag.StringVar(&port, "port", "8000", "服务端端口")
+	flag.Parse()
+}
+
+func main() {
+	conn, err := grpc.Dial(host+":"+port, grpc.WithInsecure())
+	if err != nil {
+		log.Fatalln(err)
+	}
+	defer conn.Close()
+{{range $s := .Services}}
+	{{$s.VarName}} := pb.New{{$s.Name}}Client(conn)
+{{- range $s.Methods}}
+	if err := call{{$s.Name}}{{.Name}}({{$s.VarName}}); err != nil {
+		log.Fatalf("{{$s.Name}}.{{.Name}} err: %v", err)
+	}
+{{- end}}
+{{- end}}
+}
+{{range $s := .Services}}{{range $s.Methods}}
+func call{{$s.Name}}{{.Name}}(client pb.{{$s.Name}}Client) error {
+{{- if eq .Kind "unary"}}
+	resp, err := client.{{.Name}}(context.Background(), &pb.{{.Input}}{})
+	if err != nil {
+		return err
+	}
+
+	log.Printf("{{.Name}} resp: %v", resp)
+	return nil
+{{- else if eq .Kind "server-streaming"}}
+	stream, err := client.{{.Name}}(context.Background(), &pb.{{.Input}}{})
+	if err != nil {
+		return err
+	}
+
+	for {
+		resp, err := stream.Recv()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		log.Printf("{{.Name}} resp: %v", resp)
+	}
+{{- else if eq .Kind "client-streaming"}}
+	stream, err := client.{{.Name}}(context.Background())
+	if err != nil {
+		return err
+	}
+
+	for i := 0; i < 3; i++ {
+		if err := stream.Send(&pb.{{.Input}}{}); err != nil {
+			return err
+		}
+	}
+
+	resp, err := stream.CloseAndRecv()
+	if err != nil {
+		return err
+	}
+
+	log.Printf("{{.Name}} resp: %v", resp)
+	return nil
+{{- else}}
+	stream, err := client.{{.Name}}(context.Background())
+	if err != nil {
+		return err
+	}
+
+	for i := 0; i < 3; i++ {
+		if err := stream.Send(&pb.{{.Input}}{}); err != nil {
+			return err
+		}
+
+		resp, err := stream.Recv()
+		if err != nil {
+			return err
+		}
+
+		log.Printf("{{.Name}} resp: %v", resp)
+	}
+
+	return stream.CloseSend()
+{{- end}}
+}
+{{end}}{{end}}`
+
+type scaffoldData struct {
+	Module   string
+	Services []*serviceData
+}
+
+type serviceData struct {
+	Name    string
+	VarName string
+	Methods []*methodData
+}
+
+type methodData struct {
+	Name   string
+	Input  string
+	Output string
+	Kind   string
+}
+
+// HasKind 判断是否存在指定流模式的方法，用于决定需要导入的包
+func (d *scaffoldData) HasKind(kind string) bool {
+	for _, s := range d.Services {
+		for _, m := range s.Methods {
+			if m.Kind == kind {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// Scaffold 根据 .proto 中的 service 定义生成 gRPC 服务端、客户端和 go.mod
+type Scaffold struct {
+	// 生成项目的 module 名，服务端和客户端通过 <Module>/proto 引用 protoc 生成的代码
+	Module string
+	// 生成项目的目录
+	OutDir string
+}
+
+// Result 记录生成的文件，已存在的文件不会被覆盖，记录在 Skipped 中
+type Result struct {
+	Created []string
+	Skipped []string
+	// 生成 pb 代码的 protoc 命令，需要在 OutDir 下执行
+	ProtocCmd string
+}
+
+// Generate 解析 protoPath 并在 OutDir 下生成 go.mod、proto/、server/、client/
+func (s *Scaffold) Generate(protoPath string) (*Result, error) {
+	src, err := ioutil.ReadFile(protoPath)
+	if err != nil {
+		return nil, err
+	}
+
+	f, err := ParseProto(string(src))
+	if err != nil {
+		return nil, fmt.Errorf("解析 %s 失败: %v", protoPath, err)
+	}
+	if len(f.Services) == 0 {
+		return nil, fmt.Errorf("%s 中没有 service 定义", protoPath)
+	}
+
+	data, err := newScaffoldData(s.Module, f)
+	if err != nil {
+		return nil, err
+	}
+
+	protoFile := filepath.Join("proto", filepath.Base(protoPath))
+	files := map[string][]byte{protoFile: src}
+	order := []string{"go.mod", protoFile, filepath.Join("server", "server.go"), filepath.Join("client", "client.go")}
+	for name, tpl := range map[string]string{
+		order[0]: goModTpl,
+		order[2]: serverTpl,
+		order[3]: clientTpl,
+	} {
+		content, err := render(tpl, data, strings.HasSuffix(name, ".go"))
+		if err != nil {
+			return nil, fmt.Errorf("生成 %s 失败: %v", name, err)
+		}
+		files[name] = content
+	}
+
+	result := &Result{
+		ProtocCmd: "protoc --go_out=plugins=grpc,paths=source_relative:. " + filepath.ToSlash(protoFile),
+	}
+	for _, name := range order {
+		path := filepath.Join(s.OutDir, name)
+		created, err := fsutil.WriteNew(path, files[name])
+		if err != nil {
+			return nil, err
+		}
+		if created {
+			result.Created = append(result.Created, path)
+		} else {
+			result.Skipped = append(result.Skipped, path)
+		}
+	}
+
+	return result, nil
+}
+
+func newScaffoldData(module string, f *File) (*scaffoldData, error) {
+	data := &scaffoldData{Module: module}
+	for _, s := range f.Services {
+		name := CamelCase(s.Name)
+		sd := &serviceData{
+			Name:    name,
+			VarName: string(unicode.ToLower(rune(name[0]))) + name[1:] + "Client",
+		}
+		for _, m := range s.Methods {
+			input, err := goType(f.Package, m.InputType)
+			if err != nil {
+				return nil, err
+			}
+			output, err := goType(f.Package, m.OutputType)
+			if err != nil {
+				return nil, err
+			}
+
+			sd.Methods = append(sd.Methods, &methodData{
+				Name:   CamelCase(m.Name),
+				Input:  input,
+				Output: output,
+				Kind:   m.Kind().String(),
+			})
+		}
+		data.Services = append(data.Services, sd)
+	}
+
+	return data, nil
+}
+
+// goType 同 GoType，引用其它 package 的消息（如 google.protobuf.Empty）暂不支持
+func goType(pkg, typ string) (string, error) {
+	t := strings.TrimPrefix(typ, ".")
+	if pkg != "" {
+		t = strings.TrimPrefix(t, pkg+".")
+	}
+	if i := strings.Index(t, "."); i > 0 && unicode.IsLower(rune(t[0])) {
+		return "", fmt.Errorf("暂不支持引用其它 package 的消息类型: %s", typ)
+	}
+	return GoType(pkg, typ), nil
+}
+
+func render(tpl string, data interface{}, isGo bool) ([]byte, error) {
+	t, err := template.New("grpcgen").Parse(tpl)
+	if err != nil {
+		return nil, err
+	}
+
+	var buf bytes.Buffer
+	if err := t.Execute(&buf, data); err != nil {
+		return nil, err
+	}
+	if !isGo {
+		return buf.Bytes(), nil
+	}
+	return format.Source(buf.Bytes())
+}
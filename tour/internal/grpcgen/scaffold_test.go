@@ -0,0 +1,63 @@
+package grpcgen
+
+import (
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/matryer/is"
+)
+
+func TestScaffoldGenerate(t *testing.T) {
+	is := is.New(t)
+
+	dir := t.TempDir()
+	s := &Scaffold{Module: "allkinds", OutDir: dir}
+	result, err := s.Generate("testdata/greeter.proto")
+	is.NoErr(err)
+	is.Equal(len(result.Created), 4)
+	is.Equal(len(result.Skipped), 0)
+	is.Equal(result.ProtocCmd, "protoc --go_out=plugins=grpc,paths=source_relative:. proto/greeter.proto")
+
+	server, err := ioutil.ReadFile(filepath.Join(dir, "server", "server.go"))
+	is.NoErr(err)
+	for _, sig := range []string{
+		"func (s *GreeterServer) SayHello(ctx context.Context, req *pb.HelloRequest) (*pb.HelloReply, error) {",
+		"func (s *GreeterServer) SayList(req *pb.HelloRequest, stream pb.Greeter_SayListServer) error {",
+		"func (s *GreeterServer) SayRecord(stream pb.Greeter_SayRecordServer) error {",
+		"func (s *GreeterServer) SayRoute(stream pb.Greeter_SayRouteServer) error {",
+		"pb.RegisterGreeterServer(server, &GreeterServer{})",
+		`pb "allkinds/proto"`,
+	} {
+		is.True(strings.Contains(string(server), sig))
+	}
+
+	client, err := ioutil.ReadFile(filepath.Join(dir, "client", "client.go"))
+	is.NoErr(err)
+	is.True(strings.Contains(string(client), "stream.CloseAndRecv()"))
+	is.True(strings.Contains(string(client), "greeterClient := pb.NewGreeterClient(conn)"))
+
+	goMod, err := ioutil.ReadFile(filepath.Join(dir, "go.mod"))
+	is.NoErr(err)
+	is.True(strings.HasPrefix(string(goMod), "module allkinds\n"))
+
+	// 再次生成时不会覆盖已有文件
+	is.NoErr(ioutil.WriteFile(filepath.Join(dir, "go.mod"), []byte("module edited\n"), 0644))
+	result, err = s.Generate("testdata/greeter.proto")
+	is.NoErr(err)
+	is.Equal(len(result.Created), 0)
+	is.Equal(len(result.Skipped), 4)
+	goMod, err = ioutil.ReadFile(filepath.Join(dir, "go.mod"))
+	is.NoErr(err)
+	is.Equal(string(goMod), "module edited\n")
+}
+
+func TestScaffoldUnsupportedType(t *testing.T) {
+	is := is.New(t)
+
+	f, err := ParseProto(`package proto; service S { rpc Ping (google.protobuf.Empty) returns (Pong); }`)
+	is.NoErr(err)
+	_, err = newScaffoldData("m", f)
+	is.True(err != nil)
+}
@@ -0,0 +1,143 @@
+package json2struct
+
+import (
+	"bytes"
+	"fmt"
+	"go/format"
+	"io"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"tour/internal/word"
+)
+
+type structDef struct {
+	name   string
+	fields []string
+}
+
+type generator struct {
+	structs []*structDef
+	names   map[string]bool
+}
+
+// Generate 根据合并后的样本生成 Go 类型定义并写入 w，name 为根类型名，
+// pkg 不为空时会输出 package 声明
+func (p *Parser) Generate(w io.Writer, name, pkg string) error {
+	g := &generator{names: make(map[string]bool)}
+
+	var buf bytes.Buffer
+	if pkg != "" {
+		fmt.Fprintf(&buf, "package %s\n\n", pkg)
+	}
+
+	root := p.root
+	switch {
+	case root.kinds&^kindNull == kindObject:
+		g.defineStruct(root, name, "")
+	case root.kinds&^kindNull == kindArray && root.elem != nil && root.elem.kinds&^kindNull == kindObject:
+		// 顶层为对象数组时，以数组元素作为根类型
+		g.defineStruct(root.elem, name, "")
+	default:
+		g.names[name] = true
+		fmt.Fprintf(&buf, "type %s %s\n\n", name, g.typeOf(root, name, ""))
+	}
+
+	for _, s := range g.structs {
+		fmt.Fprintf(&buf, "type %s struct {\n%s}\n\n", s.name, strings.Join(s.fields, ""))
+	}
+
+	src, err := format.Source(buf.Bytes())
+	if err != nil {
+		return err
+	}
+
+	_, err = w.Write(src)
+	return err
+}
+
+// typeOf 返回 n 对应的 Go 类型，对象会以 name 为名生成新的结构体
+func (g *generator) typeOf(n *node, name, parent string) string {
+	var typ string
+	switch n.kinds &^ kindNull {
+	case kindBool:
+		typ = "bool"
+	case kindInt:
+		typ = "int"
+	case kindFloat, kindInt | kindFloat:
+		typ = "float64"
+	case kindString:
+		typ = "string"
+	case kindObject:
+		typ = g.defineStruct(n, name, parent)
+	case kindArray:
+		elem := "interface{}"
+		if n.elem != nil && n.elem.kinds != 0 {
+			elem = g.typeOf(n.elem, name, parent)
+		}
+		return "[]" + elem
+	default:
+		// 只出现过 null，或者多种类型混合
+		return "interface{}"
+	}
+
+	if n.kinds&kindNull != 0 {
+		typ = "*" + typ
+	}
+	return typ
+}
+
+// defineStruct 为对象生成结构体定义，返回结构体名
+func (g *generator) defineStruct(n *node, name, parent string) string {
+	structName := g.uniqueName(name, parent)
+	s := &structDef{name: structName}
+	g.structs = append(g.structs, s)
+
+	used := make(map[string]bool)
+	for _, key := range n.order {
+		f := n.fields[key]
+
+		fieldName := FieldName(key)
+		for i := 2; used[fieldName]; i++ {
+			fieldName = FieldName(key) + strconv.Itoa(i)
+		}
+		used[fieldName] = true
+
+		tag := key
+		if f.count < n.objects {
+			tag += ",omitempty"
+		}
+
+		typ := g.typeOf(f.node, fieldName, structName)
+		s.fields = append(s.fields, fmt.Sprintf("\t%s %s `json:%q`\n", fieldName, typ, tag))
+	}
+
+	return structName
+}
+
+// uniqueName 避免不同位置的对象生成同名的结构体，冲突时加上父结构体名作为前缀
+func (g *generator) uniqueName(name, parent string) string {
+	candidate := name
+	if g.names[candidate] {
+		candidate = parent + name
+	}
+	for i := 2; g.names[candidate]; i++ {
+		candidate = parent + name + strconv.Itoa(i)
+	}
+	g.names[candidate] = true
+
+	return candidate
+}
+
+// FieldName 将 JSON 字段名转换为可导出的 Go 字段名
+func FieldName(key string) string {
+	name := word.ToGoName(key)
+	if name == "" {
+		return "Field"
+	}
+	if unicode.IsDigit([]rune(name)[0]) {
+		return "Field" + name
+	}
+	return name
+}
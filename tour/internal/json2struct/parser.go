@@ -0,0 +1,182 @@
+package json2struct
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// kind 记录某个位置上出现过的 JSON 值类型，可按位组合
+type kind uint8
+
+const (
+	kindNull kind = 1 << iota
+	kindBool
+	kindInt
+	kindFloat
+	kindString
+	kindObject
+	kindArray
+)
+
+// node 是对同一位置上所有样本值合并后的类型描述
+type node struct {
+	kinds kind
+
+	// 对象：字段按首次出现的顺序保存，objects 为合并过的对象个数
+	fields  map[string]*field
+	order   []string
+	objects int
+
+	// 数组：所有元素合并后的类型
+	elem *node
+}
+
+type field struct {
+	node *node
+	// 出现该字段的对象个数，小于所在对象的 objects 时说明字段可缺省
+	count int
+}
+
+// Parser 合并多个 JSON 样本，推断出对应的 Go 类型
+type Parser struct {
+	root *node
+}
+
+func NewParser() *Parser {
+	return &Parser{root: &node{}}
+}
+
+// object 保留了字段顺序的 JSON 对象
+type object struct {
+	keys   []string
+	values map[string]interface{}
+}
+
+// Parse 读取 r 中的一个或多个 JSON 文档，逐个作为样本合并
+func (p *Parser) Parse(r io.Reader) error {
+	decoder := json.NewDecoder(r)
+	decoder.UseNumber()
+	for decoder.More() {
+		v, err := decodeValue(decoder)
+		if err != nil {
+			return err
+		}
+		p.root.merge(v)
+	}
+
+	// More 遇到非法内容时也会返回 false，这里再读一次以暴露错误
+	t, err := decoder.Token()
+	if err == io.EOF {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	return fmt.Errorf("无效的 JSON: 多余的 %v", t)
+}
+
+// ParseBytes 同 Parse，数据来源为字节切片
+func (p *Parser) ParseBytes(data []byte) error {
+	return p.Parse(bytes.NewReader(data))
+}
+
+func (n *node) merge(v interface{}) {
+	switch val := v.(type) {
+	case nil:
+		n.kinds |= kindNull
+	case bool:
+		n.kinds |= kindBool
+	case json.Number:
+		if isInt(val) {
+			n.kinds |= kindInt
+		} else {
+			n.kinds |= kindFloat
+		}
+	case string:
+		n.kinds |= kindString
+	case *object:
+		n.kinds |= kindObject
+		n.mergeObject(val)
+	case []interface{}:
+		n.kinds |= kindArray
+		if n.elem == nil {
+			n.elem = &node{}
+		}
+		for _, item := range val {
+			n.elem.merge(item)
+		}
+	}
+}
+
+func (n *node) mergeObject(obj *object) {
+	if n.fields == nil {
+		n.fields = make(map[string]*field)
+	}
+	n.objects++
+
+	for _, key := range obj.keys {
+		f, ok := n.fields[key]
+		if !ok {
+			f = &field{node: &node{}}
+			n.fields[key] = f
+			n.order = append(n.order, key)
+		}
+		f.count++
+		f.node.merge(obj.values[key])
+	}
+}
+
+// decodeValue 按 token 解码一个 JSON 值，对象解码为 *object 以保留字段顺序
+func decodeValue(decoder *json.Decoder) (interface{}, error) {
+	t, err := decoder.Token()
+	if err != nil {
+		return nil, err
+	}
+
+	switch t {
+	case json.Delim('{'):
+		obj := &object{values: make(map[string]interface{})}
+		for decoder.More() {
+			t, err := decoder.Token()
+			if err != nil {
+				return nil, err
+			}
+			key := t.(string)
+			v, err := decodeValue(decoder)
+			if err != nil {
+				return nil, err
+			}
+			if _, ok := obj.values[key]; !ok {
+				obj.keys = append(obj.keys, key)
+			}
+			obj.values[key] = v
+		}
+		_, err = decoder.Token()
+		return obj, err
+	case json.Delim('['):
+		arr := []interface{}{}
+		for decoder.More() {
+			v, err := decodeValue(decoder)
+			if err != nil {
+				return nil, err
+			}
+			arr = append(arr, v)
+		}
+		_, err = decoder.Token()
+		return arr, err
+	}
+
+	return t, nil
+}
+
+// isInt 判断数字是否可以用 int64 表示
+func isInt(n json.Number) bool {
+	if strings.ContainsAny(string(n), ".eE") {
+		return false
+	}
+	_, err := n.Int64()
+	return err == nil
+}
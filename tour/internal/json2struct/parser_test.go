@@ -0,0 +1,93 @@
+package json2struct
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/matryer/is"
+)
+
+func generate(t *testing.T, samples ...string) string {
+	is := is.New(t)
+
+	p := NewParser()
+	for _, sample := range samples {
+		is.NoErr(p.ParseBytes([]byte(sample)))
+	}
+
+	var buf bytes.Buffer
+	is.NoErr(p.Generate(&buf, "Tour", ""))
+
+	// 压缩 gofmt 对齐产生的空白，便于断言
+	lines := strings.Split(buf.String(), "\n")
+	for i, line := range lines {
+		lines[i] = strings.Join(strings.Fields(line), " ")
+	}
+	return strings.Join(lines, "\n")
+}
+
+func TestGenerateNested(t *testing.T) {
+	is := is.New(t)
+
+	src := generate(t, `{"id": 1, "user_name": "wylu", "tags": [{"name": "go"}], "pager": {"page": 1}}`)
+
+	is.True(strings.Contains(src, "type Tour struct {"))
+	is.True(strings.Contains(src, "UserName string"))
+	is.True(strings.Contains(src, "Tags []Tags"))
+	is.True(strings.Contains(src, "Pager Pager"))
+	is.True(strings.Contains(src, "type Tags struct {\nName string `json:\"name\"`\n}"))
+	// 字段顺序与样本一致
+	is.True(strings.Index(src, "ID ") < strings.Index(src, "UserName"))
+}
+
+func TestGenerateMerge(t *testing.T) {
+	is := is.New(t)
+
+	src := generate(t,
+		`{"id": 1, "price": 10, "cover": "a.png", "pager": {"page": 1}}`,
+		`{"id": 2, "price": 9.9, "cover": null, "pager": null, "desc": "x"}`,
+	)
+
+	is.True(strings.Contains(src, "ID int `json:\"id\"`"))
+	is.True(strings.Contains(src, "Price float64 `json:\"price\"`"))
+	is.True(strings.Contains(src, "Cover *string `json:\"cover\"`"))
+	is.True(strings.Contains(src, "Pager *Pager `json:\"pager\"`"))
+	is.True(strings.Contains(src, "Desc string `json:\"desc,omitempty\"`"))
+}
+
+func TestGenerateTopLevelArray(t *testing.T) {
+	is := is.New(t)
+
+	src := generate(t, `[{"id": 1}, {"id": 2, "name": "go"}]`)
+
+	is.True(strings.Contains(src, "type Tour struct {"))
+	is.True(strings.Contains(src, "Name string `json:\"name,omitempty\"`"))
+}
+
+func TestGenerateNameConflict(t *testing.T) {
+	is := is.New(t)
+
+	src := generate(t, `{"data": {"id": 1}, "list": [{"data": {"name": "go"}}]}`)
+
+	is.True(strings.Contains(src, "type Data struct {"))
+	is.True(strings.Contains(src, "type ListData struct {"))
+}
+
+func TestParseInvalid(t *testing.T) {
+	is := is.New(t)
+
+	p := NewParser()
+	is.True(p.ParseBytes([]byte(`{"id": 1`)) != nil)
+	is.True(p.ParseBytes([]byte(`{"id": 1}}`)) != nil)
+}
+
+func TestFieldName(t *testing.T) {
+	is := is.New(t)
+
+	is.Equal(FieldName("user_name"), "UserName")
+	is.Equal(FieldName("cover-image"), "CoverImage")
+	is.Equal(FieldName("1st"), "Field1st")
+	is.Equal(FieldName("cover_url"), "CoverURL")
+	is.Equal(FieldName("$"), "Field")
+}
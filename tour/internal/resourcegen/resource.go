@@ -0,0 +1,360 @@
+package resourcegen
+
+import (
+	"bytes"
+	"fmt"
+	"go/format"
+	"go/token"
+	"io/ioutil"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"text/template"
+
+	"tour/internal/fsutil"
+	"tour/internal/word"
+)
+
+// fieldTypes 支持的字段类型及其 swagger 类型
+var fieldTypes = map[string]string{
+	"string":  "string",
+	"bool":    "boolean",
+	"int":     "int",
+	"int8":    "int",
+	"int16":   "int",
+	"int32":   "int",
+	"int64":   "int",
+	"uint":    "int",
+	"uint8":   "int",
+	"uint16":  "int",
+	"uint32":  "int",
+	"uint64":  "int",
+	"float32": "number",
+	"float64": "number",
+}
+
+// reservedColumns 已由 model.Model、State 或者生成代码中的参数占用的字段名
+var reservedColumns = map[string]bool{
+	"id":          true,
+	"created_by":  true,
+	"modified_by": true,
+	"created_on":  true,
+	"modified_on": true,
+	"deleted_on":  true,
+	"is_del":      true,
+	"state":       true,
+	"page":        true,
+	"page_size":   true,
+	"values":      true,
+}
+
+// reservedNames 生成代码中已使用的变量名和包名
+var reservedNames = map[string]bool{
+	"d":          true,
+	"db":         true,
+	"app":        true,
+	"model":      true,
+	"service":    true,
+	"global":     true,
+	"convert":    true,
+	"errcode":    true,
+	"gin":        true,
+	"param":      true,
+	"response":   true,
+	"svc":        true,
+	"valid":      true,
+	"errs":       true,
+	"err":        true,
+	"pager":      true,
+	"count":      true,
+	"totalRows":  true,
+	"pageOffset": true,
+}
+
+var errCodeRegexp = regexp.MustCompile(`NewError\((\d{4})\d{4},`)
+
+// Resource 描述要生成的 blog-service 资源
+type Resource struct {
+	// 资源名，如 Comment
+	Name string
+	// 字段定义，形如 content:string,article_id:uint32
+	Fields string
+	// 资源的中文描述，用于 swagger 注释和错误信息，默认为 Name
+	Desc string
+	// 表名前缀，如 blog_
+	TablePrefix string
+	// blog-service 的 module 名
+	Module string
+	// blog-service 项目的根目录
+	Dir string
+}
+
+// Result 记录生成的文件，已存在的文件不会被覆盖，记录在 Skipped 中
+type Result struct {
+	Created []string
+	Skipped []string
+	// 需要手动添加到 routers.NewRouter 中的代码
+	RouterLines []string
+}
+
+type resourceData struct {
+	Module      string
+	Name        string
+	Var         string
+	PluralVar   string
+	Recv        string
+	HandlerRecv string
+	Path        string
+	Table       string
+	Desc        string
+	ErrModule   int
+	Fields      []*fieldData
+}
+
+type fieldData struct {
+	Name        string
+	Column      string
+	Param       string
+	Type        string
+	SwaggerType string
+	Zero        string
+	// bool 字段无法区分零值和未传值，不作为查询条件
+	Filter  bool
+	Binding string
+}
+
+// Generate 在 Dir 下生成 model、dao、service、v1 handler 和错误码
+func (r *Resource) Generate() (*Result, error) {
+	data, err := r.newData()
+	if err != nil {
+		return nil, err
+	}
+
+	file := word.CamelCaseToUnderscore(r.Name) + ".go"
+	targets := []struct {
+		path string
+		tpl  string
+	}{
+		{filepath.Join("internal", "model", file), modelTpl},
+		{filepath.Join("internal", "dao", file), daoTpl},
+		{filepath.Join("internal", "service", file), serviceTpl},
+		{filepath.Join("internal", "routers", "api", "v1", file), handlerTpl},
+		{filepath.Join("pkg", "errcode", strings.TrimSuffix(file, ".go")+"_code.go"), errcodeTpl},
+	}
+
+	contents := make([][]byte, len(targets))
+	for i, target := range targets {
+		content, err := render(target.tpl, data)
+		if err != nil {
+			return nil, fmt.Errorf("生成 %s 失败: %v", target.path, err)
+		}
+		contents[i] = content
+	}
+
+	result := &Result{
+		RouterLines: []string{
+			fmt.Sprintf("%s := v1.New%s()", data.Var, data.Name),
+			fmt.Sprintf("apiv1.POST(\"/%s\", %s.Create)", data.Path, data.Var),
+			fmt.Sprintf("apiv1.DELETE(\"/%s/:id\", %s.Delete)", data.Path, data.Var),
+			fmt.Sprintf("apiv1.PUT(\"/%s/:id\", %s.Update)", data.Path, data.Var),
+			fmt.Sprintf("apiv1.PATCH(\"/%s/:id/state\", %s.Update)", data.Path, data.Var),
+			fmt.Sprintf("apiv1.GET(\"/%s/:id\", %s.Get)", data.Path, data.Var),
+			fmt.Sprintf("apiv1.GET(\"/%s\", %s.List)", data.Path, data.Var),
+		},
+	}
+	for i, target := range targets {
+		path := filepath.Join(r.Dir, target.path)
+		created, err := fsutil.WriteNew(path, contents[i])
+		if err != nil {
+			return nil, err
+		}
+		if created {
+			result.Created = append(result.Created, path)
+		} else {
+			result.Skipped = append(result.Skipped, path)
+		}
+	}
+
+	return result, nil
+}
+
+func (r *Resource) newData() (*resourceData, error) {
+	name := word.ToGoName(r.Name)
+	if name == "" || !token.IsIdentifier(name) {
+		return nil, fmt.Errorf("无效的资源名: %q", r.Name)
+	}
+
+	data := &resourceData{
+		Module: r.Module,
+		Name:   name,
+		Var:    lowerGoName(name),
+		Recv:   strings.ToLower(name[:1]),
+		Path:   pluralize(word.CamelCaseToUnderscore(name)),
+		Table:  r.TablePrefix + word.CamelCaseToUnderscore(name),
+		Desc:   r.Desc,
+	}
+	data.PluralVar = lowerGoName(pluralize(word.CamelCaseToUnderscore(name)))
+	if data.Desc == "" {
+		data.Desc = name
+	}
+
+	// handler 中 c 已被 *gin.Context 占用
+	data.HandlerRecv = data.Recv
+	if data.HandlerRecv == "c" {
+		data.HandlerRecv = strings.ToLower(name[:2])
+	}
+	if reservedNames[data.Var] || reservedNames[data.PluralVar] {
+		return nil, fmt.Errorf("资源名 %s 与生成代码中的变量冲突", name)
+	}
+
+	fields, err := parseFields(r.Fields)
+	if err != nil {
+		return nil, err
+	}
+	for _, f := range fields {
+		if f.Param == data.Var || f.Param == data.PluralVar {
+			return nil, fmt.Errorf("字段 %s 与资源名 %s 冲突", f.Column, name)
+		}
+	}
+	data.Fields = fields
+
+	data.ErrModule, err = nextErrModule(filepath.Join(r.Dir, "pkg", "errcode"))
+	if err != nil {
+		return nil, err
+	}
+
+	return data, nil
+}
+
+// parseFields 解析 content:string,article_id:uint32 形式的字段定义
+func parseFields(s string) ([]*fieldData, error) {
+	var fields []*fieldData
+	seen := make(map[string]bool)
+	for _, def := range strings.Split(s, ",") {
+		def = strings.TrimSpace(def)
+		if def == "" {
+			continue
+		}
+
+		parts := strings.Split(def, ":")
+		if len(parts) != 2 {
+			return nil, fmt.Errorf("无效的字段定义 %q，格式应为 name:type", def)
+		}
+		column := word.CamelCaseToUnderscore(parts[0])
+		typ := strings.TrimSpace(parts[1])
+		swaggerType, ok := fieldTypes[typ]
+		if !ok {
+			return nil, fmt.Errorf("字段 %s 的类型 %s 不受支持", column, typ)
+		}
+		if column == "" || reservedColumns[column] || reservedNames[lowerGoName(column)] {
+			return nil, fmt.Errorf("字段名 %q 无效或已被占用", parts[0])
+		}
+		if seen[column] {
+			return nil, fmt.Errorf("字段 %s 重复", column)
+		}
+		seen[column] = true
+
+		f := &fieldData{
+			Name:        word.ToGoName(column),
+			Column:      column,
+			Param:       lowerGoName(column),
+			Type:        typ,
+			SwaggerType: swaggerType,
+			Filter:      typ != "bool",
+		}
+		switch {
+		case typ == "string":
+			f.Zero = `""`
+			f.Binding = "required"
+		case typ == "bool":
+			f.Zero = "false"
+		default:
+			f.Zero = "0"
+			if strings.HasSuffix(column, "_id") && strings.HasPrefix(typ, "uint") {
+				f.Binding = "required,gte=1"
+			}
+		}
+		fields = append(fields, f)
+	}
+
+	return fields, nil
+}
+
+// nextErrModule 扫描 errcode 目录下已有的错误码，返回下一个可用的模块编号，如 2004
+func nextErrModule(dir string) (int, error) {
+	files, err := filepath.Glob(filepath.Join(dir, "*.go"))
+	if err != nil {
+		return 0, err
+	}
+
+	module := 2000
+	for _, file := range files {
+		src, err := ioutil.ReadFile(file)
+		if err != nil {
+			return 0, err
+		}
+		for _, m := range errCodeRegexp.FindAllStringSubmatch(string(src), -1) {
+			if n, _ := strconv.Atoi(m[1]); n > module {
+				module = n
+			}
+		}
+	}
+
+	return module + 1, nil
+}
+
+// lowerGoName 转为小写开头的 Go 命名，如 article_id -> articleID，与关键字冲突时加上 Value 后缀
+func lowerGoName(s string) string {
+	words := word.Split(s)
+	if len(words) == 0 {
+		return ""
+	}
+
+	name := strings.ToLower(words[0]) + word.ToGoName(strings.Join(words[1:], "_"))
+	if token.IsKeyword(name) {
+		name += "Value"
+	}
+	return name
+}
+
+// pluralize 简单的英文复数规则，如 tag -> tags，category -> categories
+func pluralize(s string) string {
+	switch {
+	case strings.HasSuffix(s, "s"), strings.HasSuffix(s, "x"), strings.HasSuffix(s, "z"),
+		strings.HasSuffix(s, "ch"), strings.HasSuffix(s, "sh"):
+		return s + "es"
+	case strings.HasSuffix(s, "y") && len(s) > 1 && !strings.ContainsAny(s[len(s)-2:len(s)-1], "aeiou"):
+		return s[:len(s)-1] + "ies"
+	}
+	return s + "s"
+}
+
+func render(tpl string, data *resourceData) ([]byte, error) {
+	t, err := template.New("resourcegen").Parse(tpl)
+	if err != nil {
+		return nil, err
+	}
+
+	var buf bytes.Buffer
+	if err := t.Execute(&buf, data); err != nil {
+		return nil, err
+	}
+	return format.Source(buf.Bytes())
+}
+
+// ModulePath 读取 dir/go.mod 中的 module 名，读取失败时返回空字符串
+func ModulePath(dir string) string {
+	src, err := ioutil.ReadFile(filepath.Join(dir, "go.mod"))
+	if err != nil {
+		return ""
+	}
+
+	for _, line := range strings.Split(string(src), "\n") {
+		fields := strings.Fields(line)
+		if len(fields) == 2 && fields[0] == "module" {
+			return strings.Trim(fields[1], `"`)
+		}
+	}
+	return ""
+}
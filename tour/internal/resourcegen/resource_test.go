@@ -0,0 +1,125 @@
+package resourcegen
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/matryer/is"
+)
+
+const moduleCode = `package errcode
+
+var (
+	ErrorGetTagListFail = NewError(20010001, "获取标签列表失败")
+	ErrorUploadFileFail = NewError(20030001, "上传文件失败")
+)
+`
+
+func TestResourceGenerate(t *testing.T) {
+	is := is.New(t)
+
+	dir := t.TempDir()
+	is.NoErr(os.MkdirAll(filepath.Join(dir, "pkg", "errcode"), 0755))
+	is.NoErr(ioutil.WriteFile(filepath.Join(dir, "pkg", "errcode", "module_code.go"), []byte(moduleCode), 0644))
+	is.NoErr(ioutil.WriteFile(filepath.Join(dir, "go.mod"), []byte("module blog-service\n\ngo 1.15\n"), 0644))
+
+	r := &Resource{
+		Name:        "Comment",
+		Fields:      "content:string, article_id:uint32, visible:bool",
+		Desc:        "评论",
+		TablePrefix: "blog_",
+		Module:      ModulePath(dir),
+		Dir:         dir,
+	}
+	result, err := r.Generate()
+	is.NoErr(err)
+	is.Equal(len(result.Created), 5)
+	is.Equal(len(result.Skipped), 0)
+	is.Equal(result.RouterLines[0], "comment := v1.NewComment()")
+	is.Equal(result.RouterLines[1], `apiv1.POST("/comments", comment.Create)`)
+
+	read := func(elem ...string) string {
+		src, err := ioutil.ReadFile(filepath.Join(append([]string{dir}, elem...)...))
+		is.NoErr(err)
+		return string(src)
+	}
+	for _, c := range []struct {
+		file string
+		want []string
+	}{
+		{"internal/model/comment.go", []string{
+			`ArticleID uint32 ` + "`json:\"article_id\"`",
+			`return "blog_comment"`,
+			"func (c Comment) List(db *gorm.DB, pageOffset, pageSize int) ([]*Comment, error) {",
+		}},
+		{"internal/dao/comment.go", []string{
+			"func (d *Dao) CountComment(content string, articleID uint32, state uint8) (int, error) {",
+			`"visible":     visible,`,
+		}},
+		{"internal/service/comment.go", []string{
+			`ArticleID uint32 ` + "`form:\"article_id\" binding:\"required,gte=1\"`",
+			`"blog-service/internal/model"`,
+		}},
+		{"internal/routers/api/v1/comment.go", []string{
+			"func (co Comment) Get(c *gin.Context) {",
+			"// @Router /api/v1/comments/{id} [put]",
+		}},
+		{"pkg/errcode/comment_code.go", []string{
+			`NewError(20040001, "获取单个评论失败")`,
+		}},
+	} {
+		src := read(filepath.FromSlash(c.file))
+		for _, want := range c.want {
+			if !strings.Contains(src, want) {
+				t.Errorf("%s: missing %q", c.file, want)
+			}
+		}
+	}
+
+	// 已存在的文件不会被覆盖
+	is.NoErr(ioutil.WriteFile(filepath.Join(dir, "internal", "dao", "comment.go"), []byte("package dao\n"), 0644))
+	result, err = r.Generate()
+	is.NoErr(err)
+	is.Equal(len(result.Created), 0)
+	is.Equal(len(result.Skipped), 5)
+	is.Equal(read("internal", "dao", "comment.go"), "package dao\n")
+}
+
+func TestResourceGenerateInvalid(t *testing.T) {
+	for _, r := range []*Resource{
+		{Name: "", Fields: "content:string"},
+		{Name: "Comment", Fields: "content"},
+		{Name: "Comment", Fields: "content:text"},
+		{Name: "Comment", Fields: "state:uint8"},
+		{Name: "Comment", Fields: "content:string,content:string"},
+		{Name: "Comment", Fields: "comment:string"},
+		{Name: "Model", Fields: "content:string"},
+	} {
+		r.Dir = t.TempDir()
+		if _, err := r.Generate(); err == nil {
+			t.Errorf("Generate(%q, %q) expected error", r.Name, r.Fields)
+		}
+	}
+}
+
+func TestPluralize(t *testing.T) {
+	is := is.New(t)
+
+	is.Equal(pluralize("tag"), "tags")
+	is.Equal(pluralize("category"), "categories")
+	is.Equal(pluralize("day"), "days")
+	is.Equal(pluralize("box"), "boxes")
+	is.Equal(pluralize("branch"), "branches")
+	is.Equal(pluralize("article_tag"), "article_tags")
+}
+
+func TestLowerGoName(t *testing.T) {
+	is := is.New(t)
+
+	is.Equal(lowerGoName("article_id"), "articleID")
+	is.Equal(lowerGoName("cover_image_url"), "coverImageURL")
+	is.Equal(lowerGoName("type"), "typeValue")
+}
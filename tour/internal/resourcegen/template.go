@@ -0,0 +1,410 @@
+package resourcegen
+
+const modelTpl = `package model
+
+import (
+	"{{.Module}}/pkg/app"
+
+	"github.com/jinzhu/gorm"
+)
+
+type {{.Name}} struct {
+	*Model
+{{- range .Fields}}
+	{{.Name}} {{.Type}} ` + "`" + `json:"{{.Column}}"` + "`" + `
+{{- end}}
+	State uint8 ` + "`" + `json:"state"` + "`" + `
+}
+
+func ({{.Recv}} {{.Name}}) TableName() string {
+	return "{{.Table}}"
+}
+
+type {{.Name}}Swagger struct {
+	List  []*{{.Name}}
+	Pager *app.Pager
+}
+
+func ({{.Recv}} {{.Name}}) Count(db *gorm.DB) (int, error) {
+	var count int
+{{- range .Fields}}{{if .Filter}}
+	if {{$.Recv}}.{{.Name}} != {{.Zero}} {
+		db = db.Where("{{.Column}} = ?", {{$.Recv}}.{{.Name}})
+	}
+{{- end}}{{end}}
+
+	db = db.Where("state = ?", {{.Recv}}.State)
+	if err := db.Model(&{{.Recv}}).Where("is_del = ?", 0).Count(&count).Error; err != nil {
+		return 0, err
+	}
+
+	return count, nil
+}
+
+func ({{.Recv}} {{.Name}}) Get(db *gorm.DB) ({{.Name}}, error) {
+	var {{.Var}} {{.Name}}
+	err := db.Where("id = ? AND is_del = ? AND state = ?", {{.Recv}}.ID, 0, {{.Recv}}.State).First(&{{.Var}}).Error
+	if err != nil && err != gorm.ErrRecordNotFound {
+		return {{.Var}}, err
+	}
+
+	return {{.Var}}, nil
+}
+
+func ({{.Recv}} {{.Name}}) List(db *gorm.DB, pageOffset, pageSize int) ([]*{{.Name}}, error) {
+	var {{.PluralVar}} []*{{.Name}}
+	var err error
+	if pageOffset >= 0 && pageSize > 0 {
+		db = db.Offset(pageOffset).Limit(pageSize)
+	}
+{{- range .Fields}}{{if .Filter}}
+	if {{$.Recv}}.{{.Name}} != {{.Zero}} {
+		db = db.Where("{{.Column}} = ?", {{$.Recv}}.{{.Name}})
+	}
+{{- end}}{{end}}
+	db = db.Where("state = ?", {{.Recv}}.State)
+	if err = db.Where("is_del = ?", 0).Find(&{{.PluralVar}}).Error; err != nil {
+		return nil, err
+	}
+
+	return {{.PluralVar}}, nil
+}
+
+func ({{.Recv}} {{.Name}}) Create(db *gorm.DB) error {
+	return db.Create(&{{.Recv}}).Error
+}
+
+func ({{.Recv}} {{.Name}}) Update(db *gorm.DB, values interface{}) error {
+	return db.Model(&{{.Recv}}).Where("is_del = ?", 0).Updates(values).Error
+}
+
+func ({{.Recv}} {{.Name}}) Delete(db *gorm.DB) error {
+	return db.Where("is_del = ?", 0).Delete(&{{.Recv}}).Error
+}
+`
+
+const daoTpl = `package dao
+
+import (
+	"{{.Module}}/internal/model"
+	"{{.Module}}/pkg/app"
+)
+
+func (d *Dao) Count{{.Name}}({{range .Fields}}{{if .Filter}}{{.Param}} {{.Type}}, {{end}}{{end}}state uint8) (int, error) {
+	{{.Var}} := model.{{.Name}}{ {{- range .Fields}}{{if .Filter}}{{.Name}}: {{.Param}}, {{end}}{{end}}State: state}
+	return {{.Var}}.Count(d.engine)
+}
+
+func (d *Dao) Get{{.Name}}(id uint32, state uint8) (model.{{.Name}}, error) {
+	{{.Var}} := model.{{.Name}}{Model: &model.Model{ID: id}, State: state}
+	return {{.Var}}.Get(d.engine)
+}
+
+func (d *Dao) Get{{.Name}}List({{range .Fields}}{{if .Filter}}{{.Param}} {{.Type}}, {{end}}{{end}}state uint8, page, pageSize int) ([]*model.{{.Name}}, error) {
+	{{.Var}} := model.{{.Name}}{ {{- range .Fields}}{{if .Filter}}{{.Name}}: {{.Param}}, {{end}}{{end}}State: state}
+	pageOffset := app.GetPageOffset(page, pageSize)
+	return {{.Var}}.List(d.engine, pageOffset, pageSize)
+}
+
+func (d *Dao) Create{{.Name}}({{range .Fields}}{{.Param}} {{.Type}}, {{end}}state uint8, createdBy string) error {
+	{{.Var}} := model.{{.Name}}{
+{{- range .Fields}}
+		{{.Name}}: {{.Param}},
+{{- end}}
+		State: state,
+		Model: &model.Model{CreatedBy: createdBy},
+	}
+	return {{.Var}}.Create(d.engine)
+}
+
+func (d *Dao) Update{{.Name}}(id uint32, {{range .Fields}}{{.Param}} {{.Type}}, {{end}}state uint8, modifiedBy string) error {
+	{{.Var}} := model.{{.Name}}{
+		Model: &model.Model{ID: id},
+	}
+	values := map[string]interface{}{
+{{- range .Fields}}{{if not .Filter}}
+		"{{.Column}}": {{.Param}},
+{{- end}}{{end}}
+		"state":       state,
+		"modified_by": modifiedBy,
+	}
+{{- range .Fields}}{{if .Filter}}
+	if {{.Param}} != {{.Zero}} {
+		values["{{.Column}}"] = {{.Param}}
+	}
+{{- end}}{{end}}
+
+	return {{.Var}}.Update(d.engine, values)
+}
+
+func (d *Dao) Delete{{.Name}}(id uint32) error {
+	{{.Var}} := model.{{.Name}}{Model: &model.Model{ID: id}}
+	return {{.Var}}.Delete(d.engine)
+}
+`
+
+const serviceTpl = `package service
+
+import (
+	"{{.Module}}/internal/model"
+	"{{.Module}}/pkg/app"
+)
+
+type Count{{.Name}}Request struct {
+{{- range .Fields}}{{if .Filter}}
+	{{.Name}} {{.Type}} ` + "`" + `form:"{{.Column}}"` + "`" + `
+{{- end}}{{end}}
+	State uint8 ` + "`" + `form:"state,default=1" binding:"oneof=0 1"` + "`" + `
+}
+
+type {{.Name}}Request struct {
+	ID    uint32 ` + "`" + `form:"id" binding:"required,gte=1"` + "`" + `
+	State uint8  ` + "`" + `form:"state,default=1" binding:"oneof=0 1"` + "`" + `
+}
+
+type {{.Name}}ListRequest struct {
+{{- range .Fields}}{{if .Filter}}
+	{{.Name}} {{.Type}} ` + "`" + `form:"{{.Column}}"` + "`" + `
+{{- end}}{{end}}
+	State uint8 ` + "`" + `form:"state,default=1" binding:"oneof=0 1"` + "`" + `
+}
+
+type Create{{.Name}}Request struct {
+{{- range .Fields}}
+	{{.Name}} {{.Type}} ` + "`" + `form:"{{.Column}}"{{if .Binding}} binding:"{{.Binding}}"{{end}}` + "`" + `
+{{- end}}
+	CreatedBy string ` + "`" + `form:"created_by" binding:"required,min=3,max=100"` + "`" + `
+	State     uint8  ` + "`" + `form:"state,default=1" binding:"oneof=0 1"` + "`" + `
+}
+
+type Update{{.Name}}Request struct {
+	ID uint32 ` + "`" + `form:"id" binding:"required,gte=1"` + "`" + `
+{{- range .Fields}}
+	{{.Name}} {{.Type}} ` + "`" + `form:"{{.Column}}"` + "`" + `
+{{- end}}
+	State      uint8  ` + "`" + `form:"state" binding:"oneof=0 1"` + "`" + `
+	ModifiedBy string ` + "`" + `form:"modified_by" binding:"required,min=3,max=100"` + "`" + `
+}
+
+type Delete{{.Name}}Request struct {
+	ID uint32 ` + "`" + `form:"id" binding:"required,gte=1"` + "`" + `
+}
+
+func (svc *Service) Count{{.Name}}(param *Count{{.Name}}Request) (int, error) {
+	return svc.dao.Count{{.Name}}({{range .Fields}}{{if .Filter}}param.{{.Name}}, {{end}}{{end}}param.State)
+}
+
+func (svc *Service) Get{{.Name}}(param *{{.Name}}Request) (model.{{.Name}}, error) {
+	return svc.dao.Get{{.Name}}(param.ID, param.State)
+}
+
+func (svc *Service) Get{{.Name}}List(param *{{.Name}}ListRequest, pager *app.Pager) ([]*model.{{.Name}}, error) {
+	return svc.dao.Get{{.Name}}List({{range .Fields}}{{if .Filter}}param.{{.Name}}, {{end}}{{end}}param.State, pager.Page, pager.PageSize)
+}
+
+func (svc *Service) Create{{.Name}}(param *Create{{.Name}}Request) error {
+	return svc.dao.Create{{.Name}}({{range .Fields}}param.{{.Name}}, {{end}}param.State, param.CreatedBy)
+}
+
+func (svc *Service) Update{{.Name}}(param *Update{{.Name}}Request) error {
+	return svc.dao.Update{{.Name}}(param.ID, {{range .Fields}}param.{{.Name}}, {{end}}param.State, param.ModifiedBy)
+}
+
+func (svc *Service) Delete{{.Name}}(param *Delete{{.Name}}Request) error {
+	return svc.dao.Delete{{.Name}}(param.ID)
+}
+`
+
+const handlerTpl = `package v1
+
+import (
+	"{{.Module}}/global"
+	"{{.Module}}/internal/service"
+	"{{.Module}}/pkg/app"
+	"{{.Module}}/pkg/convert"
+	"{{.Module}}/pkg/errcode"
+
+	"github.com/gin-gonic/gin"
+)
+
+type {{.Name}} struct{}
+
+func New{{.Name}}() {{.Name}} {
+	return {{.Name}}{}
+}
+
+// @Summary 获取单个{{.Desc}}
+// @Produce json
+// @Param id path int true "{{.Desc}} ID"
+// @Param state query int false "状态" Enums(0, 1) default(1)
+// @Success 200 {object} model.{{.Name}} "成功"
+// @Failure 400 {object} errcode.Error "请求错误"
+// @Failure 500 {object} errcode.Error "内部错误"
+// @Router /api/v1/{{.Path}}/{id} [get]
+func ({{.HandlerRecv}} {{.Name}}) Get(c *gin.Context) {
+	param := service.{{.Name}}Request{ID: convert.StrTo(c.Param("id")).MustUInt32()}
+	response := app.NewResponse(c)
+	valid, errs := app.BindAndValid(c, &param)
+	if !valid {
+		global.Logger.Errorf("app.BindAndValid errs: %v", errs)
+		response.ToErrorResponse(errcode.InvalidParams.WithDetails(errs.Errors()...))
+		return
+	}
+
+	svc := service.New(c.Request.Context())
+	{{.Var}}, err := svc.Get{{.Name}}(&param)
+	if err != nil {
+		global.Logger.Errorf("svc.Get{{.Name}} err: %v", err)
+		response.ToErrorResponse(errcode.ErrorGet{{.Name}}Fail)
+		return
+	}
+
+	response.ToResponse({{.Var}})
+}
+
+// @Summary 获取多个{{.Desc}}
+// @Produce json
+{{- range .Fields}}{{if .Filter}}
+// @Param {{.Column}} query {{.SwaggerType}} false "{{.Column}}"
+{{- end}}{{end}}
+// @Param state query int false "状态" Enums(0, 1) default(1)
+// @Param page query int false "页码"
+// @Param page_size query int false "每页数量"
+// @Success 200 {object} model.{{.Name}}Swagger "成功"
+// @Failure 400 {object} errcode.Error "请求错误"
+// @Failure 500 {object} errcode.Error "内部错误"
+// @Router /api/v1/{{.Path}} [get]
+func ({{.HandlerRecv}} {{.Name}}) List(c *gin.Context) {
+	param := service.{{.Name}}ListRequest{}
+	response := app.NewResponse(c)
+	valid, errs := app.BindAndValid(c, &param)
+	if !valid {
+		global.Logger.Errorf("app.BindAndValid errs: %v", errs)
+		response.ToErrorResponse(errcode.InvalidParams.WithDetails(errs.Errors()...))
+		return
+	}
+
+	svc := service.New(c.Request.Context())
+	pager := app.Pager{Page: app.GetPage(c), PageSize: app.GetPageSize(c)}
+	totalRows, err := svc.Count{{.Name}}(&service.Count{{.Name}}Request{ {{- range .Fields}}{{if .Filter}}{{.Name}}: param.{{.Name}}, {{end}}{{end}}State: param.State})
+	if err != nil {
+		global.Logger.Errorf("svc.Count{{.Name}} err: %v", err)
+		response.ToErrorResponse(errcode.ErrorCount{{.Name}}Fail)
+		return
+	}
+
+	{{.PluralVar}}, err := svc.Get{{.Name}}List(&param, &pager)
+	if err != nil {
+		global.Logger.Errorf("svc.Get{{.Name}}List err: %v", err)
+		response.ToErrorResponse(errcode.ErrorGet{{.Name}}ListFail)
+		return
+	}
+
+	response.ToResponseList({{.PluralVar}}, totalRows)
+}
+
+// @Summary 新增{{.Desc}}
+// @Produce json
+{{- range .Fields}}
+// @Param {{.Column}} body {{.SwaggerType}} {{if .Binding}}true{{else}}false{{end}} "{{.Column}}"
+{{- end}}
+// @Param state body int false "状态" Enums(0, 1) default(1)
+// @Param created_by body string true "创建者" minlength(3) maxlength(100)
+// @Success 200 {object} model.{{.Name}} "成功"
+// @Failure 400 {object} errcode.Error "请求错误"
+// @Failure 500 {object} errcode.Error "内部错误"
+// @Router /api/v1/{{.Path}} [post]
+func ({{.HandlerRecv}} {{.Name}}) Create(c *gin.Context) {
+	param := service.Create{{.Name}}Request{}
+	response := app.NewResponse(c)
+	valid, errs := app.BindAndValid(c, &param)
+	if !valid {
+		global.Logger.Errorf("app.BindAndValid errs: %v", errs)
+		response.ToErrorResponse(errcode.InvalidParams.WithDetails(errs.Errors()...))
+		return
+	}
+
+	svc := service.New(c.Request.Context())
+	err := svc.Create{{.Name}}(&param)
+	if err != nil {
+		global.Logger.Errorf("svc.Create{{.Name}} err: %v", err)
+		response.ToErrorResponse(errcode.ErrorCreate{{.Name}}Fail)
+		return
+	}
+
+	response.ToResponse(gin.H{})
+}
+
+// @Summary 更新{{.Desc}}
+// @Produce json
+// @Param id path int true "{{.Desc}} ID"
+{{- range .Fields}}
+// @Param {{.Column}} body {{.SwaggerType}} false "{{.Column}}"
+{{- end}}
+// @Param state body int false "状态" Enums(0, 1) default(1)
+// @Param modified_by body string true "修改者" minlength(3) maxlength(100)
+// @Success 200 {object} model.{{.Name}} "成功"
+// @Failure 400 {object} errcode.Error "请求错误"
+// @Failure 500 {object} errcode.Error "内部错误"
+// @Router /api/v1/{{.Path}}/{id} [put]
+func ({{.HandlerRecv}} {{.Name}}) Update(c *gin.Context) {
+	param := service.Update{{.Name}}Request{ID: convert.StrTo(c.Param("id")).MustUInt32()}
+	response := app.NewResponse(c)
+	valid, errs := app.BindAndValid(c, &param)
+	if !valid {
+		global.Logger.Errorf("app.BindAndValid errs: %v", errs)
+		response.ToErrorResponse(errcode.InvalidParams.WithDetails(errs.Errors()...))
+		return
+	}
+
+	svc := service.New(c.Request.Context())
+	err := svc.Update{{.Name}}(&param)
+	if err != nil {
+		global.Logger.Errorf("svc.Update{{.Name}} err: %v", err)
+		response.ToErrorResponse(errcode.ErrorUpdate{{.Name}}Fail)
+		return
+	}
+
+	response.ToResponse(gin.H{})
+}
+
+// @Summary 删除{{.Desc}}
+// @Produce json
+// @Param id path int true "{{.Desc}} ID"
+// @Success 200 {string} string "成功"
+// @Failure 400 {object} errcode.Error "请求错误"
+// @Failure 500 {object} errcode.Error "内部错误"
+// @Router /api/v1/{{.Path}}/{id} [delete]
+func ({{.HandlerRecv}} {{.Name}}) Delete(c *gin.Context) {
+	param := service.Delete{{.Name}}Request{ID: convert.StrTo(c.Param("id")).MustUInt32()}
+	response := app.NewResponse(c)
+	valid, errs := app.BindAndValid(c, &param)
+	if !valid {
+		global.Logger.Errorf("app.BindAndValid errs: %v", errs)
+		response.ToErrorResponse(errcode.InvalidParams.WithDetails(errs.Errors()...))
+		return
+	}
+
+	svc := service.New(c.Request.Context())
+	err := svc.Delete{{.Name}}(&param)
+	if err != nil {
+		global.Logger.Errorf("svc.Delete{{.Name}} err: %v", err)
+		response.ToErrorResponse(errcode.ErrorDelete{{.Name}}Fail)
+		return
+	}
+
+	response.ToResponse(gin.H{})
+}
+`
+
+const errcodeTpl = `package errcode
+
+var (
+	ErrorGet{{.Name}}Fail     = NewError({{.ErrModule}}0001, "获取单个{{.Desc}}失败")
+	ErrorGet{{.Name}}ListFail = NewError({{.ErrModule}}0002, "获取{{.Desc}}列表失败")
+	ErrorCreate{{.Name}}Fail  = NewError({{.ErrModule}}0003, "创建{{.Desc}}失败")
+	ErrorUpdate{{.Name}}Fail  = NewError({{.ErrModule}}0004, "更新{{.Desc}}失败")
+	ErrorDelete{{.Name}}Fail  = NewError({{.ErrModule}}0005, "删除{{.Desc}}失败")
+	ErrorCount{{.Name}}Fail   = NewError({{.ErrModule}}0006, "统计{{.Desc}}失败")
+)
+`
@@ -0,0 +1,115 @@
+package shell
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+)
+
+// Completer 根据 cobra 的命令树补全子命令、flag 以及 flag 的候选值，实现了 readline.AutoCompleter
+type Completer struct {
+	Root *cobra.Command
+	// 交互模式内置的命令，如 exit
+	Builtins []string
+}
+
+// Do 返回候选项中待补全的部分以及已输入部分的长度
+func (c *Completer) Do(line []rune, pos int) ([][]rune, int) {
+	prefix, candidates := c.Complete(string(line[:pos]))
+	var suffixes [][]rune
+	for _, candidate := range candidates {
+		suffixes = append(suffixes, []rune(strings.TrimPrefix(candidate, prefix)+" "))
+	}
+	return suffixes, len([]rune(prefix))
+}
+
+// Complete 返回 line 最后一个未输入完的词及其候选项
+func (c *Completer) Complete(line string) (string, []string) {
+	words, err := Split(line)
+	if err != nil {
+		words = strings.Fields(line)
+	}
+
+	prefix := ""
+	if len(words) > 0 && !strings.HasSuffix(line, " ") {
+		prefix = words[len(words)-1]
+		words = words[:len(words)-1]
+	}
+
+	cmd := c.Root
+	positional := false
+	var pending *pflag.Flag
+	for _, w := range words {
+		if pending != nil {
+			pending = nil
+			continue
+		}
+		if strings.HasPrefix(w, "-") {
+			f, _, ok := lookupFlag(cmd, w)
+			if f != nil && !ok && takesValue(f) {
+				pending = f
+			}
+			continue
+		}
+		if positional {
+			continue
+		}
+		if sub := findSubCommand(cmd, w); sub != nil {
+			cmd = sub
+		} else {
+			positional = true
+		}
+	}
+
+	var candidates []string
+	switch {
+	case pending != nil:
+		candidates = pending.Annotations[ValuesAnnotation]
+	case strings.HasPrefix(prefix, "-"):
+		if f, _, ok := lookupFlag(cmd, prefix); ok && f != nil && strings.HasPrefix(prefix, "--") {
+			name := prefix[:strings.Index(prefix, "=")+1]
+			for _, v := range f.Annotations[ValuesAnnotation] {
+				candidates = append(candidates, name+v)
+			}
+			break
+		}
+		visitFlags(cmd, func(f *pflag.Flag) {
+			if f.Hidden {
+				return
+			}
+			candidates = append(candidates, "--"+f.Name)
+			if f.Shorthand != "" && !strings.HasPrefix(prefix, "--") {
+				candidates = append(candidates, "-"+f.Shorthand)
+			}
+		})
+	case !positional:
+		for _, sub := range cmd.Commands() {
+			if sub.IsAvailableCommand() {
+				candidates = append(candidates, sub.Name())
+			}
+		}
+		if cmd == c.Root {
+			candidates = append(candidates, c.Builtins...)
+		}
+	}
+
+	var matched []string
+	for _, candidate := range candidates {
+		if strings.HasPrefix(candidate, prefix) {
+			matched = append(matched, candidate)
+		}
+	}
+	sort.Strings(matched)
+	return prefix, matched
+}
+
+func findSubCommand(cmd *cobra.Command, name string) *cobra.Command {
+	for _, sub := range cmd.Commands() {
+		if sub.Name() == name || sub.HasAlias(name) {
+			return sub
+		}
+	}
+	return nil
+}
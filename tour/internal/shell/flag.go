@@ -0,0 +1,78 @@
+package shell
+
+import (
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+)
+
+const (
+	// StickyAnnotation 标记需要在交互模式中记住上次取值的 flag，如 word 的 --mode
+	StickyAnnotation = "tour_shell_sticky"
+	// ValuesAnnotation 记录 flag 的可选值，用于补全
+	ValuesAnnotation = "tour_shell_values"
+)
+
+// MarkSticky 将 flag 标记为 sticky，values 为补全时的候选值，可以为空
+func MarkSticky(flags *pflag.FlagSet, name string, values ...string) error {
+	if err := flags.SetAnnotation(name, StickyAnnotation, []string{"true"}); err != nil {
+		return err
+	}
+	return SetValues(flags, name, values...)
+}
+
+// SetValues 设置 flag 补全时的候选值
+func SetValues(flags *pflag.FlagSet, name string, values ...string) error {
+	if len(values) == 0 {
+		return nil
+	}
+	return flags.SetAnnotation(name, ValuesAnnotation, values)
+}
+
+func isSticky(f *pflag.Flag) bool {
+	_, ok := f.Annotations[StickyAnnotation]
+	return ok
+}
+
+// takesValue 判断 flag 是否需要单独的值，bool 等设置了 NoOptDefVal 的 flag 不需要
+func takesValue(f *pflag.Flag) bool {
+	return f.NoOptDefVal == ""
+}
+
+// lookupFlag 在命令自身及继承的 flag 中查找，arg 形如 --name、--name=value、-n、-nvalue，
+// 返回 flag、arg 中携带的值以及是否携带了值
+func lookupFlag(cmd *cobra.Command, arg string) (*pflag.Flag, string, bool) {
+	var f *pflag.Flag
+	switch {
+	case strings.HasPrefix(arg, "--"):
+		parts := strings.SplitN(arg[2:], "=", 2)
+		f = cmd.Flags().Lookup(parts[0])
+		if f == nil {
+			f = cmd.InheritedFlags().Lookup(parts[0])
+		}
+		if len(parts) == 2 {
+			return f, parts[1], true
+		}
+	case strings.HasPrefix(arg, "-") && len(arg) > 1:
+		short := arg[1:2]
+		f = cmd.Flags().ShorthandLookup(short)
+		if f == nil {
+			f = cmd.InheritedFlags().ShorthandLookup(short)
+		}
+		if v := strings.TrimPrefix(arg[2:], "="); len(arg) > 2 {
+			return f, v, true
+		}
+	}
+	return f, "", false
+}
+
+// visitFlags 遍历命令自身及继承的 flag
+func visitFlags(cmd *cobra.Command, fn func(*pflag.Flag)) {
+	cmd.Flags().VisitAll(fn)
+	cmd.InheritedFlags().VisitAll(func(f *pflag.Flag) {
+		if cmd.Flags().Lookup(f.Name) == nil {
+			fn(f)
+		}
+	})
+}
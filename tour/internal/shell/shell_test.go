@@ -0,0 +1,96 @@
+package shell
+
+import (
+	"testing"
+
+	"github.com/matryer/is"
+	"github.com/spf13/cobra"
+)
+
+func newTestRoot() *cobra.Command {
+	noop := func(cmd *cobra.Command, args []string) {}
+	root := &cobra.Command{Use: "tour"}
+	word := &cobra.Command{Use: "word", Run: noop}
+	word.Flags().StringP("str", "s", "", "")
+	word.Flags().StringP("mode", "m", "", "")
+	word.Flags().Bool("in-place", false, "")
+	MarkSticky(word.Flags(), "mode", "camel", "snake", "upper")
+
+	timeCmd := &cobra.Command{Use: "time", Run: noop}
+	timeCmd.PersistentFlags().StringP("zone", "z", "", "")
+	MarkSticky(timeCmd.PersistentFlags(), "zone")
+	now := &cobra.Command{Use: "now", Run: noop}
+	calc := &cobra.Command{Use: "calc", Run: noop}
+	timeCmd.AddCommand(now, calc)
+
+	root.AddCommand(word, timeCmd)
+	return root
+}
+
+func TestSplit(t *testing.T) {
+	is := is.New(t)
+
+	args, err := Split(`word -s "hello world" -m 'camel' a\ b ""`)
+	is.NoErr(err)
+	is.Equal(args, []string{"word", "-s", "hello world", "-m", "camel", "a b", ""})
+
+	_, err = Split(`word -s "hello`)
+	is.True(err != nil)
+}
+
+func TestComplete(t *testing.T) {
+	is := is.New(t)
+
+	c := &Completer{Root: newTestRoot(), Builtins: []string{"exit"}}
+	for _, tc := range []struct {
+		line       string
+		prefix     string
+		candidates []string
+	}{
+		{"", "", []string{"exit", "time", "word"}},
+		{"ti", "ti", []string{"time"}},
+		{"time ", "", []string{"calc", "now"}},
+		{"time now --", "--", []string{"--zone"}},
+		{"word -", "-", []string{"--in-place", "--mode", "--str", "-m", "-s"}},
+		{"word --m", "--m", []string{"--mode"}},
+		{"word -m ", "", []string{"camel", "snake", "upper"}},
+		{"word --mode=s", "--mode=s", []string{"--mode=snake"}},
+		{"word -s hello ", "", nil},
+		{"word hello ", "", nil},
+	} {
+		prefix, candidates := c.Complete(tc.line)
+		is.Equal(prefix, tc.prefix)
+		is.Equal(candidates, tc.candidates)
+	}
+
+	suffixes, n := c.Do([]rune("time n"), 6)
+	is.Equal(n, 1)
+	is.Equal(string(suffixes[0]), "ow ")
+}
+
+func TestSticky(t *testing.T) {
+	is := is.New(t)
+
+	root := newTestRoot()
+	s := NewSticky()
+
+	args, added := s.Apply(root, []string{"word", "-s", "a_b"})
+	is.Equal(args, []string{"word", "-s", "a_b"})
+	is.Equal(len(added), 0)
+
+	s.Apply(root, []string{"word", "-s", "a_b", "-m", "camel"})
+	args, added = s.Apply(root, []string{"word", "-s", "c_d"})
+	is.Equal(args, []string{"word", "-s", "c_d", "--mode=camel"})
+	is.Equal(added, []string{"--mode=camel"})
+
+	s.Apply(root, []string{"word", "--mode=snake", "-s", "x"})
+	args, _ = s.Apply(root, []string{"word", "-s", "c_d", "--", "-x"})
+	is.Equal(args, []string{"word", "-s", "c_d", "--mode=snake", "--", "-x"})
+
+	// 继承的 flag 按命令分别记录
+	s.Apply(root, []string{"time", "now", "-zUTC"})
+	args, _ = s.Apply(root, []string{"time", "now"})
+	is.Equal(args, []string{"time", "now", "--zone=UTC"})
+	args, _ = s.Apply(root, []string{"time", "calc"})
+	is.Equal(args, []string{"time", "calc"})
+}
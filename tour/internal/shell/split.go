@@ -0,0 +1,54 @@
+package shell
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Split 按照 shell 的规则将一行输入拆分为参数，支持单引号、双引号和反斜杠转义
+func Split(line string) ([]string, error) {
+	var args []string
+	var b strings.Builder
+	inArg := false
+	var quote rune
+	escaped := false
+	for _, r := range line {
+		switch {
+		case escaped:
+			b.WriteRune(r)
+			escaped = false
+		case r == '\\' && quote != '\'':
+			escaped = true
+			inArg = true
+		case quote != 0:
+			if r == quote {
+				quote = 0
+			} else {
+				b.WriteRune(r)
+			}
+		case r == '\'' || r == '"':
+			quote = r
+			inArg = true
+		case r == ' ' || r == '\t' || r == '\n' || r == '\r':
+			if inArg {
+				args = append(args, b.String())
+				b.Reset()
+				inArg = false
+			}
+		default:
+			b.WriteRune(r)
+			inArg = true
+		}
+	}
+
+	if escaped {
+		return nil, fmt.Errorf("行尾存在未完成的转义")
+	}
+	if quote != 0 {
+		return nil, fmt.Errorf("未闭合的引号: %c", quote)
+	}
+	if inArg {
+		args = append(args, b.String())
+	}
+	return args, nil
+}
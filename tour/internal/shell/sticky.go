@@ -0,0 +1,78 @@
+package shell
+
+import (
+	"sort"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+)
+
+// Sticky 按命令记录 sticky flag 上次显式指定的值，再次执行该命令且未指定时自动补上
+type Sticky struct {
+	last map[string]map[string]string
+}
+
+func NewSticky() *Sticky {
+	return &Sticky{last: make(map[string]map[string]string)}
+}
+
+// Apply 记录 args 中显式指定的 sticky flag，返回补上了其余 sticky flag 上次取值的参数，
+// 以及补上的参数
+func (s *Sticky) Apply(root *cobra.Command, args []string) ([]string, []string) {
+	cmd, _, err := root.Find(args)
+	if err != nil {
+		return args, nil
+	}
+
+	key := cmd.CommandPath()
+	given := make(map[string]string)
+	for i := 0; i < len(args); i++ {
+		if args[i] == "--" {
+			break
+		}
+		f, value, ok := lookupFlag(cmd, args[i])
+		if f == nil {
+			continue
+		}
+		if !ok && takesValue(f) && i+1 < len(args) {
+			i++
+			value = args[i]
+		}
+		if isSticky(f) {
+			given[f.Name] = value
+		}
+	}
+
+	if s.last[key] == nil {
+		s.last[key] = make(map[string]string)
+	}
+	for name, value := range given {
+		s.last[key][name] = value
+	}
+
+	var added []string
+	visitFlags(cmd, func(f *pflag.Flag) {
+		if !isSticky(f) {
+			return
+		}
+		if _, ok := given[f.Name]; ok {
+			return
+		}
+		if value, ok := s.last[key][f.Name]; ok {
+			added = append(added, "--"+f.Name+"="+value)
+		}
+	})
+	sort.Strings(added)
+
+	result := make([]string, 0, len(args)+len(added))
+	for i, arg := range args {
+		// 补上的 flag 要放在 -- 之前，避免被当作位置参数
+		if arg == "--" {
+			result = append(result, added...)
+			result = append(result, args[i:]...)
+			return result, added
+		}
+		result = append(result, arg)
+	}
+	return append(result, added...), added
+}
@@ -0,0 +1,320 @@
+package sql2struct
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Table 从 CREATE TABLE 语句中解析出的表结构
+type Table struct {
+	Name    string
+	Comment string
+	Columns []*TableColumn
+}
+
+// TableColumn 表字段
+type TableColumn struct {
+	ColumnName    string
+	DataType      string // 小写的类型名，如 int、varchar
+	ColumnType    string // 完整的类型定义，如 int(10) unsigned
+	Unsigned      bool
+	IsNullable    bool
+	IsPrimaryKey  bool
+	AutoIncrement bool
+	ColumnComment string
+}
+
+// 表定义中以这些关键字开头的是索引或约束，而不是字段
+var constraintKeywords = []string{
+	"PRIMARY", "KEY", "INDEX", "UNIQUE", "CONSTRAINT",
+	"FOREIGN", "FULLTEXT", "SPATIAL", "CHECK",
+}
+
+type parser struct {
+	tokens []token
+	pos    int
+}
+
+// ParseDDL 解析 MySQL/SQLite 的 CREATE TABLE 语句，其它语句会被忽略
+func ParseDDL(ddl string) ([]*Table, error) {
+	tokens, err := tokenize(ddl)
+	if err != nil {
+		return nil, err
+	}
+
+	p := &parser{tokens: tokens}
+	var tables []*Table
+	for !p.eof() {
+		if p.peek().is("CREATE") {
+			p.next()
+			if p.peek().is("TEMPORARY") {
+				p.next()
+			}
+			if p.peek().is("TABLE") {
+				p.next()
+				table, err := p.parseTable()
+				if err != nil {
+					return nil, err
+				}
+				if table != nil {
+					tables = append(tables, table)
+				}
+				continue
+			}
+		}
+		p.skipStatement()
+	}
+
+	return tables, nil
+}
+
+func (p *parser) eof() bool {
+	return p.pos >= len(p.tokens)
+}
+
+func (p *parser) peek() token {
+	if p.eof() {
+		return token{}
+	}
+	return p.tokens[p.pos]
+}
+
+func (p *parser) next() token {
+	t := p.peek()
+	p.pos++
+	return t
+}
+
+// skipStatement 跳过当前语句，直到分号或结尾
+func (p *parser) skipStatement() {
+	for !p.eof() {
+		if p.next().isSymbol(";") {
+			return
+		}
+	}
+}
+
+func (p *parser) parseTable() (*Table, error) {
+	if p.peek().is("IF") {
+		p.next() // IF
+		p.next() // NOT
+		p.next() // EXISTS
+	}
+
+	name, err := p.parseName()
+	if err != nil {
+		return nil, err
+	}
+
+	// CREATE TABLE ... LIKE/AS SELECT 无法得到字段定义
+	if !p.peek().isSymbol("(") {
+		p.skipStatement()
+		return nil, nil
+	}
+	p.next()
+
+	table := &Table{Name: name}
+	var primaryKeys []string
+	for _, def := range p.splitDefinitions() {
+		if len(def) == 0 {
+			continue
+		}
+		if isConstraint(def[0]) {
+			primaryKeys = append(primaryKeys, parsePrimaryKey(def)...)
+			continue
+		}
+
+		column, err := parseColumn(def)
+		if err != nil {
+			return nil, fmt.Errorf("表 %s: %v", name, err)
+		}
+		table.Columns = append(table.Columns, column)
+	}
+
+	for _, key := range primaryKeys {
+		for _, column := range table.Columns {
+			if strings.EqualFold(column.ColumnName, key) {
+				column.IsPrimaryKey = true
+				column.IsNullable = false
+			}
+		}
+	}
+
+	// 表选项，如 ENGINE=InnoDB COMMENT='标签管理'
+	for !p.eof() {
+		t := p.next()
+		if t.isSymbol(";") {
+			break
+		}
+		if t.is("COMMENT") {
+			if p.peek().isSymbol("=") {
+				p.next()
+			}
+			if p.peek().kind == tokenString {
+				table.Comment = p.next().value
+			}
+		}
+	}
+
+	return table, nil
+}
+
+// parseName 解析表名，形如 `db`.`table` 时只保留表名
+func (p *parser) parseName() (string, error) {
+	var name string
+	for {
+		t := p.next()
+		if t.kind != tokenWord && t.kind != tokenIdent {
+			return "", fmt.Errorf("无效的表名: %q", t.value)
+		}
+		name = t.value
+		if !p.peek().isSymbol(".") {
+			return name, nil
+		}
+		p.next()
+	}
+}
+
+// splitDefinitions 按顶层逗号拆分括号内的字段和索引定义，并消费结尾的右括号
+func (p *parser) splitDefinitions() [][]token {
+	var defs [][]token
+	var cur []token
+	depth := 0
+	for !p.eof() {
+		t := p.next()
+		switch {
+		case t.isSymbol("("):
+			depth++
+		case t.isSymbol(")"):
+			if depth == 0 {
+				return append(defs, cur)
+			}
+			depth--
+		case t.isSymbol(",") && depth == 0:
+			defs = append(defs, cur)
+			cur = nil
+			continue
+		}
+		cur = append(cur, t)
+	}
+
+	return append(defs, cur)
+}
+
+func isConstraint(t token) bool {
+	for _, keyword := range constraintKeywords {
+		if t.is(keyword) {
+			return true
+		}
+	}
+	return false
+}
+
+// parsePrimaryKey 从 PRIMARY KEY (`id`) 这样的约束定义中取出字段名
+func parsePrimaryKey(def []token) []string {
+	var keys []string
+	for i := 0; i+1 < len(def); i++ {
+		if !def[i].is("PRIMARY") || !def[i+1].is("KEY") {
+			continue
+		}
+
+		depth := 0
+		for _, t := range def[i+2:] {
+			switch {
+			case t.isSymbol("("):
+				depth++
+			case t.isSymbol(")"):
+				depth--
+			case depth == 1 && (t.kind == tokenIdent || (t.kind == tokenWord && !t.is("ASC") && !t.is("DESC"))):
+				keys = append(keys, t.value)
+			}
+		}
+		break
+	}
+
+	return keys
+}
+
+func parseColumn(def []token) (*TableColumn, error) {
+	if def[0].kind != tokenWord && def[0].kind != tokenIdent {
+		return nil, fmt.Errorf("无效的字段名: %q", def[0].value)
+	}
+	column := &TableColumn{ColumnName: def[0].value, IsNullable: true}
+
+	i := 1
+	var typeParts []string
+	if i < len(def) && def[i].kind == tokenWord {
+		column.DataType = strings.ToLower(def[i].value)
+		i++
+		// double precision、character varying 等多个单词组成的类型
+		if i < len(def) && (def[i].is("PRECISION") || def[i].is("VARYING")) {
+			column.DataType += " " + strings.ToLower(def[i].value)
+			i++
+		}
+		typeParts = append(typeParts, column.DataType)
+	}
+
+	if i < len(def) && def[i].isSymbol("(") && len(typeParts) > 0 {
+		var args []string
+		for i++; i < len(def) && !def[i].isSymbol(")"); i++ {
+			args = append(args, def[i].value)
+		}
+		i++
+		typeParts[len(typeParts)-1] += "(" + strings.Join(args, "") + ")"
+	}
+
+	for ; i < len(def); i++ {
+		t := def[i]
+		switch {
+		case t.is("UNSIGNED"):
+			column.Unsigned = true
+			typeParts = append(typeParts, "unsigned")
+		case t.is("NOT"):
+			if i+1 < len(def) && def[i+1].is("NULL") {
+				column.IsNullable = false
+				i++
+			}
+		case t.is("NULL"):
+			column.IsNullable = true
+		case t.is("PRIMARY"):
+			column.IsPrimaryKey = true
+			column.IsNullable = false
+		case t.is("AUTO_INCREMENT") || t.is("AUTOINCREMENT"):
+			column.AutoIncrement = true
+		case t.is("DEFAULT"):
+			i = skipExpr(def, i+1)
+		case t.is("COMMENT"):
+			if i+1 < len(def) && def[i+1].kind == tokenString {
+				column.ColumnComment = def[i+1].value
+				i++
+			}
+		}
+	}
+	column.ColumnType = strings.Join(typeParts, " ")
+
+	return column, nil
+}
+
+// skipExpr 跳过从 i 开始的一个表达式（单个 token 或括号内容），返回最后一个被跳过的下标
+func skipExpr(def []token, i int) int {
+	if i >= len(def) {
+		return i
+	}
+	if def[i].isSymbol("-") || def[i].isSymbol("+") {
+		i++
+	}
+	if i < len(def) && (def[i].isSymbol("(") || (i+1 < len(def) && def[i+1].isSymbol("("))) {
+		depth := 0
+		for ; i < len(def); i++ {
+			if def[i].isSymbol("(") {
+				depth++
+			} else if def[i].isSymbol(")") {
+				depth--
+				if depth == 0 {
+					return i
+				}
+			}
+		}
+	}
+	return i
+}
@@ -0,0 +1,91 @@
+package sql2struct
+
+import (
+	"testing"
+
+	"github.com/matryer/is"
+)
+
+const blogTagDDL = "CREATE DATABASE IF NOT EXISTS blog_service;\n" +
+	"CREATE TABLE `blog_service`.`blog_tag` (\n" +
+	"  `id` int(10) unsigned NOT NULL AUTO_INCREMENT,\n" +
+	"  `name` varchar(100) DEFAULT '' COMMENT '标签名称',\n" +
+	"  `content` longtext COMMENT '内容',\n" +
+	"  `state` tinyint(3) unsigned DEFAULT '1' COMMENT '状态 0为禁用、1为启用',\n" +
+	"  `price` decimal(10,2) NOT NULL DEFAULT '0.00',\n" +
+	"  `updated_at` timestamp NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,\n" +
+	"  PRIMARY KEY (`id`) USING BTREE,\n" +
+	"  KEY `idx_name` (`name`(20))\n" +
+	") ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COMMENT='标签管理';\n" +
+	"INSERT INTO `blog_tag` VALUES (1, 'go', '', 1, 0, NULL);"
+
+func TestParseDDL(t *testing.T) {
+	is := is.New(t)
+
+	tables, err := ParseDDL(blogTagDDL)
+	is.NoErr(err)
+	is.Equal(len(tables), 1)
+
+	table := tables[0]
+	is.Equal(table.Name, "blog_tag")
+	is.Equal(table.Comment, "标签管理")
+	is.Equal(len(table.Columns), 6)
+
+	id := table.Columns[0]
+	is.Equal(id.ColumnName, "id")
+	is.Equal(id.DataType, "int")
+	is.Equal(id.ColumnType, "int(10) unsigned")
+	is.True(id.Unsigned)
+	is.True(id.IsPrimaryKey)
+	is.True(id.AutoIncrement)
+	is.True(!id.IsNullable)
+
+	is.Equal(table.Columns[1].ColumnComment, "标签名称")
+	is.True(table.Columns[1].IsNullable)
+	is.Equal(table.Columns[4].ColumnType, "decimal(10,2)")
+	is.Equal(table.Columns[5].DataType, "timestamp")
+}
+
+func TestParseDDLSQLite(t *testing.T) {
+	is := is.New(t)
+
+	tables, err := ParseDDL(`
+		/* users */
+		CREATE TABLE IF NOT EXISTS "users" (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			[name] TEXT NOT NULL DEFAULT (''), -- 用户名
+			score REAL
+		);`)
+	is.NoErr(err)
+	is.Equal(len(tables), 1)
+	is.Equal(tables[0].Name, "users")
+	is.Equal(len(tables[0].Columns), 3)
+	is.True(tables[0].Columns[0].IsPrimaryKey)
+	is.Equal(tables[0].Columns[1].ColumnName, "name")
+	is.True(!tables[0].Columns[1].IsNullable)
+}
+
+func TestStructType(t *testing.T) {
+	is := is.New(t)
+
+	cases := []struct {
+		dbType string
+		column TableColumn
+		want   string
+	}{
+		{TypeMySQL, TableColumn{DataType: "int", Unsigned: true}, "uint32"},
+		{TypeMySQL, TableColumn{DataType: "int"}, "int32"},
+		{TypeMySQL, TableColumn{DataType: "tinyint", Unsigned: true}, "uint8"},
+		{TypeMySQL, TableColumn{DataType: "bigint", Unsigned: true}, "uint64"},
+		{TypeMySQL, TableColumn{DataType: "longtext"}, "string"},
+		{TypeMySQL, TableColumn{DataType: "datetime"}, "time.Time"},
+		{TypeMySQL, TableColumn{DataType: "float", Unsigned: true}, "float32"},
+		{TypeSQLite, TableColumn{DataType: "integer"}, "int64"},
+		{TypeSQLite, TableColumn{DataType: "varchar"}, "string"},
+		{TypeSQLite, TableColumn{DataType: "numeric"}, "float64"},
+	}
+	for _, c := range cases {
+		column := c.column
+		is.Equal(StructType(c.dbType, &column), c.want)
+	}
+}
@@ -0,0 +1,133 @@
+package sql2struct
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+)
+
+type tokenKind int
+
+const (
+	tokenWord   tokenKind = iota + 1 // 关键字或未加引号的标识符
+	tokenIdent                       // 加了引号的标识符，如 `id`、"id"、[id]
+	tokenString                      // 字符串字面量，如 'abc'
+	tokenNumber                      // 数字
+	tokenSymbol                      // 符号，如 ( ) , ; . =
+)
+
+type token struct {
+	kind  tokenKind
+	value string
+}
+
+// is 判断 token 是否为指定的关键字（忽略大小写）
+func (t token) is(word string) bool {
+	return t.kind == tokenWord && strings.EqualFold(t.value, word)
+}
+
+func (t token) isSymbol(s string) bool {
+	return t.kind == tokenSymbol && t.value == s
+}
+
+// tokenize 将 DDL 语句拆分为 token，会跳过注释和空白
+func tokenize(src string) ([]token, error) {
+	var tokens []token
+	rs := []rune(src)
+	for i := 0; i < len(rs); {
+		r := rs[i]
+		switch {
+		case unicode.IsSpace(r):
+			i++
+		case r == '#' || (r == '-' && i+1 < len(rs) && rs[i+1] == '-'):
+			for i < len(rs) && rs[i] != '\n' {
+				i++
+			}
+		case r == '/' && i+1 < len(rs) && rs[i+1] == '*':
+			j := i + 2
+			for j+1 < len(rs) && !(rs[j] == '*' && rs[j+1] == '/') {
+				j++
+			}
+			if j+1 >= len(rs) {
+				return nil, fmt.Errorf("未闭合的注释")
+			}
+			i = j + 2
+		case r == '`' || r == '"' || r == '[':
+			closer := r
+			if r == '[' {
+				closer = ']'
+			}
+			v, n, err := readQuoted(rs[i:], closer)
+			if err != nil {
+				return nil, err
+			}
+			tokens = append(tokens, token{tokenIdent, v})
+			i += n
+		case r == '\'':
+			v, n, err := readQuoted(rs[i:], '\'')
+			if err != nil {
+				return nil, err
+			}
+			tokens = append(tokens, token{tokenString, v})
+			i += n
+		case unicode.IsDigit(r):
+			j := i
+			for j < len(rs) && (unicode.IsDigit(rs[j]) || rs[j] == '.') {
+				j++
+			}
+			tokens = append(tokens, token{tokenNumber, string(rs[i:j])})
+			i = j
+		case unicode.IsLetter(r) || r == '_':
+			j := i
+			for j < len(rs) && (unicode.IsLetter(rs[j]) || unicode.IsDigit(rs[j]) || rs[j] == '_' || rs[j] == '$') {
+				j++
+			}
+			tokens = append(tokens, token{tokenWord, string(rs[i:j])})
+			i = j
+		default:
+			tokens = append(tokens, token{tokenSymbol, string(r)})
+			i++
+		}
+	}
+
+	return tokens, nil
+}
+
+// readQuoted 读取以 rs[0] 开头、closer 结尾的内容，返回去掉引号后的值和消耗的 rune 数。
+// 连续两个 closer 视为转义；单引号字符串还支持反斜杠转义。
+func readQuoted(rs []rune, closer rune) (string, int, error) {
+	var b strings.Builder
+	for i := 1; i < len(rs); i++ {
+		r := rs[i]
+		if closer == '\'' && r == '\\' && i+1 < len(rs) {
+			i++
+			b.WriteRune(unescape(rs[i]))
+			continue
+		}
+		if r == closer {
+			if i+1 < len(rs) && rs[i+1] == closer {
+				b.WriteRune(r)
+				i++
+				continue
+			}
+			return b.String(), i + 1, nil
+		}
+		b.WriteRune(r)
+	}
+
+	return "", 0, fmt.Errorf("未闭合的引号: %c", rs[0])
+}
+
+func unescape(r rune) rune {
+	switch r {
+	case 'n':
+		return '\n'
+	case 't':
+		return '\t'
+	case 'r':
+		return '\r'
+	case '0':
+		return 0
+	}
+	return r
+}
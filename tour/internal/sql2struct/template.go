@@ -0,0 +1,138 @@
+package sql2struct
+
+import (
+	"bytes"
+	"fmt"
+	"go/format"
+	"io"
+	"strings"
+	"text/template"
+
+	"tour/internal/word"
+)
+
+const structTpl = `{{if .Package}}package {{.Package}}
+
+{{end}}{{if .ImportTime}}import "time"
+
+{{end}}{{range .Tables}}
+{{- if .Comment}}// {{.StructName}} {{.Comment}}
+{{end -}}
+type {{.StructName}} struct {
+{{- range .Columns}}
+	// {{.Comment}}
+	{{.Name}} {{.Type}} {{.Tag}}
+{{- end}}
+}
+
+func ({{.Receiver}} {{.StructName}}) TableName() string {
+	return "{{.TableName}}"
+}
+
+{{end}}`
+
+// StructTemplate 根据表结构生成 Go 结构体
+type StructTemplate struct {
+	structTpl string
+	dbType    string
+	// 生成结构体名时去掉的表名前缀，如 blog_
+	prefix string
+}
+
+type StructColumn struct {
+	Name    string
+	Type    string
+	Tag     string
+	Comment string
+}
+
+type StructTemplateDB struct {
+	TableName  string
+	StructName string
+	Receiver   string
+	Comment    string
+	Columns    []*StructColumn
+}
+
+func NewStructTemplate(dbType, prefix string) *StructTemplate {
+	return &StructTemplate{
+		structTpl: structTpl,
+		dbType:    dbType,
+		prefix:    prefix,
+	}
+}
+
+// AssemblyColumns 将表字段转换为结构体字段
+func (t *StructTemplate) AssemblyColumns(tbColumns []*TableColumn) []*StructColumn {
+	tplColumns := make([]*StructColumn, 0, len(tbColumns))
+	for _, column := range tbColumns {
+		gormTag := "column:" + column.ColumnName
+		if column.IsPrimaryKey {
+			gormTag += ";primary_key"
+		}
+		if column.AutoIncrement {
+			gormTag += ";AUTO_INCREMENT"
+		}
+
+		comment := column.ColumnComment
+		if comment == "" {
+			comment = column.ColumnName
+		}
+
+		tplColumns = append(tplColumns, &StructColumn{
+			Name:    word.ToGoName(column.ColumnName),
+			Type:    StructType(t.dbType, column),
+			Tag:     fmt.Sprintf("`gorm:\"%s\" json:\"%s\"`", gormTag, column.ColumnName),
+			Comment: strings.Replace(comment, "\n", " ", -1),
+		})
+	}
+
+	return tplColumns
+}
+
+// Generate 生成 tables 对应的结构体定义并写入 w，pkg 不为空时会输出 package 声明
+func (t *StructTemplate) Generate(w io.Writer, pkg string, tables []*Table) error {
+	tpl, err := template.New("sql2struct").Parse(t.structTpl)
+	if err != nil {
+		return err
+	}
+
+	data := struct {
+		Package    string
+		ImportTime bool
+		Tables     []*StructTemplateDB
+	}{Package: pkg}
+	for _, table := range tables {
+		structName := word.ToGoName(strings.TrimPrefix(table.Name, t.prefix))
+		if structName == "" {
+			structName = word.ToGoName(table.Name)
+		}
+		columns := t.AssemblyColumns(table.Columns)
+		for _, column := range columns {
+			if column.Type == "time.Time" {
+				data.ImportTime = true
+			}
+		}
+
+		data.Tables = append(data.Tables, &StructTemplateDB{
+			TableName:  table.Name,
+			StructName: structName,
+			Receiver:   strings.ToLower(string([]rune(structName)[:1])),
+			Comment:    strings.Replace(table.Comment, "\n", " ", -1),
+			Columns:    columns,
+		})
+	}
+
+	var buf bytes.Buffer
+	if err := tpl.Execute(&buf, data); err != nil {
+		return err
+	}
+
+	src, err := format.Source(buf.Bytes())
+	if err != nil {
+		return err
+	}
+
+	_, err = w.Write(src)
+	return err
+}
@@ -0,0 +1,29 @@
+package sql2struct
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/matryer/is"
+)
+
+func TestGenerate(t *testing.T) {
+	is := is.New(t)
+
+	tables, err := ParseDDL(blogTagDDL)
+	is.NoErr(err)
+
+	var buf bytes.Buffer
+	err = NewStructTemplate(TypeMySQL, "blog_").Generate(&buf, "model", tables)
+	is.NoErr(err)
+
+	src := buf.String()
+	is.True(strings.HasPrefix(src, "package model\n"))
+	is.True(strings.Contains(src, `import "time"`))
+	is.True(strings.Contains(src, "// Tag 标签管理\ntype Tag struct {"))
+	is.True(strings.Contains(src, "\t// 标签名称\n\tName string `gorm:\"column:name\" json:\"name\"`"))
+	is.True(strings.Contains(src, "`gorm:\"column:id;primary_key;AUTO_INCREMENT\" json:\"id\"`"))
+	is.True(strings.Contains(src, "\tID uint32 `gorm"))
+	is.True(strings.Contains(src, "func (t Tag) TableName() string {\n\treturn \"blog_tag\"\n}"))
+}
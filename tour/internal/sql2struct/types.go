@@ -0,0 +1,93 @@
+package sql2struct
+
+import "strings"
+
+const (
+	TypeMySQL  = "mysql"
+	TypeSQLite = "sqlite"
+)
+
+// DBTypeToStructType MySQL 数据类型到 Go 类型的映射
+var DBTypeToStructType = map[string]string{
+	"int":              "int32",
+	"integer":          "int32",
+	"tinyint":          "int8",
+	"smallint":         "int16",
+	"mediumint":        "int32",
+	"bigint":           "int64",
+	"bit":              "uint64",
+	"bool":             "bool",
+	"boolean":          "bool",
+	"float":            "float32",
+	"double":           "float64",
+	"double precision": "float64",
+	"real":             "float64",
+	"decimal":          "float64",
+	"numeric":          "float64",
+	"char":             "string",
+	"varchar":          "string",
+	"tinytext":         "string",
+	"text":             "string",
+	"mediumtext":       "string",
+	"longtext":         "string",
+	"enum":             "string",
+	"set":              "string",
+	"json":             "string",
+	"time":             "string",
+	"year":             "int16",
+	"date":             "time.Time",
+	"datetime":         "time.Time",
+	"timestamp":        "time.Time",
+	"binary":           "[]byte",
+	"varbinary":        "[]byte",
+	"tinyblob":         "[]byte",
+	"blob":             "[]byte",
+	"mediumblob":       "[]byte",
+	"longblob":         "[]byte",
+}
+
+// unsignedTypes 带 unsigned 修饰时整数类型对应的 Go 类型
+var unsignedTypes = map[string]string{
+	"int8":  "uint8",
+	"int16": "uint16",
+	"int32": "uint32",
+	"int64": "uint64",
+}
+
+// StructType 返回字段在指定数据库类型下对应的 Go 类型
+func StructType(dbType string, column *TableColumn) string {
+	if dbType == TypeSQLite {
+		return sqliteStructType(column.DataType)
+	}
+
+	typ, ok := DBTypeToStructType[column.DataType]
+	if !ok {
+		return "[]byte"
+	}
+	if column.Unsigned {
+		if t, ok := unsignedTypes[typ]; ok {
+			typ = t
+		}
+	}
+	return typ
+}
+
+// sqliteStructType 按 SQLite 的类型亲和性规则确定 Go 类型，
+// 见 https://www.sqlite.org/datatype3.html#determination_of_column_affinity
+func sqliteStructType(dataType string) string {
+	t := strings.ToUpper(dataType)
+	switch {
+	case strings.HasPrefix(t, "BOOL"):
+		return "bool"
+	case t == "DATE" || t == "DATETIME" || t == "TIMESTAMP":
+		return "time.Time"
+	case strings.Contains(t, "INT"):
+		return "int64"
+	case strings.Contains(t, "CHAR"), strings.Contains(t, "CLOB"), strings.Contains(t, "TEXT"):
+		return "string"
+	case t == "" || strings.Contains(t, "BLOB"):
+		return "[]byte"
+	default:
+		return "float64"
+	}
+}
@@ -0,0 +1,125 @@
+package timer
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// DefaultLayout 默认的时间格式
+const DefaultLayout = "2006-01-02 15:04:05"
+
+// Layouts 支持解析的时间格式，按优先级排列
+var Layouts = []string{
+	DefaultLayout,
+	"2006-01-02 15:04",
+	"2006-01-02",
+	time.RFC3339,
+	time.RFC3339Nano,
+	time.RFC1123Z,
+	time.RFC1123,
+}
+
+// dayRegexp 匹配以天为单位的持续时间，如 7d、1.5d
+var dayRegexp = regexp.MustCompile(`([0-9]*\.?[0-9]+)d`)
+
+// LoadLocation 加载 IANA 时区，name 为空时使用本地时区
+func LoadLocation(name string) (*time.Location, error) {
+	if name == "" {
+		return time.Local, nil
+	}
+	return time.LoadLocation(name)
+}
+
+// GetNowTime 获取指定时区的当前时间
+func GetNowTime(location *time.Location) time.Time {
+	if location == nil {
+		location = time.Local
+	}
+	return time.Now().In(location)
+}
+
+// ParseTime 解析时间，支持秒级、毫秒级时间戳以及 Layouts 中的格式，
+// 返回解析后的时间和匹配到的格式（时间戳时为 DefaultLayout）
+func ParseTime(value string, location *time.Location) (time.Time, string, error) {
+	if location == nil {
+		location = time.Local
+	}
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return time.Time{}, "", errors.New("时间不能为空")
+	}
+
+	if ts, err := strconv.ParseInt(value, 10, 64); err == nil {
+		// 13 位及以上视为毫秒级时间戳
+		if len(strings.TrimPrefix(value, "-")) >= 13 {
+			return time.Unix(0, ts*int64(time.Millisecond)).In(location), DefaultLayout, nil
+		}
+		return time.Unix(ts, 0).In(location), DefaultLayout, nil
+	}
+
+	for _, layout := range Layouts {
+		t, err := time.ParseInLocation(layout, value, location)
+		if err == nil {
+			return t, layout, nil
+		}
+	}
+
+	return time.Time{}, "", fmt.Errorf("无法解析时间: %s", value)
+}
+
+// ParseDuration 解析持续时间，在 time.ParseDuration 的基础上支持 d（天）单位，
+// 如 +2h30m、-7d、1d12h
+func ParseDuration(d string) (time.Duration, error) {
+	s := strings.TrimSpace(d)
+	if s == "" {
+		return 0, errors.New("持续时间不能为空")
+	}
+
+	neg := false
+	switch s[0] {
+	case '-':
+		neg = true
+		s = s[1:]
+	case '+':
+		s = s[1:]
+	}
+	if s == "" || s[0] == '-' || s[0] == '+' {
+		return 0, fmt.Errorf("无效的持续时间: %s", d)
+	}
+
+	var duration time.Duration
+	for _, m := range dayRegexp.FindAllStringSubmatch(s, -1) {
+		days, err := strconv.ParseFloat(m[1], 64)
+		if err != nil {
+			return 0, fmt.Errorf("无效的持续时间: %s", d)
+		}
+		duration += time.Duration(days * float64(24*time.Hour))
+	}
+
+	if rest := dayRegexp.ReplaceAllString(s, ""); rest != "" {
+		v, err := time.ParseDuration(rest)
+		if err != nil {
+			return 0, fmt.Errorf("无效的持续时间: %s", d)
+		}
+		duration += v
+	}
+
+	if neg {
+		duration = -duration
+	}
+	return duration, nil
+}
+
+// GetCalculateTime 在 currentTimer 的基础上加上持续时间 d
+func GetCalculateTime(currentTimer time.Time, d string) (time.Time, error) {
+	duration, err := ParseDuration(d)
+	if err != nil {
+		return time.Time{}, err
+	}
+
+	return currentTimer.Add(duration), nil
+}
@@ -0,0 +1,70 @@
+package timer
+
+import (
+	"testing"
+	"time"
+
+	"github.com/matryer/is"
+)
+
+func TestParseDuration(t *testing.T) {
+	is := is.New(t)
+
+	cases := []struct {
+		in   string
+		want time.Duration
+	}{
+		{"2h30m", 2*time.Hour + 30*time.Minute},
+		{"+2h30m", 2*time.Hour + 30*time.Minute},
+		{"-7d", -7 * 24 * time.Hour},
+		{"1d12h", 36 * time.Hour},
+		{"1.5d", 36 * time.Hour},
+		{"-90s", -90 * time.Second},
+	}
+	for _, c := range cases {
+		d, err := ParseDuration(c.in)
+		is.NoErr(err)
+		is.Equal(d, c.want)
+	}
+
+	for _, in := range []string{"", "-", "d", "7x", "--1h"} {
+		_, err := ParseDuration(in)
+		is.True(err != nil)
+	}
+}
+
+func TestParseTime(t *testing.T) {
+	is := is.New(t)
+
+	location, err := LoadLocation("Asia/Shanghai")
+	is.NoErr(err)
+
+	tm, layout, err := ParseTime("2021-10-01 12:00", location)
+	is.NoErr(err)
+	is.Equal(layout, "2006-01-02 15:04")
+	is.Equal(tm.Unix(), int64(1633060800))
+
+	tm, layout, err = ParseTime("1633060800", location)
+	is.NoErr(err)
+	is.Equal(layout, DefaultLayout)
+	is.Equal(tm.Format(layout), "2021-10-01 12:00:00")
+
+	tm, _, err = ParseTime("1633060800123", location)
+	is.NoErr(err)
+	is.Equal(tm.Unix(), int64(1633060800))
+
+	_, _, err = ParseTime("yesterday", location)
+	is.True(err != nil)
+}
+
+func TestGetCalculateTime(t *testing.T) {
+	is := is.New(t)
+
+	base := time.Date(2021, 10, 1, 12, 0, 0, 0, time.UTC)
+	tm, err := GetCalculateTime(base, "-7d")
+	is.NoErr(err)
+	is.Equal(tm, time.Date(2021, 9, 24, 12, 0, 0, 0, time.UTC))
+
+	_, err = GetCalculateTime(base, "")
+	is.True(err != nil)
+}
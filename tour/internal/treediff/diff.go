@@ -0,0 +1,229 @@
+package treediff
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+type Kind int
+
+const (
+	Added Kind = iota + 1
+	Removed
+	Changed
+)
+
+func (k Kind) String() string {
+	switch k {
+	case Added:
+		return "+"
+	case Removed:
+		return "-"
+	case Changed:
+		return "~"
+	}
+	return "?"
+}
+
+// Change 表示一处差异，Added 时 Old 为 nil，Removed 时 New 为 nil
+type Change struct {
+	Kind Kind
+	Path string
+	Old  interface{}
+	New  interface{}
+}
+
+func (c Change) String() string {
+	switch c.Kind {
+	case Added:
+		return fmt.Sprintf("%s %s: %s", c.Kind, c.Path, FormatValue(c.New))
+	case Removed:
+		return fmt.Sprintf("%s %s: %s", c.Kind, c.Path, FormatValue(c.Old))
+	}
+	return fmt.Sprintf("%s %s: %s -> %s", c.Kind, c.Path, FormatValue(c.Old), FormatValue(c.New))
+}
+
+var plainKey = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_-]*$`)
+
+// Compare 比较两棵树，返回按路径排序的差异。
+// 路径形如 App.MaxPageSize、App.UploadImageAllowExts[0]，包含特殊字符的 key 写作 ["a.b"]；
+// 与 ignore 中任意一个 glob 匹配的路径及其子节点会被忽略，见 CompileGlob。
+func Compare(a, b interface{}, ignore []string) []Change {
+	c := &comparer{}
+	for _, pattern := range ignore {
+		c.ignore = append(c.ignore, CompileGlob(pattern))
+	}
+	c.compare("", a, b)
+	return c.changes
+}
+
+// CompileGlob 将路径 glob 转换为正则表达式：
+// * 匹配一段 key 或下标中的任意字符，** 可跨越多段，? 匹配单个字符，其余字符按原样匹配，
+// 如 Database.Password、*.Password、**.Password、App.UploadImageAllowExts[*]
+func CompileGlob(pattern string) *regexp.Regexp {
+	var b strings.Builder
+	b.WriteString("^")
+	for i := 0; i < len(pattern); i++ {
+		switch {
+		case strings.HasPrefix(pattern[i:], "**"):
+			b.WriteString(".*")
+			i++
+		case pattern[i] == '*':
+			b.WriteString(`[^.\[\]]*`)
+		case pattern[i] == '?':
+			b.WriteString(".")
+		default:
+			b.WriteString(regexp.QuoteMeta(pattern[i : i+1]))
+		}
+	}
+	b.WriteString("$")
+	return regexp.MustCompile(b.String())
+}
+
+type comparer struct {
+	ignore  []*regexp.Regexp
+	changes []Change
+}
+
+func (c *comparer) ignored(p string) bool {
+	for _, re := range c.ignore {
+		if re.MatchString(p) {
+			return true
+		}
+	}
+	return false
+}
+
+func (c *comparer) add(kind Kind, p string, old, new interface{}) {
+	if p == "" {
+		p = "."
+	}
+	c.changes = append(c.changes, Change{Kind: kind, Path: p, Old: old, New: new})
+}
+
+func (c *comparer) compare(p string, a, b interface{}) {
+	if p != "" && c.ignored(p) {
+		return
+	}
+
+	switch av := a.(type) {
+	case map[string]interface{}:
+		bv, ok := b.(map[string]interface{})
+		if !ok {
+			break
+		}
+		keys := make([]string, 0, len(av)+len(bv))
+		for k := range av {
+			keys = append(keys, k)
+		}
+		for k := range bv {
+			if _, ok := av[k]; !ok {
+				keys = append(keys, k)
+			}
+		}
+		sort.Strings(keys)
+		for _, k := range keys {
+			child := joinKey(p, k)
+			x, inA := av[k]
+			y, inB := bv[k]
+			switch {
+			case !inA:
+				if !c.ignored(child) {
+					c.add(Added, child, nil, y)
+				}
+			case !inB:
+				if !c.ignored(child) {
+					c.add(Removed, child, x, nil)
+				}
+			default:
+				c.compare(child, x, y)
+			}
+		}
+		return
+	case []interface{}:
+		bv, ok := b.([]interface{})
+		if !ok {
+			break
+		}
+		for i := 0; i < len(av) || i < len(bv); i++ {
+			child := p + "[" + strconv.Itoa(i) + "]"
+			switch {
+			case i >= len(av):
+				if !c.ignored(child) {
+					c.add(Added, child, nil, bv[i])
+				}
+			case i >= len(bv):
+				if !c.ignored(child) {
+					c.add(Removed, child, av[i], nil)
+				}
+			default:
+				c.compare(child, av[i], bv[i])
+			}
+		}
+		return
+	}
+
+	if !scalarEqual(a, b) {
+		c.add(Changed, p, a, b)
+	}
+}
+
+func joinKey(p, key string) string {
+	if !plainKey.MatchString(key) {
+		return p + "[" + strconv.Quote(key) + "]"
+	}
+	if p == "" {
+		return key
+	}
+	return p + "." + key
+}
+
+// scalarEqual 比较两个标量，JSON 和 YAML 中的数字按数值比较，如 100 与 100.0 相等
+func scalarEqual(a, b interface{}) bool {
+	x, xok := toFloat(a)
+	y, yok := toFloat(b)
+	if xok && yok {
+		ai, aok := a.(int64)
+		bi, bok := b.(int64)
+		if aok && bok {
+			return ai == bi
+		}
+		return x == y
+	}
+
+	switch a.(type) {
+	case map[string]interface{}, []interface{}:
+		return false
+	}
+	switch b.(type) {
+	case map[string]interface{}, []interface{}:
+		return false
+	}
+	return a == b
+}
+
+func toFloat(v interface{}) (float64, bool) {
+	switch v := v.(type) {
+	case int64:
+		return float64(v), true
+	case uint64:
+		return float64(v), true
+	case float64:
+		return v, !math.IsNaN(v)
+	}
+	return 0, false
+}
+
+// FormatValue 将值格式化为单行 JSON，无法编码时使用 fmt 的默认格式
+func FormatValue(v interface{}) string {
+	b, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Sprint(v)
+	}
+	return string(b)
+}
@@ -0,0 +1,103 @@
+package treediff
+
+import (
+	"testing"
+
+	"github.com/matryer/is"
+)
+
+const configYAML = `
+Server:
+  RunMode: debug
+  HttpPort: 8080
+App:
+  DefaultPageSize: 10
+  MaxPageSize: 100
+  UploadImageAllowExts:
+    - .jpg
+    - .jpeg
+Database:
+  Password: mysql
+  ParseTime: True
+`
+
+const configJSON = `{
+  "Server": {"RunMode": "debug", "HttpPort": 8080.0},
+  "App": {
+    "DefaultPageSize": 10,
+    "MaxPageSize": 200,
+    "UploadImageAllowExts": [".jpg", ".jpeg", ".png"],
+    "log.level": "info"
+  },
+  "Database": {"Password": "secret"}
+}`
+
+func parse(t *testing.T, data, format string) interface{} {
+	t.Helper()
+	v, err := Parse([]byte(data), format)
+	if err != nil {
+		t.Fatalf("Parse err: %v", err)
+	}
+	return v
+}
+
+func changeStrings(changes []Change) []string {
+	var s []string
+	for _, c := range changes {
+		s = append(s, c.String())
+	}
+	return s
+}
+
+func TestCompare(t *testing.T) {
+	is := is.New(t)
+
+	a := parse(t, configYAML, FormatYAML)
+	b := parse(t, configJSON, FormatJSON)
+
+	is.Equal(changeStrings(Compare(a, b, nil)), []string{
+		`~ App.MaxPageSize: 100 -> 200`,
+		`+ App.UploadImageAllowExts[2]: ".png"`,
+		`+ App["log.level"]: "info"`,
+		`- Database.ParseTime: true`,
+		`~ Database.Password: "mysql" -> "secret"`,
+	})
+	is.Equal(len(Compare(a, a, nil)), 0)
+}
+
+func TestCompareIgnore(t *testing.T) {
+	is := is.New(t)
+
+	a := parse(t, configYAML, FormatYAML)
+	b := parse(t, configJSON, FormatJSON)
+
+	is.Equal(changeStrings(Compare(a, b, []string{"Database", "App.*", `App["log.level"]`})), []string(nil))
+	is.Equal(changeStrings(Compare(a, b, []string{"*.Password", "App.UploadImageAllowExts[*]", `**["log.level"]`})), []string{
+		`~ App.MaxPageSize: 100 -> 200`,
+		`- Database.ParseTime: true`,
+	})
+}
+
+func TestCompareTypeChange(t *testing.T) {
+	is := is.New(t)
+
+	a := parse(t, `{"a": {"b": 1}, "c": [1]}`, FormatJSON)
+	b := parse(t, "a: 1\nc: {x: 1}\n", FormatYAML)
+	is.Equal(changeStrings(Compare(a, b, nil)), []string{
+		`~ a: {"b":1} -> 1`,
+		`~ c: [1] -> {"x":1}`,
+	})
+
+	is.Equal(changeStrings(Compare(parse(t, "1", FormatJSON), parse(t, "2", FormatYAML), nil)), []string{
+		`~ .: 1 -> 2`,
+	})
+}
+
+func TestDetectFormat(t *testing.T) {
+	is := is.New(t)
+
+	is.Equal(DetectFormat("config.yml", nil), FormatYAML)
+	is.Equal(DetectFormat("resp.JSON", nil), FormatJSON)
+	is.Equal(DetectFormat("-", []byte(`{"a": 1}`)), FormatJSON)
+	is.Equal(DetectFormat("-", []byte("a: 1")), FormatYAML)
+}
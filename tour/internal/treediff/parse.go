@@ -0,0 +1,89 @@
+package treediff
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	FormatJSON = "json"
+	FormatYAML = "yaml"
+)
+
+// DetectFormat 根据文件扩展名判断格式，无法判断时根据内容判断
+func DetectFormat(filename string, data []byte) string {
+	switch strings.ToLower(filepath.Ext(filename)) {
+	case ".json":
+		return FormatJSON
+	case ".yaml", ".yml":
+		return FormatYAML
+	}
+
+	if json.Valid(data) {
+		return FormatJSON
+	}
+	return FormatYAML
+}
+
+// Parse 将 JSON 或 YAML 解析为通用的树，
+// 节点为 map[string]interface{}、[]interface{}、string、bool、int64、uint64、float64 或 nil
+func Parse(data []byte, format string) (interface{}, error) {
+	var v interface{}
+	switch format {
+	case FormatJSON:
+		d := json.NewDecoder(bytes.NewReader(data))
+		d.UseNumber()
+		if err := d.Decode(&v); err != nil {
+			return nil, err
+		}
+		if d.More() {
+			return nil, fmt.Errorf("JSON 中包含多个值")
+		}
+	case FormatYAML:
+		if err := yaml.Unmarshal(data, &v); err != nil {
+			return nil, err
+		}
+	default:
+		return nil, fmt.Errorf("不支持的格式: %s", format)
+	}
+
+	return normalize(v), nil
+}
+
+// normalize 统一 JSON 和 YAML 解析结果中的 map 和数字类型
+func normalize(v interface{}) interface{} {
+	switch v := v.(type) {
+	case map[string]interface{}:
+		for k, item := range v {
+			v[k] = normalize(item)
+		}
+		return v
+	case map[interface{}]interface{}:
+		m := make(map[string]interface{}, len(v))
+		for k, item := range v {
+			m[fmt.Sprint(k)] = normalize(item)
+		}
+		return m
+	case []interface{}:
+		for i, item := range v {
+			v[i] = normalize(item)
+		}
+		return v
+	case json.Number:
+		if n, err := v.Int64(); err == nil {
+			return n
+		}
+		f, _ := v.Float64()
+		return f
+	case int:
+		return int64(v)
+	case float32:
+		return float64(v)
+	}
+	return v
+}
@@ -0,0 +1,109 @@
+package word
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+// Rewriter 将文本中匹配正则的标识符按 convert 转换。
+// 正则含有分组时只转换各分组的内容，否则转换整个匹配，
+// 如 `json:"(\w+)"` 只会转换 json 标签中的名称。
+type Rewriter struct {
+	re      *regexp.Regexp
+	convert func(string) string
+}
+
+// Change 记录一行文本的改动，Line 从 1 开始
+type Change struct {
+	Line     int
+	Old, New string
+}
+
+func NewRewriter(pattern string, convert func(string) string) (*Rewriter, error) {
+	re, err := regexp.Compile(pattern)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Rewriter{re: re, convert: convert}, nil
+}
+
+// RewriteLine 转换一行文本中所有匹配的标识符
+func (r *Rewriter) RewriteLine(line string) string {
+	var b strings.Builder
+	last := 0
+	for _, loc := range r.re.FindAllStringSubmatchIndex(line, -1) {
+		spans := [][]int{loc[:2]}
+		if r.re.NumSubexp() > 0 {
+			spans = spans[:0]
+			for i := 2; i+1 < len(loc); i += 2 {
+				if loc[i] >= 0 && loc[i] >= last {
+					spans = append(spans, loc[i:i+2])
+				}
+			}
+		}
+
+		for _, span := range spans {
+			b.WriteString(line[last:span[0]])
+			b.WriteString(r.convert(line[span[0]:span[1]]))
+			last = span[1]
+		}
+	}
+	b.WriteString(line[last:])
+
+	return b.String()
+}
+
+// Rewrite 逐行转换 src，返回转换后的内容和改动的行；正则不会跨行匹配
+func (r *Rewriter) Rewrite(src []byte) ([]byte, []Change) {
+	var changes []Change
+	var dst bytes.Buffer
+	lines := strings.SplitAfter(string(src), "\n")
+	for i, line := range lines {
+		content := strings.TrimSuffix(line, "\n")
+		newContent := r.RewriteLine(content)
+		if newContent != content {
+			changes = append(changes, Change{Line: i + 1, Old: content, New: newContent})
+		}
+		dst.WriteString(newContent)
+		if len(content) < len(line) {
+			dst.WriteByte('\n')
+		}
+	}
+
+	return dst.Bytes(), changes
+}
+
+// Diff 以 unified diff 的格式输出 filename 的改动，相邻的改动行合并为一个 hunk
+func Diff(w io.Writer, filename string, changes []Change) error {
+	if len(changes) == 0 {
+		return nil
+	}
+
+	bw := bufio.NewWriter(w)
+	name := strings.TrimPrefix(filepath.ToSlash(filename), "/")
+	fmt.Fprintf(bw, "--- a/%s\n+++ b/%s\n", name, name)
+	for i := 0; i < len(changes); {
+		j := i + 1
+		for j < len(changes) && changes[j].Line == changes[j-1].Line+1 {
+			j++
+		}
+
+		hunk := changes[i:j]
+		fmt.Fprintf(bw, "@@ -%d,%d +%d,%d @@\n", hunk[0].Line, len(hunk), hunk[0].Line, len(hunk))
+		for _, c := range hunk {
+			fmt.Fprintf(bw, "-%s\n", c.Old)
+		}
+		for _, c := range hunk {
+			fmt.Fprintf(bw, "+%s\n", c.New)
+		}
+		i = j
+	}
+
+	return bw.Flush()
+}
@@ -0,0 +1,50 @@
+package word
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/matryer/is"
+)
+
+const modelSrc = "type Model struct {\n" +
+	"\tID        uint32 `gorm:\"primary_key\" json:\"id\"`\n" +
+	"\tCreatedBy string `json:\"createdBy\"`\n" +
+	"\tIsDel     uint8  `json:\"isDel\"`\n" +
+	"}\n"
+
+func TestRewrite(t *testing.T) {
+	is := is.New(t)
+
+	r, err := NewRewriter(`json:"(\w+)"`, CamelCaseToUnderscore)
+	is.NoErr(err)
+
+	dst, changes := r.Rewrite([]byte(modelSrc))
+	is.Equal(string(dst), "type Model struct {\n"+
+		"\tID        uint32 `gorm:\"primary_key\" json:\"id\"`\n"+
+		"\tCreatedBy string `json:\"created_by\"`\n"+
+		"\tIsDel     uint8  `json:\"is_del\"`\n"+
+		"}\n")
+	is.Equal(len(changes), 2)
+	is.Equal(changes[0].Line, 3)
+
+	var buf bytes.Buffer
+	is.NoErr(Diff(&buf, "model.go", changes))
+	is.Equal(buf.String(), "--- a/model.go\n+++ b/model.go\n"+
+		"@@ -3,2 +3,2 @@\n"+
+		"-\tCreatedBy string `json:\"createdBy\"`\n"+
+		"-\tIsDel     uint8  `json:\"isDel\"`\n"+
+		"+\tCreatedBy string `json:\"created_by\"`\n"+
+		"+\tIsDel     uint8  `json:\"is_del\"`\n")
+}
+
+func TestRewriteLineWithoutGroup(t *testing.T) {
+	is := is.New(t)
+
+	r, err := NewRewriter(`[a-z]+[A-Z]\w*`, ToScreamingSnakeCase)
+	is.NoErr(err)
+	is.Equal(r.RewriteLine("const maxPageSize = defaultPageSize"), "const MAX_PAGE_SIZE = DEFAULT_PAGE_SIZE")
+
+	_, err = NewRewriter(`(`, ToScreamingSnakeCase)
+	is.True(err != nil)
+}
@@ -5,6 +5,177 @@ import (
 	"unicode"
 )
 
+// Initialisms Go 命名中需要保持原有大小写的缩写词，键为 Go 命名中的写法（如 ID、IPv4、OAuth），可按需增删。
+// Split 会把它们作为一个单词切分，ToGoName 会把它们还原为该写法
+var Initialisms = map[string]bool{
+	"ACL":   true,
+	"API":   true,
+	"ASCII": true,
+	"CPU":   true,
+	"CSS":   true,
+	"DNS":   true,
+	"EOF":   true,
+	"GUID":  true,
+	"HTML":  true,
+	"HTTP":  true,
+	"HTTPS": true,
+	"ID":    true,
+	"IP":    true,
+	"IPv4":  true,
+	"IPv6":  true,
+	"JSON":  true,
+	"JWT":   true,
+	"OAuth": true,
+	"QPS":   true,
+	"RAM":   true,
+	"RPC":   true,
+	"SQL":   true,
+	"SSH":   true,
+	"TCP":   true,
+	"TLS":   true,
+	"TTL":   true,
+	"UDP":   true,
+	"UI":    true,
+	"UID":   true,
+	"URI":   true,
+	"URL":   true,
+	"UTF":   true,
+	"UUID":  true,
+	"XML":   true,
+}
+
+// AddInitialisms 增加 Go 命名中的缩写词，全小写的视为全大写，如 grpc -> GRPC，其他的保持原样，如 OAuth
+func AddInitialisms(words ...string) {
+	for _, w := range words {
+		if w = strings.TrimSpace(w); w == "" {
+			continue
+		}
+		if w == strings.ToLower(w) {
+			w = strings.ToUpper(w)
+		}
+		Initialisms[w] = true
+	}
+}
+
+// initialism 返回与 w 忽略大小写相同的缩写词的写法
+func initialism(w string) (string, bool) {
+	for k := range Initialisms {
+		if strings.EqualFold(k, w) {
+			return k, true
+		}
+	}
+	return "", false
+}
+
+// Split 将单词拆分为若干部分。- _ . 空格等非字母数字字符作为分隔符，
+// 此外在小写转大写、数字后跟大写字母，以及连续大写字母后跟小写字母时
+// （如 HTTPServer 拆为 HTTP 和 Server）进行切分；数字属于前面的单词（如 utf8、OAuth2）。
+// Initialisms 中的缩写词及其复数作为一个单词，如 userIDs 拆为 user 和 IDs，IPv4Address 拆为 IPv4 和 Address
+func Split(s string) []string {
+	var words []string
+	rs := []rune(s)
+	for i := 0; i < len(rs); {
+		if !isWordRune(rs[i]) {
+			i++
+			continue
+		}
+		j := wordEnd(rs, i)
+		words = append(words, string(rs[i:j]))
+		i = j
+	}
+
+	return words
+}
+
+func isWordRune(r rune) bool {
+	return unicode.IsLetter(r) || unicode.IsDigit(r)
+}
+
+// wordEnd 返回从 rs[i] 开始的单词的结束位置
+func wordEnd(rs []rune, i int) int {
+	j := i + 1
+	if n := initialismLen(rs, i); n > 0 {
+		j = i + n
+		// 缩写词后面只能再跟数字，如 OAuth2
+		if j == len(rs) || !unicode.IsDigit(rs[j]) {
+			return j
+		}
+	}
+	for j < len(rs) && isWordRune(rs[j]) && !isBoundary(rs, j) {
+		j++
+	}
+	return j
+}
+
+// initialismLen 返回从 rs[i] 开始的最长的缩写词（包括复数的 s）的长度，没有时返回 0。
+// 缩写词后面必须是单词的边界：结尾、非字母或大写字母
+func initialismLen(rs []rune, i int) int {
+	if !unicode.IsUpper(rs[i]) {
+		return 0
+	}
+	best := 0
+	for w := range Initialisms {
+		n := hasPrefix(rs[i:], w)
+		if n == 0 {
+			continue
+		}
+		j := i + n
+		// 复数，如 IDs、URLs
+		if j < len(rs) && rs[j] == 's' && (j+1 == len(rs) || !unicode.IsLower(rs[j+1])) {
+			n++
+		} else if j < len(rs) && unicode.IsLower(rs[j]) {
+			continue
+		}
+		if n > best {
+			best = n
+		}
+	}
+	return best
+}
+
+// hasPrefix 返回 rs 以 w 开头时 w 的长度，否则返回 0
+func hasPrefix(rs []rune, w string) int {
+	n := 0
+	for _, r := range w {
+		if n >= len(rs) || rs[n] != r {
+			return 0
+		}
+		n++
+	}
+	return n
+}
+
+// isBoundary 判断 rs[i] 是否为一个新单词的开始，rs[i-1] 一定是字母或数字
+func isBoundary(rs []rune, i int) bool {
+	prev, cur := rs[i-1], rs[i]
+	switch {
+	case unicode.IsDigit(cur):
+		return false
+	case unicode.IsDigit(prev):
+		return unicode.IsUpper(cur)
+	case unicode.IsLower(prev) && unicode.IsUpper(cur):
+		return true
+	case unicode.IsUpper(prev) && unicode.IsUpper(cur):
+		// 新的单词是缩写词，如 XMLHTTPRequest 中的 HTTP
+		return i+1 < len(rs) && unicode.IsLower(rs[i+1]) || initialismLen(rs, i) > 0
+	}
+	return false
+}
+
+// title 首字母大写，其余小写
+func title(w string) string {
+	rs := []rune(strings.ToLower(w))
+	rs[0] = unicode.ToTitle(rs[0])
+	return string(rs)
+}
+
+func join(words []string, sep string, fn func(i int, w string) string) string {
+	for i, w := range words {
+		words[i] = fn(i, w)
+	}
+	return strings.Join(words, sep)
+}
+
 func ToUpper(s string) string {
 	return strings.ToUpper(s)
 }
@@ -13,28 +184,82 @@ func ToLower(s string) string {
 	return strings.ToLower(s)
 }
 
+// UnderscoreToUpperCamelCase 转为大写驼峰，如 user_name -> UserName
 func UnderscoreToUpperCamelCase(s string) string {
-	s = strings.Replace(s, "_", " ", -1)
-	s = strings.Title(s)
-	return strings.Replace(s, " ", "", -1)
+	return join(Split(s), "", func(i int, w string) string {
+		return title(w)
+	})
 }
 
+// UnderscoreToLowerCamelCase 转为小写驼峰，如 user_name -> userName
 func UnderscoreToLowerCamelCase(s string) string {
-	s = UnderscoreToUpperCamelCase(s)
-	return string(unicode.ToLower(rune(s[0]))) + s[1:]
+	return join(Split(s), "", func(i int, w string) string {
+		if i == 0 {
+			return strings.ToLower(w)
+		}
+		return title(w)
+	})
 }
 
+// CamelCaseToUnderscore 转为下划线，如 HTTPServer -> http_server
 func CamelCaseToUnderscore(s string) string {
-	var output []rune
-	for i, r := range s {
-		if i == 0 {
-			output = append(output, unicode.ToLower(r))
-			continue
+	return join(Split(s), "_", func(i int, w string) string {
+		return strings.ToLower(w)
+	})
+}
+
+// ToKebabCase 转为中划线，如 HTTPServer -> http-server
+func ToKebabCase(s string) string {
+	return join(Split(s), "-", func(i int, w string) string {
+		return strings.ToLower(w)
+	})
+}
+
+// ToScreamingSnakeCase 转为全大写下划线，如 httpServer -> HTTP_SERVER
+func ToScreamingSnakeCase(s string) string {
+	return join(Split(s), "_", func(i int, w string) string {
+		return strings.ToUpper(w)
+	})
+}
+
+// ToDotCase 转为点分隔，如 HTTPServer -> http.server
+func ToDotCase(s string) string {
+	return join(Split(s), ".", func(i int, w string) string {
+		return strings.ToLower(w)
+	})
+}
+
+// ToTitleCase 转为空格分隔的首字母大写，如 http_server -> Http Server
+func ToTitleCase(s string) string {
+	return join(Split(s), " ", func(i int, w string) string {
+		return title(w)
+	})
+}
+
+// ToGoName 转为可导出的 Go 命名，Initialisms 中的缩写词使用其写法，如 user_id -> UserID、user_ids -> UserIDs、
+// ipv4_address -> IPv4Address、oauth2_token -> OAuth2Token
+func ToGoName(s string) string {
+	return join(Split(s), "", func(i int, w string) string {
+		return goWord(w)
+	})
+}
+
+// goWord 将一个单词转为 Go 命名中的写法
+func goWord(w string) string {
+	if k, ok := initialism(w); ok {
+		return k
+	}
+	// 复数，如 ids -> IDs
+	if strings.HasSuffix(w, "s") {
+		if k, ok := initialism(strings.TrimSuffix(w, "s")); ok {
+			return k + "s"
 		}
-		if unicode.IsUpper(r) {
-			output = append(output, '_')
+	}
+	// 后面跟着数字，如 utf8 -> UTF8
+	if letters := strings.TrimRightFunc(w, unicode.IsDigit); letters != "" && letters != w {
+		if k, ok := initialism(letters); ok {
+			return k + w[len(letters):]
 		}
-		output = append(output, unicode.ToLower(r))
 	}
-	return string(output)
+	return title(w)
 }
@@ -0,0 +1,86 @@
+package word
+
+import (
+	"testing"
+
+	"github.com/matryer/is"
+)
+
+func TestSplit(t *testing.T) {
+	is := is.New(t)
+
+	cases := []struct {
+		in   string
+		want []string
+	}{
+		{"", nil},
+		{"user_name", []string{"user", "name"}},
+		{"HTTPServer", []string{"HTTP", "Server"}},
+		{"getHTTPResponseCode", []string{"get", "HTTP", "Response", "Code"}},
+		{"userID", []string{"user", "ID"}},
+		{"utf8Decoder", []string{"utf8", "Decoder"}},
+		{"userIDs", []string{"user", "IDs"}},
+		{"IPv4Address", []string{"IPv4", "Address"}},
+		{"OAuth2Token", []string{"OAuth2", "Token"}},
+		{"XMLHTTPRequest", []string{"XML", "HTTP", "Request"}},
+		{"HTTPSProxy", []string{"HTTPS", "Proxy"}},
+		{"page2Size", []string{"page2", "Size"}},
+		{"1st", []string{"1st"}},
+		{"foo-bar.baz qux", []string{"foo", "bar", "baz", "qux"}},
+		{"__a__b__", []string{"a", "b"}},
+		{"ÄpfelÜber", []string{"Äpfel", "Über"}},
+		{"用户_名称", []string{"用户", "名称"}},
+	}
+	for _, c := range cases {
+		is.Equal(Split(c.in), c.want)
+	}
+}
+
+func TestConvert(t *testing.T) {
+	is := is.New(t)
+
+	is.Equal(UnderscoreToUpperCamelCase("user_name"), "UserName")
+	is.Equal(UnderscoreToUpperCamelCase("HTTPServer"), "HttpServer")
+	is.Equal(UnderscoreToLowerCamelCase("user_name"), "userName")
+	is.Equal(UnderscoreToLowerCamelCase("HTTPServer"), "httpServer")
+	is.Equal(CamelCaseToUnderscore("HTTPServer"), "http_server")
+	is.Equal(CamelCaseToUnderscore("CoverImageURL"), "cover_image_url")
+	is.Equal(CamelCaseToUnderscore("userIDs"), "user_ids")
+	is.Equal(CamelCaseToUnderscore("IPv4Address"), "ipv4_address")
+	is.Equal(CamelCaseToUnderscore("OAuth2Token"), "oauth2_token")
+	is.Equal(ToKebabCase("CoverImageURL"), "cover-image-url")
+	is.Equal(ToScreamingSnakeCase("maxPageSize"), "MAX_PAGE_SIZE")
+	is.Equal(ToDotCase("App.MaxPageSize"), "app.max.page.size")
+	is.Equal(ToTitleCase("blog_article_tag"), "Blog Article Tag")
+	is.Equal(ToGoName("cover_image_url"), "CoverImageURL")
+	is.Equal(ToGoName("user_id"), "UserID")
+	is.Equal(ToGoName("json-http-server"), "JSONHTTPServer")
+	is.Equal(ToGoName("äpfel"), "Äpfel")
+	is.Equal(ToGoName("user_ids"), "UserIDs")
+	is.Equal(ToGoName("ipv4_address"), "IPv4Address")
+	is.Equal(ToGoName("oauth2_token"), "OAuth2Token")
+	is.Equal(ToGoName("utf8_decoder"), "UTF8Decoder")
+}
+
+func TestEmpty(t *testing.T) {
+	is := is.New(t)
+
+	is.Equal(UnderscoreToUpperCamelCase(""), "")
+	is.Equal(UnderscoreToLowerCamelCase(""), "")
+	is.Equal(CamelCaseToUnderscore(""), "")
+	is.Equal(ToGoName("___"), "")
+}
+
+func TestAddInitialisms(t *testing.T) {
+	is := is.New(t)
+
+	is.Equal(ToGoName("grpc_client"), "GrpcClient")
+	AddInitialisms("grpc")
+	defer delete(Initialisms, "GRPC")
+	is.Equal(ToGoName("grpc_client"), "GRPCClient")
+	is.Equal(Split("GRPCClient"), []string{"GRPC", "Client"})
+
+	AddInitialisms("WebSocket")
+	defer delete(Initialisms, "WebSocket")
+	is.Equal(ToGoName("websocket_url"), "WebSocketURL")
+}